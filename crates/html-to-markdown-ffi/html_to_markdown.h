@@ -12,6 +12,14 @@
 #include <stdint.h>
 #include <stdlib.h>
 
+/**
+ * FFI ABI revision implemented by this library.
+ *
+ * Bump it whenever an exported function changes its signature or ownership
+ * rules, so that language bindings can refuse a library they do not support.
+ */
+#define HTML_TO_MARKDOWN_ABI_VERSION 2
+
 /**
  * Result type enumeration for visitor callbacks.
  *
@@ -455,6 +463,39 @@ void html_to_markdown_free_string(char *s);
  */
 const char *html_to_markdown_version(void);
 
+/**
+ * Get the FFI ABI revision implemented by this library.
+ *
+ * # Safety
+ *
+ * - Takes no arguments and returns a plain integer; always safe to call
+ */
+int32_t html_to_markdown_abi_version(void);
+
+/**
+ * Restart the allocation peak reported by `html_to_markdown_peak_bytes`.
+ *
+ * The peak counts the library allocations of every thread, so
+ * conversions running concurrently with the one being measured add to it.
+ * Only exported when the library is built with the `alloc-stats` feature,
+ * which installs a counting global allocator.
+ *
+ * # Safety
+ *
+ * - Takes no arguments; always safe to call
+ */
+void html_to_markdown_reset_peak_bytes(void);
+
+/**
+ * Get the peak number of bytes the library allocated since the last call to
+ * `html_to_markdown_reset_peak_bytes`, beyond those it held at that call.
+ *
+ * # Safety
+ *
+ * - Takes no arguments and returns a plain integer; always safe to call
+ */
+uint64_t html_to_markdown_peak_bytes(void);
+
 /**
  * Convert HTML to Markdown with metadata extraction.
  *
@@ -483,6 +524,20 @@ const char *html_to_markdown_version(void);
 char *html_to_markdown_convert_with_metadata(const char *html,
                                              char **metadata_json_out);
 
+/**
+ * Extract the metadata of HTML without returning its Markdown.
+ *
+ * The metadata is the same `convert_with_metadata` collects, serialized to
+ * JSON like the metadata of `html_to_markdown_convert_with_metadata`.
+ *
+ * # Safety
+ *
+ * - `html` must be a valid null-terminated C string
+ * - The returned JSON string must be freed with `html_to_markdown_free_string`
+ * - Returns NULL on error (check error with `html_to_markdown_last_error`)
+ */
+char *html_to_markdown_extract_metadata(const char *html);
+
 /**
  * Convert HTML to Markdown with metadata extraction, returning output lengths.
  *
@@ -0,0 +1,76 @@
+//! Allocation accounting for C FFI.
+//!
+//! This module wraps the system allocator to track the bytes the library holds,
+//! so that callers can read the peak allocation of a conversion. Since it
+//! installs the global allocator, it is only built with the `alloc-stats`
+//! feature.
+
+use std::alloc::{GlobalAlloc, Layout, System};
+use std::sync::atomic::{AtomicUsize, Ordering};
+
+static CURRENT: AtomicUsize = AtomicUsize::new(0);
+static PEAK: AtomicUsize = AtomicUsize::new(0);
+static BASELINE: AtomicUsize = AtomicUsize::new(0);
+
+/// The system allocator, counting the bytes currently allocated and their peak.
+pub struct CountingAllocator;
+
+#[global_allocator]
+static ALLOCATOR: CountingAllocator = CountingAllocator;
+
+fn grow(size: usize) {
+    let current = CURRENT.fetch_add(size, Ordering::Relaxed) + size;
+    PEAK.fetch_max(current, Ordering::Relaxed);
+}
+
+fn shrink(size: usize) {
+    CURRENT.fetch_sub(size, Ordering::Relaxed);
+}
+
+unsafe impl GlobalAlloc for CountingAllocator {
+    unsafe fn alloc(&self, layout: Layout) -> *mut u8 {
+        let ptr = unsafe { System.alloc(layout) };
+        if !ptr.is_null() {
+            grow(layout.size());
+        }
+        ptr
+    }
+
+    unsafe fn alloc_zeroed(&self, layout: Layout) -> *mut u8 {
+        let ptr = unsafe { System.alloc_zeroed(layout) };
+        if !ptr.is_null() {
+            grow(layout.size());
+        }
+        ptr
+    }
+
+    unsafe fn dealloc(&self, ptr: *mut u8, layout: Layout) {
+        unsafe { System.dealloc(ptr, layout) };
+        shrink(layout.size());
+    }
+
+    unsafe fn realloc(&self, ptr: *mut u8, layout: Layout, new_size: usize) -> *mut u8 {
+        let new_ptr = unsafe { System.realloc(ptr, layout, new_size) };
+        if !new_ptr.is_null() {
+            if new_size > layout.size() {
+                grow(new_size - layout.size());
+            } else {
+                shrink(layout.size() - new_size);
+            }
+        }
+        new_ptr
+    }
+}
+
+/// Start a new measurement: the peak restarts from the bytes allocated now.
+pub fn reset_peak() {
+    let current = CURRENT.load(Ordering::Relaxed);
+    BASELINE.store(current, Ordering::Relaxed);
+    PEAK.store(current, Ordering::Relaxed);
+}
+
+/// Peak bytes allocated since the last `reset_peak`, beyond those allocated then.
+pub fn peak_bytes() -> usize {
+    PEAK.load(Ordering::Relaxed)
+        .saturating_sub(BASELINE.load(Ordering::Relaxed))
+}
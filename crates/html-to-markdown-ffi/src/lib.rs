@@ -16,6 +16,8 @@ use html_to_markdown_rs::safety::guard_panic;
 
 #[cfg(feature = "metadata")]
 use html_to_markdown_rs::{MetadataConfig, convert_with_metadata, metadata::DEFAULT_MAX_STRUCTURED_DATA_SIZE};
+#[cfg(feature = "alloc-stats")]
+mod allocation;
 mod error;
 mod profiling;
 mod strings;
@@ -282,6 +284,50 @@ pub const unsafe extern "C" fn html_to_markdown_version() -> *const c_char {
     concat!(env!("CARGO_PKG_VERSION"), "\0").as_ptr().cast::<c_char>()
 }
 
+/// FFI ABI revision implemented by this library.
+///
+/// Bump it whenever an exported function changes its signature or ownership
+/// rules, so that language bindings can refuse a library they do not support.
+pub const HTML_TO_MARKDOWN_ABI_VERSION: i32 = 2;
+
+/// Get the FFI ABI revision implemented by this library.
+///
+/// # Safety
+///
+/// - Takes no arguments and returns a plain integer; always safe to call
+#[unsafe(no_mangle)]
+pub const unsafe extern "C" fn html_to_markdown_abi_version() -> i32 {
+    HTML_TO_MARKDOWN_ABI_VERSION
+}
+
+/// Restart the allocation peak reported by `html_to_markdown_peak_bytes`.
+///
+/// The peak counts the library allocations of every thread, so
+/// conversions running concurrently with the one being measured add to it.
+/// Only exported when the library is built with the `alloc-stats` feature,
+/// which installs a counting global allocator.
+///
+/// # Safety
+///
+/// - Takes no arguments; always safe to call
+#[cfg(feature = "alloc-stats")]
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn html_to_markdown_reset_peak_bytes() {
+    allocation::reset_peak();
+}
+
+/// Get the peak number of bytes the library allocated since the last call to
+/// `html_to_markdown_reset_peak_bytes`, beyond those it held at that call.
+///
+/// # Safety
+///
+/// - Takes no arguments and returns a plain integer; always safe to call
+#[cfg(feature = "alloc-stats")]
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn html_to_markdown_peak_bytes() -> u64 {
+    u64::try_from(allocation::peak_bytes()).unwrap_or(u64::MAX)
+}
+
 /// Convert HTML to Markdown with metadata extraction.
 ///
 /// # Safety
@@ -383,6 +429,68 @@ pub unsafe extern "C" fn html_to_markdown_convert_with_metadata(
     }
 }
 
+/// Extract the metadata of HTML without returning its Markdown.
+///
+/// The metadata is the same `convert_with_metadata` collects, serialized to
+/// JSON like the metadata of `html_to_markdown_convert_with_metadata`.
+///
+/// # Safety
+///
+/// - `html` must be a valid null-terminated C string
+/// - The returned JSON string must be freed with `html_to_markdown_free_string`
+/// - Returns NULL on error (check error with `html_to_markdown_last_error`)
+#[cfg(feature = "metadata")]
+#[unsafe(no_mangle)]
+pub unsafe extern "C" fn html_to_markdown_extract_metadata(html: *const c_char) -> *mut c_char {
+    if html.is_null() {
+        set_last_error(Some("html pointer was null".to_string()));
+        return ptr::null_mut();
+    }
+
+    let html_str = if let Ok(s) = unsafe { CStr::from_ptr(html) }.to_str() {
+        s
+    } else {
+        set_last_error(Some("html must be valid UTF-8".to_string()));
+        return ptr::null_mut();
+    };
+
+    let metadata_cfg = MetadataConfig {
+        extract_document: true,
+        extract_headers: true,
+        extract_links: true,
+        extract_images: true,
+        extract_structured_data: true,
+        max_structured_data_size: DEFAULT_MAX_STRUCTURED_DATA_SIZE,
+    };
+
+    match guard_panic(|| profiling::maybe_profile(|| convert_with_metadata(html_str, None, metadata_cfg, None))) {
+        Ok((_, metadata)) => {
+            let metadata_json = match serde_json::to_vec(&metadata) {
+                Ok(json) => json,
+                Err(e) => {
+                    set_last_error(Some(format!("failed to serialize metadata to JSON: {e}")));
+                    return ptr::null_mut();
+                }
+            };
+
+            match bytes_to_c_string(metadata_json, "metadata JSON") {
+                Ok(c_string) => {
+                    set_last_error(None);
+                    c_string.into_raw()
+                }
+                Err(err) => {
+                    set_last_error(Some(format!("failed to build CString for metadata JSON: {err}")));
+                    ptr::null_mut()
+                }
+            }
+        }
+        Err(err) => {
+            capture_error(err);
+            ptr::null_mut()
+        }
+    }
+}
+
 /// Convert HTML to Markdown with metadata extraction, returning output lengths.
 ///
 /// # Safety
@@ -634,6 +742,27 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_abi_version() {
+        unsafe {
+            assert_eq!(html_to_markdown_abi_version(), HTML_TO_MARKDOWN_ABI_VERSION);
+        }
+    }
+
+    #[cfg(feature = "alloc-stats")]
+    #[test]
+    fn test_peak_bytes_covers_output() {
+        unsafe {
+            let html = CString::new("<p>Some text</p>".repeat(20_000)).unwrap();
+            html_to_markdown_reset_peak_bytes();
+            let result = html_to_markdown_convert(html.as_ptr());
+            assert!(!result.is_null());
+            let output_len = CStr::from_ptr(result).to_bytes().len() as u64;
+            assert!(html_to_markdown_peak_bytes() >= output_len);
+            html_to_markdown_free_string(result);
+        }
+    }
+
     #[test]
     fn test_last_error_clears_after_success() {
         unsafe {
@@ -676,6 +805,34 @@ mod tests {
         }
     }
 
+    #[cfg(feature = "metadata")]
+    #[test]
+    fn test_extract_metadata_matches_convert_with_metadata() {
+        unsafe {
+            let html = CString::new("<html lang=\"en\"><head><title>Test</title></head><body><h1 id=\"heading\">Title</h1><a href=\"https://example.com\">Link</a></body></html>").unwrap();
+            let mut metadata_json: *mut c_char = ptr::null_mut();
+            let result = html_to_markdown_convert_with_metadata(html.as_ptr(), &mut metadata_json);
+            assert!(!result.is_null());
+
+            let extracted = html_to_markdown_extract_metadata(html.as_ptr());
+            assert!(!extracted.is_null());
+            assert_eq!(CStr::from_ptr(extracted), CStr::from_ptr(metadata_json));
+
+            html_to_markdown_free_string(result);
+            html_to_markdown_free_string(metadata_json);
+            html_to_markdown_free_string(extracted);
+        }
+    }
+
+    #[cfg(feature = "metadata")]
+    #[test]
+    fn test_extract_metadata_null_html() {
+        unsafe {
+            assert!(html_to_markdown_extract_metadata(ptr::null()).is_null());
+            assert!(!html_to_markdown_last_error().is_null());
+        }
+    }
+
     #[cfg(feature = "metadata")]
     #[test]
     fn test_convert_with_metadata_json_structure() {
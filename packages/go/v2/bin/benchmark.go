@@ -27,7 +27,7 @@ type BenchmarkResult struct {
 func main() {
 	filePath := flag.String("file", "", "Path to the HTML/HOCR fixture")
 	iterations := flag.Int("iterations", 50, "Number of iterations")
-	_ = flag.String("format", "html", "Fixture format (html or hocr)")
+	format := flag.String("format", "html", "Fixture format (html or hocr)")
 	scenario := flag.String("scenario", "convert-default", "Scenario to benchmark")
 	flag.Parse()
 
@@ -36,6 +36,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *format != "html" && *format != "hocr" {
+		fmt.Fprintf(os.Stderr, "Unsupported format: %s\n", *format)
+		os.Exit(1)
+	}
+
 	if *scenario != "convert-default" && *scenario != "metadata-default" {
 		fmt.Fprintf(os.Stderr, "Unsupported scenario: %s\n", *scenario)
 		os.Exit(1)
@@ -60,7 +65,7 @@ func main() {
 		_ = os.Unsetenv("HTML_TO_MARKDOWN_PROFILE_REPEAT")
 	}
 
-	err = runScenario(html, *scenario)
+	err = runScenario(html, *format, *scenario)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warmup conversion failed: %v\n", err)
 		os.Exit(1)
@@ -81,7 +86,7 @@ func main() {
 
 	start := time.Now()
 	for i := 0; i < *iterations; i++ {
-		err = runScenario(html, *scenario)
+		err = runScenario(html, *format, *scenario)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Conversion failed: %v\n", err)
 			os.Exit(1)
@@ -112,9 +117,15 @@ func main() {
 	}
 }
 
-func runScenario(html string, scenario string) error {
-	switch scenario {
-	case "metadata-default":
+func runScenario(html string, format string, scenario string) error {
+	switch {
+	case format == "hocr" && scenario == "metadata-default":
+		_, err := htmltomarkdown.ConvertHOCRWithMetadata(html)
+		return err
+	case format == "hocr":
+		_, err := htmltomarkdown.ConvertHOCR(html)
+		return err
+	case scenario == "metadata-default":
 		_, err := htmltomarkdown.ConvertWithMetadata(html)
 		return err
 	default:
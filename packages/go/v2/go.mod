@@ -1,3 +1,5 @@
 module github.com/kreuzberg-dev/html-to-markdown/packages/go/v2
 
-go 1.25
+go 1.25.0
+
+require golang.org/x/net v0.58.0
@@ -0,0 +1,38 @@
+package htmltomarkdown
+
+import "strings"
+
+// renderAddresses replaces every <address> with a block fragment in the
+// requested style. Each line of the converted content, whether it ends at a
+// <br> or a block element, becomes one line of the output.
+func renderAddresses(root *htmlNode, style AddressStyle, fragments *fragmentSet) error {
+	addresses := root.findAll("address")
+	for i := len(addresses) - 1; i >= 0; i-- {
+		n := addresses[i]
+		markdown, err := convertFFI(n.renderChildren())
+		if err != nil {
+			return err
+		}
+
+		var lines []string
+		for _, line := range strings.Split(fragments.restore(markdown), "\n") {
+			line = strings.TrimSpace(strings.TrimSuffix(strings.TrimRight(line, " "), `\`))
+			if line == "" {
+				continue
+			}
+			switch style {
+			case AddressStyleItalic:
+				line = "*" + line + "*"
+			case AddressStyleBlockquote:
+				line = "> " + line
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			n.remove()
+			continue
+		}
+		n.replaceWith(fragments.node(strings.Join(lines, "  \n"), false))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package htmltomarkdown
+
+import "strings"
+
+// renderAdmonitions replaces the elements carrying one of the classes of
+// classes with a GFM alert: a block quote opened by a [!KEYWORD] line. When
+// an element has several mapped classes, the first one in its class attribute
+// wins. Nested admonitions render innermost first.
+func renderAdmonitions(root *htmlNode, classes map[string]string, fragments *fragmentSet) error {
+	var admonitions []*htmlNode
+	keywords := map[*htmlNode]string{}
+	root.walk(func(n *htmlNode) bool {
+		if n.typ != htmlElementNode {
+			return true
+		}
+		if n.tag == "pre" || n.tag == "code" {
+			return false
+		}
+		for _, class := range strings.Fields(n.attrOr("class", "")) {
+			if keyword, ok := classes[class]; ok {
+				admonitions = append(admonitions, n)
+				keywords[n] = keyword
+				break
+			}
+		}
+		return true
+	})
+
+	for i := len(admonitions) - 1; i >= 0; i-- {
+		n := admonitions[i]
+		markdown, err := convertFFI(n.renderChildren())
+		if err != nil {
+			return err
+		}
+		lines := []string{"> [!" + keywords[n] + "]"}
+		if content := strings.Trim(fragments.restore(markdown), "\n"); content != "" {
+			for _, line := range strings.Split(content, "\n") {
+				lines = append(lines, strings.TrimRight("> "+line, " "))
+			}
+		}
+		n.replaceWith(fragments.node(strings.Join(lines, "\n"), false))
+	}
+	return nil
+}
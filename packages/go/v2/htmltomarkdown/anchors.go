@@ -0,0 +1,17 @@
+package htmltomarkdown
+
+// keepNamedAnchors replaces every <a name> without an href by a raw HTML
+// anchor placed where the element started, followed by its content.
+func keepNamedAnchors(root *htmlNode, fragments *fragmentSet) {
+	for _, a := range root.findAll("a") {
+		name, ok := a.attr("name")
+		if !ok || name == "" {
+			continue
+		}
+		if _, hasHref := a.attr("href"); hasHref {
+			continue
+		}
+		anchor := fragments.node(`<a name="`+escapeAttr(name)+`"></a>`, true)
+		a.replaceWith(append([]*htmlNode{anchor}, a.children...)...)
+	}
+}
@@ -0,0 +1,79 @@
+package htmltomarkdown
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ASTNode is a node of the document tree returned by ConvertToAST.
+type ASTNode struct {
+	// Type is "document", "element", "text", "comment" or "doctype".
+	Type string `json:"type"`
+
+	// Tag is the lowercase tag name of an element.
+	Tag string `json:"tag,omitempty"`
+
+	// Attributes holds an element's attributes with their values unescaped.
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Text is the unescaped content of a text or comment node, or the
+	// markup of a doctype.
+	Text string `json:"text,omitempty"`
+
+	Children []ASTNode `json:"children,omitempty"`
+}
+
+// ConvertToAST parses html and returns the document tree that drives the
+// conversion as JSON, rooted at a "document" node. The tree is the one the
+// HTML5 parsing algorithm builds, so it always holds <html>, <head> and
+// <body> elements. Lazy-loaded images and <picture> elements are normalized
+// as they are for Convert. Documents nesting elements deeper than 512 levels
+// fail with a *ConversionError of kind ConversionErrorMaxDepthExceeded.
+//
+// Example:
+//
+//	data, err := htmltomarkdown.ConvertToAST("<p>Hi <b>there</b></p>")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var root htmltomarkdown.ASTNode
+//	_ = json.Unmarshal(data, &root)
+func ConvertToAST(html string) ([]byte, error) {
+	root, err := parseDocument(html)
+	if err != nil {
+		return nil, err
+	}
+	applyLazyLoadAttrs(root, defaultLazyLoadAttrs)
+	normalizePictures(root)
+	return json.Marshal(newASTNode(root))
+}
+
+func newASTNode(n *htmlNode) ASTNode {
+	var node ASTNode
+	switch n.typ {
+	case htmlDocumentNode:
+		node.Type = "document"
+	case htmlElementNode:
+		node.Type = "element"
+		node.Tag = n.tag
+		if len(n.attrs) > 0 {
+			node.Attributes = make(map[string]string, len(n.attrs))
+			for _, a := range n.attrs {
+				node.Attributes[a.Key] = a.Val
+			}
+		}
+	case htmlTextNode:
+		node.Type = "text"
+		node.Text = n.text()
+	case htmlCommentNode:
+		node.Type = "comment"
+		node.Text = strings.TrimSuffix(strings.TrimPrefix(n.data, "<!--"), "-->")
+	case htmlDoctypeNode:
+		node.Type = "doctype"
+		node.Text = n.data
+	}
+	for _, c := range n.children {
+		node.Children = append(node.Children, newASTNode(c))
+	}
+	return node
+}
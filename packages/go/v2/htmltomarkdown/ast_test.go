@@ -0,0 +1,36 @@
+package htmltomarkdown
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConvertToAST(t *testing.T) {
+	data, err := ConvertToAST(`<p class="intro">Hi <b>there</b> &amp; you</p>`)
+	if err != nil {
+		t.Fatalf("ConvertToAST failed: %v", err)
+	}
+	if !strings.Contains(string(data), `{"type":"element","tag":"b","children":[{"type":"text","text":"there"}]}`) {
+		t.Errorf("ConvertToAST() = %s, expected the nested bold node", data)
+	}
+
+	var root ASTNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("Failed to unmarshal AST: %v", err)
+	}
+	if root.Type != "document" || len(root.Children) != 1 || len(root.Children[0].Children) != 2 {
+		t.Fatalf("root = %+v, expected a document with <html>, <head> and <body>", root)
+	}
+	body := root.Children[0].Children[1]
+	if body.Tag != "body" || len(body.Children) != 1 {
+		t.Fatalf("body = %+v, expected one child", body)
+	}
+	p := body.Children[0]
+	if p.Tag != "p" || p.Attributes["class"] != "intro" {
+		t.Errorf("paragraph = %+v, expected <p class=\"intro\">", p)
+	}
+	if len(p.Children) != 3 || p.Children[2].Text != " & you" {
+		t.Errorf("paragraph children = %+v, expected decoded trailing text", p.Children)
+	}
+}
@@ -0,0 +1,172 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultCodeLanguageClasses are the class prefixes marking the language of
+// a code block, as written by Prism and highlight.js.
+var defaultCodeLanguageClasses = []string{"language-", "lang-"}
+
+// renderCodeBlocks rewrites every <pre> block as a fenced code block whose
+// info string is the language found by codeLanguage with prefixes. The text
+// is kept as written, apart from the newlines at its edges.
+func renderCodeBlocks(root *htmlNode, prefixes []string, fragments *fragmentSet) {
+	for _, pre := range root.findAll("pre") {
+		if pre.hasAncestor("pre") {
+			continue
+		}
+		code := strings.TrimPrefix(strings.TrimRight(pre.text(), "\n"), "\n")
+		pre.replaceWith(fragments.node(codeFence(code, codeLanguage(pre, prefixes)), false))
+	}
+}
+
+// renderTextareas rewrites every <textarea> in the requested style. Like
+// browsers, a newline right after the start tag is not part of the content.
+func renderTextareas(root *htmlNode, style TextareaStyle, fragments *fragmentSet) {
+	for _, n := range root.findAll("textarea") {
+		text := strings.TrimRight(strings.TrimPrefix(n.text(), "\n"), "\n")
+		switch {
+		case style == TextareaStyleDrop, strings.TrimSpace(text) == "":
+			n.remove()
+		case style == TextareaStyleCode:
+			n.replaceWith(fragments.node(codeFence(text, ""), false))
+		case style == TextareaStyleText:
+			p := newElementNode("p")
+			for i, line := range strings.Split(text, "\n") {
+				if i > 0 {
+					p.appendChild(newElementNode("br"))
+				}
+				p.appendChild(&htmlNode{typ: htmlTextNode, data: textEscaper.Replace(strings.TrimSpace(line))})
+			}
+			n.replaceWith(p)
+		}
+	}
+}
+
+// codeLanguage extracts the language of a <pre> block from a class starting
+// with one of prefixes on the block or its <code> child, falling back to a
+// lang attribute on either of them.
+func codeLanguage(pre *htmlNode, prefixes []string) string {
+	candidates := []*htmlNode{pre}
+	for _, c := range pre.children {
+		if c.typ == htmlElementNode && c.tag == "code" {
+			candidates = append(candidates, c)
+		}
+	}
+	for _, node := range candidates {
+		for _, class := range strings.Fields(node.attrOr("class", "")) {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(class, prefix) && len(class) > len(prefix) {
+					return class[len(prefix):]
+				}
+			}
+		}
+	}
+	for _, node := range candidates {
+		if lang := strings.TrimSpace(node.attrOr("lang", "")); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// lineNumberClasses mark the line-number gutters rendered next to code by
+// highlighters such as Pygments, Rouge, highlight.js and Prism.
+var lineNumberClasses = []string{
+	"linenos", "lineno", "line-number", "line-numbers-rows", "gutter", "hljs-ln-numbers", "blob-num",
+}
+
+// stripCodeLineNumbers removes the line numbers highlighters render beside
+// code. Tables laying out a gutter column next to the code become a code
+// block holding the code alone, and gutter elements inside <pre> blocks are
+// dropped.
+func stripCodeLineNumbers(root *htmlNode, prefixes []string, fragments *fragmentSet) {
+	tables := root.findAll("table")
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		code, lang, ok := gutterTableCode(table, prefixes)
+		switch {
+		case !ok:
+		case table.hasAncestor("pre"):
+			table.replaceWith(&htmlNode{typ: htmlTextNode, data: textEscaper.Replace(code)})
+		default:
+			table.replaceWith(fragments.node(codeFence(code, lang), false))
+		}
+	}
+
+	var gutters []*htmlNode
+	for _, pre := range root.findAll("pre") {
+		for _, c := range pre.children {
+			c.walk(func(n *htmlNode) bool {
+				if n.typ == htmlElementNode && isLineNumberGutter(n) {
+					gutters = append(gutters, n)
+					return false
+				}
+				return true
+			})
+		}
+	}
+	for _, n := range gutters {
+		n.remove()
+	}
+}
+
+// gutterTableCode returns the code and language of a table whose rows pair
+// a line-number cell with a code cell. The gutter must carry one of the
+// lineNumberClasses, or hold only numbers next to a cell with a <pre> or
+// <code> element, so that ordinary numbered data tables are left alone.
+func gutterTableCode(table *htmlNode, prefixes []string) (code, lang string, ok bool) {
+	var lines []string
+	marked := false
+	for _, row := range table.findAll("tr") {
+		var cells []*htmlNode
+		for _, c := range row.children {
+			if c.typ == htmlElementNode && (c.tag == "td" || c.tag == "th") {
+				cells = append(cells, c)
+			}
+		}
+		if len(cells) != 2 {
+			return "", "", false
+		}
+		gutter, content := cells[0], cells[1]
+		if isLineNumberGutter(gutter) {
+			marked = true
+		} else if !isNumberColumn(gutter.text()) {
+			return "", "", false
+		}
+		if pres := content.findAll("pre", "code"); len(pres) > 0 {
+			marked = true
+			if lang == "" && pres[0].tag == "pre" {
+				lang = codeLanguage(pres[0], prefixes)
+			}
+		}
+		lines = append(lines, strings.TrimPrefix(strings.TrimRight(content.text(), "\n"), "\n"))
+	}
+	if !marked || len(lines) == 0 {
+		return "", "", false
+	}
+	return strings.Join(lines, "\n"), lang, true
+}
+
+func isLineNumberGutter(n *htmlNode) bool {
+	for _, class := range lineNumberClasses {
+		if n.hasClass(class) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumberColumn reports whether text holds only whitespace-separated line
+// numbers.
+func isNumberColumn(text string) bool {
+	fields := strings.Fields(text)
+	for _, field := range fields {
+		if _, err := strconv.Atoi(field); err != nil {
+			return false
+		}
+	}
+	return len(fields) > 0
+}
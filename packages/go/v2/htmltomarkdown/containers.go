@@ -0,0 +1,56 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// neutralAttrs are the attributes that leave a wrapper's output unchanged, so
+// containers carrying only these can be unwrapped.
+var neutralAttrs = map[string]bool{
+	"dir": true, "lang": true, "role": true, "tabindex": true, "title": true,
+}
+
+// unwrapRedundantContainers removes the <div> and <span> wrappers that carry
+// no meaning: spans without attributes affecting the output, and divs whose
+// content is made of block elements only. Their children take their place.
+// Empty divs are removed.
+func unwrapRedundantContainers(root *htmlNode) {
+	containers := root.findAll("div", "span")
+	for i := len(containers) - 1; i >= 0; i-- {
+		n := containers[i]
+		if !hasNeutralAttrs(n) || n.hasAncestor("pre", "code") {
+			continue
+		}
+		if n.tag == "span" || holdsBlocksOnly(n) {
+			n.replaceWith(n.children...)
+		}
+	}
+}
+
+func hasNeutralAttrs(n *htmlNode) bool {
+	for _, a := range n.attrs {
+		if !neutralAttrs[strings.ToLower(a.Key)] {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsBlocksOnly reports whether the children of n are block elements,
+// comments and whitespace.
+func holdsBlocksOnly(n *htmlNode) bool {
+	for _, c := range n.children {
+		switch c.typ {
+		case htmlTextNode:
+			if strings.TrimSpace(html.UnescapeString(c.data)) != "" {
+				return false
+			}
+		case htmlElementNode:
+			if !fallbackIsBlock(c.tag) {
+				return false
+			}
+		}
+	}
+	return true
+}
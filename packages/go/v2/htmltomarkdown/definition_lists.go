@@ -0,0 +1,75 @@
+package htmltomarkdown
+
+import "strings"
+
+// definitionItems returns the <dt> and <dd> elements of a definition list in
+// document order, looking through the <div> wrappers HTML allows around groups.
+func definitionItems(dl *htmlNode) []*htmlNode {
+	var items []*htmlNode
+	for _, c := range dl.children {
+		if c.typ != htmlElementNode {
+			continue
+		}
+		switch c.tag {
+		case "dt", "dd":
+			items = append(items, c)
+		case "div":
+			items = append(items, definitionItems(c)...)
+		}
+	}
+	return items
+}
+
+// renderDefinitionLists replaces every <dl> with a block fragment in the
+// requested style. Inner lists are rendered first so that definitions holding
+// a nested list carry its rendered form.
+func renderDefinitionLists(root *htmlNode, style DefinitionListStyle, fragments *fragmentSet) error {
+	lists := root.findAll("dl")
+	for i := len(lists) - 1; i >= 0; i-- {
+		dl := lists[i]
+		if style == DefinitionListStyleHTML {
+			dl.replaceWith(fragments.node(fragments.restore(dl.render()), false))
+			continue
+		}
+		markdown, err := definitionListMarkdown(dl, style, fragments)
+		if err != nil {
+			return err
+		}
+		dl.replaceWith(fragments.node(markdown, false))
+	}
+	return nil
+}
+
+func definitionListMarkdown(dl *htmlNode, style DefinitionListStyle, fragments *fragmentSet) (string, error) {
+	var b strings.Builder
+	lastWasDefinition := false
+	for _, item := range definitionItems(dl) {
+		content, err := convertFFI(item.renderChildren())
+		if err != nil {
+			return "", err
+		}
+		content = strings.Trim(fragments.restore(content), "\n")
+		if content == "" {
+			continue
+		}
+
+		if item.tag == "dt" {
+			if lastWasDefinition {
+				b.WriteString("\n")
+			}
+			if style == DefinitionListStyleBold {
+				content = "**" + content + "**"
+			}
+			b.WriteString(content + "\n")
+			lastWasDefinition = false
+			continue
+		}
+
+		if style == DefinitionListStyleColon {
+			content = ": " + indentContinuation(content, "    ")
+		}
+		b.WriteString(content + "\n")
+		lastWasDefinition = true
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,135 @@
+package htmltomarkdown
+
+import "strings"
+
+// emojiShortcodes maps emoji to their GitHub shortcode names. Emoji written
+// with the U+FE0F variation selector are looked up without it.
+var emojiShortcodes = map[string]string{
+	"😀": "grinning", "😃": "smiley", "😄": "smile", "😁": "grin", "😆": "laughing",
+	"😅": "sweat_smile", "🤣": "rofl", "😂": "joy", "🙂": "slightly_smiling_face",
+	"🙃": "upside_down_face", "😉": "wink", "😊": "blush", "😇": "innocent",
+	"🥰": "smiling_face_with_three_hearts", "😍": "heart_eyes", "🤩": "star_struck",
+	"😘": "kissing_heart", "😋": "yum", "😛": "stuck_out_tongue", "😜": "stuck_out_tongue_winking_eye",
+	"🤪": "zany_face", "🤔": "thinking", "🤨": "raised_eyebrow", "😐": "neutral_face",
+	"😑": "expressionless", "😶": "no_mouth", "🙄": "roll_eyes", "😏": "smirk",
+	"😬": "grimacing", "😌": "relieved", "😔": "pensive", "😴": "sleeping",
+	"😷": "mask", "🤒": "face_with_thermometer", "🤯": "exploding_head", "🥳": "partying_face",
+	"😎": "sunglasses", "🤓": "nerd_face", "😕": "confused", "😟": "worried",
+	"😮": "open_mouth", "😲": "astonished", "😳": "flushed", "🥺": "pleading_face",
+	"😢": "cry", "😭": "sob", "😱": "scream", "😞": "disappointed", "😓": "sweat",
+	"😩": "weary", "😫": "tired_face", "😤": "triumph", "😡": "rage", "😠": "angry",
+	"🤬": "cursing_face", "😈": "smiling_imp", "💀": "skull", "💩": "hankey",
+	"🤡": "clown_face", "👻": "ghost", "👽": "alien", "🤖": "robot",
+	"😺": "smiley_cat", "🙈": "see_no_evil", "🙉": "hear_no_evil", "🙊": "speak_no_evil",
+	"💋": "kiss", "💯": "100", "💥": "boom", "💫": "dizzy", "💦": "sweat_drops", "💤": "zzz",
+	"👋": "wave", "👌": "ok_hand", "✌": "v", "🤞": "crossed_fingers", "👈": "point_left",
+	"👉": "point_right", "👆": "point_up_2", "👇": "point_down", "☝": "point_up",
+	"👍": "+1", "👎": "-1", "✊": "fist", "👊": "facepunch", "👏": "clap",
+	"🙌": "raised_hands", "👐": "open_hands", "🤝": "handshake", "🙏": "pray",
+	"✍": "writing_hand", "💪": "muscle", "👀": "eyes", "🧠": "brain",
+	"❤": "heart", "🧡": "orange_heart", "💛": "yellow_heart", "💚": "green_heart",
+	"💙": "blue_heart", "💜": "purple_heart", "🖤": "black_heart", "💔": "broken_heart",
+	"💕": "two_hearts", "💖": "sparkling_heart",
+	"🐶": "dog", "🐱": "cat", "🐭": "mouse", "🦊": "fox_face", "🐻": "bear", "🐼": "panda_face",
+	"🐸": "frog", "🐵": "monkey_face", "🐔": "chicken", "🐧": "penguin", "🐦": "bird",
+	"🐍": "snake", "🐢": "turtle", "🐙": "octopus", "🐛": "bug", "🦋": "butterfly", "🐝": "bee",
+	"🌲": "evergreen_tree", "🌳": "deciduous_tree", "🌵": "cactus", "🌷": "tulip", "🌹": "rose",
+	"🌻": "sunflower", "🌸": "cherry_blossom", "🍀": "four_leaf_clover", "🍁": "maple_leaf",
+	"🌍": "earth_africa", "🌎": "earth_americas", "🌏": "earth_asia", "🌙": "crescent_moon",
+	"⭐": "star", "🌟": "star2", "✨": "sparkles", "⚡": "zap", "🔥": "fire", "🌈": "rainbow",
+	"☀": "sunny", "⛅": "partly_sunny", "☁": "cloud", "❄": "snowflake", "☔": "umbrella", "💧": "droplet",
+	"🍎": "apple", "🍊": "tangerine", "🍋": "lemon", "🍌": "banana", "🍉": "watermelon",
+	"🍇": "grapes", "🍓": "strawberry", "🍒": "cherries", "🍑": "peach", "🥑": "avocado",
+	"🍕": "pizza", "🍔": "hamburger", "🍟": "fries", "🌮": "taco", "🍣": "sushi", "🍩": "doughnut",
+	"🍪": "cookie", "🎂": "birthday", "🍰": "cake", "☕": "coffee", "🍵": "tea", "🍺": "beer",
+	"🍻": "beers", "🍷": "wine_glass",
+	"⚽": "soccer", "🏀": "basketball", "🏈": "football", "⚾": "baseball", "🎾": "tennis",
+	"🏆": "trophy", "🥇": "1st_place_medal", "🎮": "video_game", "🎲": "game_die", "🎯": "dart",
+	"🎵": "musical_note", "🎶": "notes", "🎸": "guitar", "🎨": "art", "🎬": "clapper",
+	"🎉": "tada", "🎊": "confetti_ball", "🎁": "gift", "🎈": "balloon", "🎄": "christmas_tree",
+	"🚀": "rocket", "✈": "airplane", "🚗": "car", "🚕": "taxi", "🚌": "bus", "🚲": "bike",
+	"🚢": "ship", "🚨": "rotating_light", "🚧": "construction", "🏠": "house", "🏢": "office",
+	"⌚": "watch", "📱": "iphone", "💻": "computer", "⌨": "keyboard", "🖥": "desktop_computer",
+	"🖨": "printer", "💾": "floppy_disk", "💿": "cd", "📷": "camera", "📺": "tv",
+	"🔋": "battery", "🔌": "electric_plug", "💡": "bulb", "🔦": "flashlight",
+	"💰": "moneybag", "💸": "money_with_wings", "💳": "credit_card", "💎": "gem",
+	"🔧": "wrench", "🔨": "hammer", "🔩": "nut_and_bolt", "⚙": "gear", "🧰": "toolbox",
+	"🔒": "lock", "🔓": "unlock", "🔑": "key", "🛠": "hammer_and_wrench", "🧪": "test_tube",
+	"🔬": "microscope", "🔭": "telescope", "💊": "pill", "🧹": "broom",
+	"📦": "package", "📫": "mailbox", "✉": "envelope", "📧": "email", "📝": "memo",
+	"📄": "page_facing_up", "📅": "date", "📆": "calendar", "📈": "chart_with_upwards_trend",
+	"📉": "chart_with_downwards_trend", "📊": "bar_chart", "📋": "clipboard", "📌": "pushpin",
+	"📎": "paperclip", "📏": "straight_ruler", "✂": "scissors", "📁": "file_folder",
+	"📚": "books", "📖": "book", "🔖": "bookmark", "🔗": "link", "✏": "pencil2",
+	"🔍": "mag", "🔎": "mag_right", "📣": "mega", "📢": "loudspeaker", "🔔": "bell",
+	"🔕": "no_bell", "⏰": "alarm_clock", "⏳": "hourglass_flowing_sand", "⌛": "hourglass",
+	"✅": "white_check_mark", "☑": "ballot_box_with_check", "✔": "heavy_check_mark",
+	"❌": "x", "❎": "negative_squared_cross_mark", "➕": "heavy_plus_sign", "➖": "heavy_minus_sign",
+	"❓": "question", "❔": "grey_question", "❗": "exclamation", "❕": "grey_exclamation",
+	"‼": "bangbang", "⁉": "interrobang", "⚠": "warning", "⛔": "no_entry", "🚫": "no_entry_sign",
+	"🆕": "new", "🆗": "ok", "🆙": "up", "🆒": "cool", "🆓": "free", "ℹ": "information_source",
+	"♻": "recycle", "🔴": "red_circle", "🟢": "green_circle", "🔵": "large_blue_circle",
+	"⚪": "white_circle", "⚫": "black_circle", "➡": "arrow_right", "⬅": "arrow_left",
+	"⬆": "arrow_up", "⬇": "arrow_down", "🔄": "arrows_counterclockwise", "🔁": "repeat",
+	"▶": "arrow_forward", "⏸": "pause_button", "⏹": "stop_button", "©": "copyright",
+	"®": "registered", "™": "tm", "🏁": "checkered_flag", "🚩": "triangular_flag_on_post",
+}
+
+// variationSelector requests the emoji presentation of the preceding character.
+const variationSelector = '\uFE0F'
+
+// emojiShortcode returns the shortcode for s, ignoring variation selectors.
+// Alt text that already is a shortcode, as on GitHub's emoji images, is
+// returned as is.
+func emojiShortcode(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.HasPrefix(s, ":") && strings.HasSuffix(s, ":") && !strings.ContainsAny(s, " \t") {
+		return s, true
+	}
+	name, ok := emojiShortcodes[strings.ReplaceAll(s, string(variationSelector), "")]
+	if !ok {
+		return "", false
+	}
+	return ":" + name + ":", true
+}
+
+// replaceEmoji replaces every known emoji in s with its shortcode.
+func replaceEmoji(s string) string {
+	var b strings.Builder
+	skipSelector := false
+	for _, r := range s {
+		if skipSelector && r == variationSelector {
+			skipSelector = false
+			continue
+		}
+		name, ok := emojiShortcodes[string(r)]
+		skipSelector = ok
+		if ok {
+			b.WriteString(":" + name + ":")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// replaceEmojiShortcodes rewrites emoji images and Unicode emoji in text as
+// GitHub shortcodes. Emoji without a known shortcode are left unchanged, as
+// is text inside code.
+func replaceEmojiShortcodes(root *htmlNode) {
+	root.walk(func(n *htmlNode) bool {
+		switch {
+		case n.typ == htmlTextNode:
+			n.data = replaceEmoji(n.data)
+		case n.typ != htmlElementNode && n.typ != htmlDocumentNode:
+			return false
+		case n.tag == "code" || n.tag == "pre" || rawTextElements[n.tag]:
+			return false
+		case n.tag == "img" && n.hasClass("emoji"):
+			if code, ok := emojiShortcode(n.attrOr("alt", "")); ok {
+				n.replaceWith(&htmlNode{typ: htmlTextNode, data: code})
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,31 @@
+package htmltomarkdown
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEmojiShortcodesTable(t *testing.T) {
+	for emoji, name := range emojiShortcodes {
+		if utf8.RuneCountInString(emoji) != 1 {
+			t.Errorf("emoji %q for %q must be a single rune without variation selector", emoji, name)
+		}
+	}
+}
+
+func TestReplaceEmoji(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Ship it 🚀", want: "Ship it :rocket:"},
+		{in: "I ❤️ Go", want: "I :heart: Go"},
+		{in: "Unknown 🫠 stays", want: "Unknown 🫠 stays"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceEmoji(tt.in); got != tt.want {
+			t.Errorf("replaceEmoji(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// escapeAllCharacters are escaped wherever they appear in EscapeModeAll.
+const escapeAllCharacters = "\\`*_[]#+-!|~<>"
+
+// literalTextElements hold text that is rendered verbatim and never escaped.
+var literalTextElements = []string{"code", "pre", "kbd", "samp"}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeMarkdownText backslash-escapes the Markdown syntax characters in the
+// text nodes of root according to mode.
+func escapeMarkdownText(root *htmlNode, mode EscapeMode) {
+	root.walk(func(n *htmlNode) bool {
+		switch n.typ {
+		case htmlDocumentNode:
+			return true
+		case htmlElementNode:
+			if containsString(literalTextElements, n.tag) || rawTextElements[n.tag] || isAutolink(n) {
+				return false
+			}
+			return true
+		case htmlTextNode:
+			text := html.UnescapeString(n.data)
+			if strings.TrimSpace(text) == "" {
+				return false
+			}
+			escaped := escapeText(text, mode, startsLine(n), n.hasAncestor("td", "th"))
+			if escaped != text {
+				n.data = textEscaper.Replace(escaped)
+			}
+		}
+		return false
+	})
+}
+
+// isAutolink reports whether a is a link whose text is its URL, which the
+// native converter renders as an autolink.
+func isAutolink(a *htmlNode) bool {
+	if a.tag != "a" {
+		return false
+	}
+	href, ok := a.attr("href")
+	return ok && strings.TrimSpace(a.text()) == href
+}
+
+// startsLine reports whether the text node n begins a line of output: no
+// content precedes it within its block, or a <br> does.
+func startsLine(n *htmlNode) bool {
+	for cur := n; cur.parent != nil; cur = cur.parent {
+		siblings := cur.parent.children[:cur.index()]
+		for i := len(siblings) - 1; i >= 0; i-- {
+			sib := siblings[i]
+			if sib.typ == htmlElementNode && sib.tag == "br" {
+				return true
+			}
+			if hasVisibleContent(sib) {
+				return false
+			}
+		}
+		if cur.parent.typ != htmlElementNode || !isInlineTag(cur.parent.tag) {
+			return true
+		}
+	}
+	return true
+}
+
+func hasVisibleContent(n *htmlNode) bool {
+	switch n.typ {
+	case htmlTextNode:
+		return strings.TrimSpace(n.data) != ""
+	case htmlElementNode:
+		return n.tag == "img" || strings.TrimSpace(n.text()) != ""
+	default:
+		return false
+	}
+}
+
+// escapeText escapes text for mode. lineStart reports whether the text begins
+// a line, where list, heading and quote markers would take effect; inTable
+// reports whether it sits in a table cell, where pipes end the cell.
+func escapeText(text string, mode EscapeMode, lineStart, inTable bool) string {
+	if mode == EscapeModeNone {
+		return text
+	}
+	runes := []rune(text)
+	markerAt := -1
+	if lineStart {
+		markerAt = lineMarkerIndex(runes)
+	}
+	ctx := escapeContext{runes: runes, inTable: inTable, backticks: strings.Count(text, "`"), lastBracket: -1}
+	for i, r := range runes {
+		if r == ']' {
+			ctx.lastBracket = i
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range runes {
+		if i == markerAt || (mode == EscapeModeAll && strings.ContainsRune(escapeAllCharacters, r)) ||
+			(mode == EscapeModeSmart && ctx.isAmbiguous(i)) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lineMarkerIndex returns the index of the character that would turn the
+// start of runes into a list item, heading or block quote, or -1.
+func lineMarkerIndex(runes []rune) int {
+	i := 0
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	if i == len(runes) {
+		return -1
+	}
+	followedBySpace := func(j int) bool { return j >= len(runes) || unicode.IsSpace(runes[j]) }
+
+	switch r := runes[i]; {
+	case r == '#' || r == '>':
+		return i
+	case (r == '-' || r == '+' || r == '*') && followedBySpace(i+1):
+		return i
+	case r >= '0' && r <= '9':
+		j := i
+		for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+			j++
+		}
+		if j < len(runes) && (runes[j] == '.' || runes[j] == ')') && followedBySpace(j+1) {
+			return j
+		}
+	}
+	return -1
+}
+
+// escapeContext describes the text being escaped in EscapeModeSmart.
+type escapeContext struct {
+	runes       []rune
+	inTable     bool
+	backticks   int // number of backticks in the text
+	lastBracket int // index of the last ']' in the text, or -1
+}
+
+// isAmbiguous reports whether runes[i] could be read as Markdown syntax.
+func (c *escapeContext) isAmbiguous(i int) bool {
+	prev, next := ' ', ' '
+	if i > 0 {
+		prev = c.runes[i-1]
+	}
+	if i+1 < len(c.runes) {
+		next = c.runes[i+1]
+	}
+
+	switch c.runes[i] {
+	case '*':
+		return !unicode.IsSpace(prev) || !unicode.IsSpace(next)
+	case '_':
+		if isWordRune(prev) && isWordRune(next) {
+			return false
+		}
+		return !unicode.IsSpace(prev) || !unicode.IsSpace(next)
+	case '\\':
+		return next < unicode.MaxASCII && (unicode.IsPunct(next) || unicode.IsSymbol(next))
+	case '`':
+		return c.backticks > 1
+	case '[':
+		return c.lastBracket > i
+	case '~':
+		return prev == '~' || next == '~'
+	case '|':
+		return c.inTable
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
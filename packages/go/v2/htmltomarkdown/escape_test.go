@@ -0,0 +1,50 @@
+package htmltomarkdown
+
+import "testing"
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		mode      EscapeMode
+		lineStart bool
+		inTable   bool
+		want      string
+	}{
+		{name: "smart emphasis", text: "a *b* c", mode: EscapeModeSmart, want: `a \*b\* c`},
+		{name: "smart lone asterisk", text: "5 * 3", mode: EscapeModeSmart, want: "5 * 3"},
+		{name: "smart snake case", text: "my_var_name", mode: EscapeModeSmart, want: "my_var_name"},
+		{name: "smart underscore emphasis", text: "_word_", mode: EscapeModeSmart, want: `\_word\_`},
+		{name: "smart ordered marker", text: "1. item", mode: EscapeModeSmart, lineStart: true, want: `1\. item`},
+		{name: "smart ordered marker mid-line", text: "1. item", mode: EscapeModeSmart, want: "1. item"},
+		{name: "smart bullet marker", text: "- item", mode: EscapeModeSmart, lineStart: true, want: `\- item`},
+		{name: "smart heading marker", text: "# not a heading", mode: EscapeModeSmart, lineStart: true, want: `\# not a heading`},
+		{name: "smart pipe outside table", text: "a | b", mode: EscapeModeSmart, want: "a | b"},
+		{name: "smart pipe in table", text: "a | b", mode: EscapeModeSmart, inTable: true, want: `a \| b`},
+		{name: "smart link brackets", text: "[x] and [y]", mode: EscapeModeSmart, want: `\[x] and \[y]`},
+		{name: "all", text: "a-b *c* _d_ | e", mode: EscapeModeAll, want: `a\-b \*c\* \_d\_ \| e`},
+		{name: "all ordered marker", text: "2) two", mode: EscapeModeAll, lineStart: true, want: `2\) two`},
+		{name: "none", text: "*a* _b_ 1. c", mode: EscapeModeNone, lineStart: true, want: "*a* _b_ 1. c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.text, tt.mode, tt.lineStart, tt.inTable); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartsLine(t *testing.T) {
+	root := parseHTML(`<p>1. first<br>2. second <em>3. third</em></p><p><strong>4. fourth</strong></p>`)
+	want := map[string]bool{"1. first": true, "2. second ": true, "3. third": false, "4. fourth": true}
+	root.walk(func(n *htmlNode) bool {
+		if n.typ == htmlTextNode {
+			if expected, ok := want[n.data]; ok && startsLine(n) != expected {
+				t.Errorf("startsLine(%q) = %v, want %v", n.data, !expected, expected)
+			}
+		}
+		return true
+	})
+}
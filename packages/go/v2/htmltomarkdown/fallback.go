@@ -0,0 +1,279 @@
+package htmltomarkdown
+
+import (
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNativeUnavailable is returned by the functions that need the native
+// library, such as ConvertWithMetadata, when the package is built without cgo.
+var ErrNativeUnavailable = errors.New("html-to-markdown native library is unavailable: the package was built without cgo")
+
+var (
+	fallbackMutex sync.RWMutex
+	fallback      func(string) (string, error)
+)
+
+// SetFallback registers fn as the converter used by Convert when the native
+// library cannot be loaded, instead of returning the *LibraryLoadError. Pass
+// ConvertFallback for the built-in pure-Go converter, or nil to restore the
+// default behavior.
+//
+// Example:
+//
+//	htmltomarkdown.SetFallback(htmltomarkdown.ConvertFallback)
+func SetFallback(fn func(string) (string, error)) {
+	fallbackMutex.Lock()
+	defer fallbackMutex.Unlock()
+	fallback = fn
+}
+
+// currentFallback returns the converter registered with SetFallback, if any.
+func currentFallback() func(string) (string, error) {
+	fallbackMutex.RLock()
+	defer fallbackMutex.RUnlock()
+	return fallback
+}
+
+// ConvertFallback converts html with the pure-Go converter used when the
+// package is built without cgo, and which SetFallback can install for builds
+// whose native library fails to load. It covers the basic elements: headings,
+// paragraphs, lists, links, images, emphasis, code, block quotes and
+// horizontal rules. Other elements contribute their text only, so tables,
+// definition lists and the like lose their structure, and no metadata is
+// extracted.
+func ConvertFallback(src string) (string, error) {
+	markdown := strings.Join(fallbackBlocks(parseHTML(src).children), "\n\n")
+	if markdown == "" {
+		return "", nil
+	}
+	return markdown + "\n", nil
+}
+
+// fallbackSkipped lists the elements whose content never reaches the output.
+var fallbackSkipped = map[string]bool{
+	"head": true, "script": true, "style": true, "template": true,
+	"title": true, "noscript": true,
+}
+
+// fallbackBlocks renders nodes as a list of Markdown blocks. Runs of inline
+// content between block elements form paragraphs.
+func fallbackBlocks(nodes []*htmlNode) []string {
+	var blocks []string
+	var run strings.Builder
+	flush := func() {
+		if p := fallbackParagraph(run.String()); p != "" {
+			blocks = append(blocks, p)
+		}
+		run.Reset()
+	}
+
+	for _, n := range nodes {
+		switch {
+		case n.typ == htmlTextNode:
+			run.WriteString(fallbackText(n.data))
+		case n.typ != htmlElementNode, fallbackSkipped[n.tag]:
+		case !fallbackIsBlock(n.tag):
+			run.WriteString(fallbackInline(n))
+		default:
+			flush()
+			if block := fallbackBlock(n); block != "" {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	flush()
+	return blocks
+}
+
+// fallbackIsBlock reports whether tag starts a new block. Unknown elements
+// such as custom elements are treated as inline.
+func fallbackIsBlock(tag string) bool {
+	switch tag {
+	case "html", "body", "ul", "ol", "li", "pre", "hr", "dl", "dt", "dd",
+		"tr", "td", "th", "thead", "tbody", "tfoot", "caption", "center":
+		return true
+	}
+	return paragraphClosers[tag]
+}
+
+func fallbackBlock(n *htmlNode) string {
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.tag[1:])
+		text := fallbackParagraph(fallbackInlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "  \n", " ")
+	case "p":
+		return fallbackParagraph(fallbackInlineChildren(n))
+	case "ul", "ol":
+		return fallbackList(n)
+	case "pre":
+		code := html.UnescapeString(n.text())
+		code = strings.TrimPrefix(strings.TrimRight(code, "\n"), "\n")
+		return codeFence(code, codeLanguage(n, defaultCodeLanguageClasses))
+	case "blockquote":
+		content := strings.Join(fallbackBlocks(n.children), "\n\n")
+		if content == "" {
+			return ""
+		}
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	case "hr":
+		return "---"
+	default:
+		return strings.Join(fallbackBlocks(n.children), "\n\n")
+	}
+}
+
+// fallbackList renders a <ul> or <ol>, numbering ordered items from the
+// start attribute. Items are tight unless one of them holds a paragraph.
+func fallbackList(list *htmlNode) string {
+	number, err := strconv.Atoi(list.attrOr("start", "1"))
+	if err != nil {
+		number = 1
+	}
+	separator := "\n"
+	if len(list.findAll("p")) > 0 {
+		separator = "\n\n"
+	}
+
+	var items []string
+	for _, li := range list.children {
+		if li.typ != htmlElementNode || li.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if list.tag == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		content := strings.Join(fallbackBlocks(li.children), separator)
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, strings.TrimRight(marker+strings.Join(lines, "\n"), " "))
+	}
+	return strings.Join(items, separator)
+}
+
+func fallbackInlineChildren(n *htmlNode) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		switch {
+		case c.typ == htmlTextNode:
+			b.WriteString(fallbackText(c.data))
+		case c.typ == htmlElementNode && !fallbackSkipped[c.tag]:
+			b.WriteString(fallbackInline(c))
+		}
+	}
+	return b.String()
+}
+
+func fallbackInline(n *htmlNode) string {
+	switch n.tag {
+	case "strong", "b":
+		return fallbackFormat(n, "**", "strong", "b")
+	case "em", "i":
+		return fallbackFormat(n, "*", "em", "i")
+	case "del", "s", "strike":
+		return fallbackFormat(n, "~~", "del", "s", "strike")
+	case "code", "kbd", "samp":
+		return inlineCode(html.UnescapeString(n.text()))
+	case "br":
+		return "\n"
+	case "img":
+		return "![" + fallbackEscaper.Replace(n.attrOr("alt", "")) + "](" + n.attrOr("src", "") + fallbackTitle(n) + ")"
+	case "a":
+		text := fallbackInlineChildren(n)
+		href, ok := n.attr("href")
+		if !ok {
+			return text
+		}
+		return fallbackWrap(text, "[", "]("+href+fallbackTitle(n)+")")
+	default:
+		return fallbackInlineChildren(n)
+	}
+}
+
+// fallbackFormat wraps the content of n in delimiter, unless an enclosing
+// element of the same family already applies it.
+func fallbackFormat(n *htmlNode, delimiter string, family ...string) string {
+	if n.hasAncestor(family...) {
+		return fallbackInlineChildren(n)
+	}
+	return fallbackWrap(fallbackInlineChildren(n), delimiter, delimiter)
+}
+
+// fallbackWrap surrounds text with open and closing, keeping surrounding
+// spaces outside so the delimiters stay attached to the words.
+func fallbackWrap(text, open, closing string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + open + trimmed + closing + text[start+len(trimmed):]
+}
+
+func fallbackTitle(n *htmlNode) string {
+	title, ok := n.attr("title")
+	if !ok || title == "" {
+		return ""
+	}
+	return ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+}
+
+// fallbackEscaper backslash-escapes the characters that would otherwise turn
+// text into emphasis, code, links or headings.
+var fallbackEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "#", `\#`)
+
+// fallbackText decodes entities, collapses whitespace and escapes Markdown
+// syntax characters in a text node.
+func fallbackText(data string) string {
+	text := html.UnescapeString(data)
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return fallbackEscaper.Replace(b.String())
+}
+
+// fallbackParagraph trims the lines of an inline run and joins them with
+// hard line breaks; lines come from <br> elements only. A line starting with
+// a list, heading or quote marker has the marker escaped.
+func fallbackParagraph(run string) string {
+	lines := strings.Split(run, "\n")
+	for i, line := range lines {
+		runes := []rune(strings.Join(strings.Fields(line), " "))
+		if at := lineMarkerIndex(runes); at >= 0 {
+			runes = append(runes[:at], append([]rune{'\\'}, runes[at:]...)...)
+		}
+		lines[i] = string(runes)
+	}
+	return strings.Trim(strings.Join(lines, "  \n"), " \n")
+}
@@ -0,0 +1,94 @@
+package htmltomarkdown
+
+import "testing"
+
+func TestConvertFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "headings and paragraphs",
+			html:     "<h1>Title</h1><p>First  paragraph\n with text.</p><h3>Section</h3><p>Second</p>",
+			expected: "# Title\n\nFirst paragraph with text.\n\n### Section\n\nSecond\n",
+		},
+		{
+			name:     "emphasis",
+			html:     "<p>Some <strong>bold</strong>, <em>italic </em>and <code>a &lt; b</code>.</p>",
+			expected: "Some **bold**, *italic* and `a < b`.\n",
+		},
+		{
+			name:     "emphasis edge spaces",
+			html:     "<p>a<em> text</em>b<strong> </strong>c</p>",
+			expected: "a *text*b c\n",
+		},
+		{
+			name:     "empty and nested formatting",
+			html:     "<p>a<strong><em></em></strong>b <b><b>text</b></b> <em>x <i>y</i></em></p>",
+			expected: "ab **text** *x y*\n",
+		},
+		{
+			name:     "links and images",
+			html:     `<p>See <a href="https://example.com" title="Example">the site</a> <img src="a.png" alt="A"></p>`,
+			expected: "See [the site](https://example.com \"Example\") ![A](a.png)\n",
+		},
+		{
+			name:     "markdown syntax in text is escaped",
+			html:     `<p>*not em* and_snake [x] #tag</p><p>1. not list</p><p>- not item</p><p>&gt; not quote</p>`,
+			expected: "\\*not em\\* and\\_snake \\[x\\] \\#tag\n\n1\\. not list\n\n\\- not item\n\n\\> not quote\n",
+		},
+		{
+			name:     "brackets in link text and alt are escaped",
+			html:     `<p><a href="x">a]b</a> <img src="i.png" alt="[c]"></p>`,
+			expected: "[a\\]b](x) ![\\[c\\]](i.png)\n",
+		},
+		{
+			name:     "markers after a line break are escaped",
+			html:     "<p>a<br>2. b</p>",
+			expected: "a  \n2\\. b\n",
+		},
+		{
+			name:     "unordered list",
+			html:     "<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>",
+			expected: "- One\n- Two\n  - Nested\n",
+		},
+		{
+			name:     "ordered list",
+			html:     `<ol start="3"><li>Three</li><li>Four</li></ol>`,
+			expected: "3. Three\n4. Four\n",
+		},
+		{
+			name:     "block quote and code block",
+			html:     "<blockquote><p>Quoted</p></blockquote><pre><code class=\"language-go\">x := 1\n</code></pre>",
+			expected: "> Quoted\n\n```go\nx := 1\n```\n",
+		},
+		{
+			name:     "line break and rule",
+			html:     "<p>Line one<br>Line two</p><hr><div>After</div>",
+			expected: "Line one  \nLine two\n\n---\n\nAfter\n",
+		},
+		{
+			name:     "skipped content",
+			html:     "<html><head><title>T</title><style>p{}</style></head><body><p>Body</p><script>x()</script></body></html>",
+			expected: "Body\n",
+		},
+		{
+			name:     "empty",
+			html:     "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertFallback(tt.html)
+			if err != nil {
+				t.Fatalf("ConvertFallback failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ConvertFallback() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
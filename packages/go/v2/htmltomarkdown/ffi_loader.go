@@ -1,3 +1,5 @@
+//go:build cgo
+
 package htmltomarkdown
 
 // #cgo linux LDFLAGS: -ldl
@@ -5,6 +7,14 @@ package htmltomarkdown
 // #include <stdlib.h>
 // #include <stdbool.h>
 // #include <stdint.h>
+// #include <stdio.h>
+//
+// static char ffi_load_error[512] = {0};
+//
+// const char* html_to_markdown_ffi_load_error(void) {
+// 	return ffi_load_error;
+// }
+//
 // #if defined(_WIN32)
 // #include <windows.h>
 // static HMODULE ffi_handle = NULL;
@@ -13,15 +23,21 @@ package htmltomarkdown
 // static FARPROC html_to_markdown_version_ptr = NULL;
 // static FARPROC html_to_markdown_last_error_ptr = NULL;
 // static FARPROC html_to_markdown_convert_with_metadata_ptr = NULL;
+// static FARPROC html_to_markdown_extract_metadata_ptr = NULL;
 // static FARPROC html_to_markdown_profile_start_ptr = NULL;
 // static FARPROC html_to_markdown_profile_stop_ptr = NULL;
 // static FARPROC html_to_markdown_convert_with_visitor_ptr = NULL;
 // static FARPROC html_to_markdown_visitor_create_ptr = NULL;
 // static FARPROC html_to_markdown_visitor_free_ptr = NULL;
+// static FARPROC html_to_markdown_abi_version_ptr = NULL;
+// static FARPROC html_to_markdown_reset_peak_bytes_ptr = NULL;
+// static FARPROC html_to_markdown_peak_bytes_ptr = NULL;
 //
 // bool html_to_markdown_ffi_load(const char* path) {
+// 	ffi_load_error[0] = '\0';
 // 	ffi_handle = LoadLibraryA(path);
 // 	if (!ffi_handle) {
+// 		snprintf(ffi_load_error, sizeof(ffi_load_error), "LoadLibrary failed with error code %lu", GetLastError());
 // 		return false;
 // 	}
 // 	html_to_markdown_convert_ptr = GetProcAddress(ffi_handle, "html_to_markdown_convert");
@@ -29,16 +45,21 @@ package htmltomarkdown
 // 	html_to_markdown_version_ptr = GetProcAddress(ffi_handle, "html_to_markdown_version");
 // 	html_to_markdown_last_error_ptr = GetProcAddress(ffi_handle, "html_to_markdown_last_error");
 // 	html_to_markdown_convert_with_metadata_ptr = GetProcAddress(ffi_handle, "html_to_markdown_convert_with_metadata");
+// 	html_to_markdown_extract_metadata_ptr = GetProcAddress(ffi_handle, "html_to_markdown_extract_metadata");
 // 	html_to_markdown_profile_start_ptr = GetProcAddress(ffi_handle, "html_to_markdown_profile_start");
 // 	html_to_markdown_profile_stop_ptr = GetProcAddress(ffi_handle, "html_to_markdown_profile_stop");
 // 	html_to_markdown_convert_with_visitor_ptr = GetProcAddress(ffi_handle, "html_to_markdown_convert_with_visitor");
 // 	html_to_markdown_visitor_create_ptr = GetProcAddress(ffi_handle, "html_to_markdown_visitor_create");
 // 	html_to_markdown_visitor_free_ptr = GetProcAddress(ffi_handle, "html_to_markdown_visitor_free");
+// 	html_to_markdown_abi_version_ptr = GetProcAddress(ffi_handle, "html_to_markdown_abi_version");
+// 	html_to_markdown_reset_peak_bytes_ptr = GetProcAddress(ffi_handle, "html_to_markdown_reset_peak_bytes");
+// 	html_to_markdown_peak_bytes_ptr = GetProcAddress(ffi_handle, "html_to_markdown_peak_bytes");
 // 	if (!html_to_markdown_convert_ptr || !html_to_markdown_free_string_ptr ||
 // 		!html_to_markdown_version_ptr || !html_to_markdown_last_error_ptr ||
 // 		!html_to_markdown_convert_with_metadata_ptr || !html_to_markdown_profile_start_ptr ||
 // 		!html_to_markdown_profile_stop_ptr || !html_to_markdown_convert_with_visitor_ptr ||
 // 		!html_to_markdown_visitor_create_ptr || !html_to_markdown_visitor_free_ptr) {
+// 		snprintf(ffi_load_error, sizeof(ffi_load_error), "library is missing required html-to-markdown symbols");
 // 		FreeLibrary(ffi_handle);
 // 		ffi_handle = NULL;
 // 		return false;
@@ -53,15 +74,22 @@ package htmltomarkdown
 // static void* html_to_markdown_version_ptr = NULL;
 // static void* html_to_markdown_last_error_ptr = NULL;
 // static void* html_to_markdown_convert_with_metadata_ptr = NULL;
+// static void* html_to_markdown_extract_metadata_ptr = NULL;
 // static void* html_to_markdown_profile_start_ptr = NULL;
 // static void* html_to_markdown_profile_stop_ptr = NULL;
 // static void* html_to_markdown_convert_with_visitor_ptr = NULL;
 // static void* html_to_markdown_visitor_create_ptr = NULL;
 // static void* html_to_markdown_visitor_free_ptr = NULL;
+// static void* html_to_markdown_abi_version_ptr = NULL;
+// static void* html_to_markdown_reset_peak_bytes_ptr = NULL;
+// static void* html_to_markdown_peak_bytes_ptr = NULL;
 //
 // bool html_to_markdown_ffi_load(const char* path) {
+// 	ffi_load_error[0] = '\0';
 // 	ffi_handle = dlopen(path, RTLD_LAZY);
 // 	if (!ffi_handle) {
+// 		const char* reason = dlerror();
+// 		snprintf(ffi_load_error, sizeof(ffi_load_error), "%s", reason ? reason : "dlopen failed");
 // 		return false;
 // 	}
 // 	html_to_markdown_convert_ptr = dlsym(ffi_handle, "html_to_markdown_convert");
@@ -69,16 +97,21 @@ package htmltomarkdown
 // 	html_to_markdown_version_ptr = dlsym(ffi_handle, "html_to_markdown_version");
 // 	html_to_markdown_last_error_ptr = dlsym(ffi_handle, "html_to_markdown_last_error");
 // 	html_to_markdown_convert_with_metadata_ptr = dlsym(ffi_handle, "html_to_markdown_convert_with_metadata");
+// 	html_to_markdown_extract_metadata_ptr = dlsym(ffi_handle, "html_to_markdown_extract_metadata");
 // 	html_to_markdown_profile_start_ptr = dlsym(ffi_handle, "html_to_markdown_profile_start");
 // 	html_to_markdown_profile_stop_ptr = dlsym(ffi_handle, "html_to_markdown_profile_stop");
 // 	html_to_markdown_convert_with_visitor_ptr = dlsym(ffi_handle, "html_to_markdown_convert_with_visitor");
 // 	html_to_markdown_visitor_create_ptr = dlsym(ffi_handle, "html_to_markdown_visitor_create");
 // 	html_to_markdown_visitor_free_ptr = dlsym(ffi_handle, "html_to_markdown_visitor_free");
+// 	html_to_markdown_abi_version_ptr = dlsym(ffi_handle, "html_to_markdown_abi_version");
+// 	html_to_markdown_reset_peak_bytes_ptr = dlsym(ffi_handle, "html_to_markdown_reset_peak_bytes");
+// 	html_to_markdown_peak_bytes_ptr = dlsym(ffi_handle, "html_to_markdown_peak_bytes");
 // 	if (!html_to_markdown_convert_ptr || !html_to_markdown_free_string_ptr ||
 // 		!html_to_markdown_version_ptr || !html_to_markdown_last_error_ptr ||
 // 		!html_to_markdown_convert_with_metadata_ptr || !html_to_markdown_profile_start_ptr ||
 // 		!html_to_markdown_profile_stop_ptr || !html_to_markdown_convert_with_visitor_ptr ||
 // 		!html_to_markdown_visitor_create_ptr || !html_to_markdown_visitor_free_ptr) {
+// 		snprintf(ffi_load_error, sizeof(ffi_load_error), "library is missing required html-to-markdown symbols");
 // 		dlclose(ffi_handle);
 // 		ffi_handle = NULL;
 // 		return false;
@@ -94,11 +127,15 @@ package htmltomarkdown
 // typedef const char* (*version_fn)(void);
 // typedef const char* (*last_error_fn)(void);
 // typedef char* (*convert_with_metadata_fn)(const char*, char**);
+// typedef char* (*extract_metadata_fn)(const char*);
 // typedef bool (*profile_start_fn)(const char*, int32_t);
 // typedef bool (*profile_stop_fn)(void);
 // typedef char* (*convert_with_visitor_fn)(const char*, void*);
 // typedef void* (*visitor_create_fn)(const void*);
 // typedef void (*visitor_free_fn)(void*);
+// typedef int32_t (*abi_version_fn)(void);
+// typedef void (*reset_peak_bytes_fn)(void);
+// typedef uint64_t (*peak_bytes_fn)(void);
 //
 // char* html_to_markdown_convert_proxy(const char* html) {
 // 	if (!html_to_markdown_convert_ptr) {
@@ -121,6 +158,28 @@ package htmltomarkdown
 // 	return ((version_fn)html_to_markdown_version_ptr)();
 // }
 //
+// int32_t html_to_markdown_abi_version_proxy(void) {
+// 	if (!html_to_markdown_abi_version_ptr) {
+// 		return -1;
+// 	}
+// 	return ((abi_version_fn)html_to_markdown_abi_version_ptr)();
+// }
+//
+// bool html_to_markdown_reset_peak_bytes_proxy(void) {
+// 	if (!html_to_markdown_reset_peak_bytes_ptr || !html_to_markdown_peak_bytes_ptr) {
+// 		return false;
+// 	}
+// 	((reset_peak_bytes_fn)html_to_markdown_reset_peak_bytes_ptr)();
+// 	return true;
+// }
+//
+// uint64_t html_to_markdown_peak_bytes_proxy(void) {
+// 	if (!html_to_markdown_peak_bytes_ptr) {
+// 		return 0;
+// 	}
+// 	return ((peak_bytes_fn)html_to_markdown_peak_bytes_ptr)();
+// }
+//
 // const char* html_to_markdown_last_error_proxy(void) {
 // 	if (!html_to_markdown_last_error_ptr) {
 // 		return html_to_markdown_ffi_error;
@@ -135,6 +194,17 @@ package htmltomarkdown
 // 	return ((convert_with_metadata_fn)html_to_markdown_convert_with_metadata_ptr)(html, metadata_json);
 // }
 //
+// bool html_to_markdown_has_extract_metadata_proxy(void) {
+// 	return html_to_markdown_extract_metadata_ptr != NULL;
+// }
+//
+// char* html_to_markdown_extract_metadata_proxy(const char* html) {
+// 	if (!html_to_markdown_extract_metadata_ptr) {
+// 		return NULL;
+// 	}
+// 	return ((extract_metadata_fn)html_to_markdown_extract_metadata_ptr)(html);
+// }
+//
 // bool html_to_markdown_profile_start_proxy(const char* output, int32_t frequency) {
 // 	if (!html_to_markdown_profile_start_ptr) {
 // 		return false;
@@ -192,6 +262,10 @@ const (
 	defaultFFIVersion = "2.19.1"
 	githubRepo        = "kreuzberg-dev/html-to-markdown"
 
+	// minFFIABIVersion and maxFFIABIVersion bound the native ABI revisions this binding supports.
+	minFFIABIVersion = 2
+	maxFFIABIVersion = 2
+
 	archAMD64    = "amd64"
 	archARM64    = "arm64"
 	archiveTarGz = "tar.gz"
@@ -202,19 +276,126 @@ var (
 	ffiLoadErr  error
 )
 
+// LibraryLoadError is returned when the html-to-markdown FFI library cannot be loaded.
+//
+// It records the library filename expected for the current platform, the path that
+// was attempted (if any), and remediation hints. Use errors.As to inspect it:
+//
+//	var loadErr *htmltomarkdown.LibraryLoadError
+//	if errors.As(err, &loadErr) {
+//	    log.Printf("missing %s: %v", loadErr.LibraryName, loadErr.Err)
+//	}
+type LibraryLoadError struct {
+	// LibraryName is the library filename expected for the current OS/arch.
+	LibraryName string
+
+	// Path is the library path that was attempted, if one was resolved.
+	Path string
+
+	// Platform is the current GOOS/GOARCH pair.
+	Platform string
+
+	// Hints lists remediation steps for resolving the failure.
+	Hints []string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+func (e *LibraryLoadError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "html-to-markdown FFI library %s could not be loaded for %s", e.LibraryName, e.Platform)
+	if e.Path != "" {
+		fmt.Fprintf(&b, " from %s", e.Path)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	if len(e.Hints) > 0 {
+		b.WriteString(". Troubleshooting:")
+		for i, hint := range e.Hints {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, hint)
+		}
+	}
+	return b.String()
+}
+
+func (e *LibraryLoadError) Unwrap() error {
+	return e.Err
+}
+
+// ABIMismatchError reports that the native library implements an FFI ABI revision
+// outside the range supported by this Go binding.
+//
+// It is returned wrapped in a *LibraryLoadError on first load.
+type ABIMismatchError struct {
+	// LibraryVersion is the version string reported by the native library.
+	LibraryVersion string
+
+	// LibraryABI is the ABI revision reported by the native library.
+	LibraryABI int
+
+	// MinABI and MaxABI bound the ABI revisions supported by this binding.
+	MinABI int
+
+	MaxABI int
+}
+
+func (e *ABIMismatchError) Error() string {
+	relation := "newer than"
+	bound := e.MaxABI
+	if e.LibraryABI < e.MinABI {
+		relation = "older than"
+		bound = e.MinABI
+	}
+	return fmt.Sprintf("native library %s implements ABI %d, which is %s ABI %d supported by this Go binding (supported range %d-%d)",
+		e.LibraryVersion, e.LibraryABI, relation, bound, e.MinABI, e.MaxABI)
+}
+
 func ensureFFILoaded() error {
 	ffiLoadOnce.Do(func() {
 		ffiLoadErr = loadFFI()
+		if ffiLoadErr == nil {
+			ffiLoadErr = checkFFIABI(readLibraryVersion)
+		}
 	})
 	return ffiLoadErr
 }
 
+// checkFFIABI performs the ABI handshake with a freshly loaded native library and
+// refuses libraries whose ABI revision is outside [minFFIABIVersion, maxFFIABIVersion].
+func checkFFIABI(readVersion func() (LibraryVersion, error)) error {
+	info, err := readVersion()
+	if err != nil {
+		return newLibraryLoadError("", fmt.Errorf("ABI handshake failed: %w", err),
+			"Check that the library is an html-to-markdown FFI build")
+	}
+	if info.ABIVersion >= minFFIABIVersion && info.ABIVersion <= maxFFIABIVersion {
+		return nil
+	}
+	return newLibraryLoadError("", &ABIMismatchError{
+		LibraryVersion: info.Semver,
+		LibraryABI:     info.ABIVersion,
+		MinABI:         minFFIABIVersion,
+		MaxABI:         maxFFIABIVersion,
+	},
+		"Install the FFI library matching this binding (HTML_TO_MARKDOWN_FFI_VERSION="+defaultFFIVersion+")",
+		"Upgrade or downgrade the Go module to a release built for html-to-markdown "+info.Semver)
+}
+
 func loadFFI() error {
 	if path := os.Getenv("HTML_TO_MARKDOWN_FFI_PATH"); path != "" {
-		return loadFFIFromPath(path)
+		if err := loadFFIFromPath(path); err != nil {
+			return newLibraryLoadError(path, err,
+				"Check that HTML_TO_MARKDOWN_FFI_PATH points to an existing "+expectedLibraryName()+" built for "+currentPlatform(),
+				"Unset HTML_TO_MARKDOWN_FFI_PATH to download a prebuilt library automatically")
+		}
+		return nil
 	}
 	if os.Getenv("HTML_TO_MARKDOWN_FFI_DISABLE_DOWNLOAD") != "" {
-		return errors.New("html-to-markdown FFI download disabled and no library path provided")
+		return newLibraryLoadError("", errors.New("download disabled and no library path provided"),
+			"Set HTML_TO_MARKDOWN_FFI_PATH to the absolute path of "+expectedLibraryName(),
+			"Unset HTML_TO_MARKDOWN_FFI_DISABLE_DOWNLOAD to download a prebuilt library automatically")
 	}
 	version := os.Getenv("HTML_TO_MARKDOWN_FFI_VERSION")
 	if version == "" {
@@ -222,37 +403,45 @@ func loadFFI() error {
 	}
 	platform, archiveExt, libName, err := resolveFFIPlatform()
 	if err != nil {
-		return err
+		return newLibraryLoadError("", err,
+			"Build html-to-markdown-ffi from source and set HTML_TO_MARKDOWN_FFI_PATH to the resulting library")
 	}
 	cacheDir, err := resolveCacheDir(version, platform)
 	if err != nil {
-		return err
+		return newLibraryLoadError("", err,
+			"Set HTML_TO_MARKDOWN_FFI_CACHE_DIR to a writable directory")
 	}
 	libPath := filepath.Join(cacheDir, libName)
 	if _, err := os.Stat(libPath); err == nil {
-		return loadFFIFromPath(libPath)
+		if err := loadFFIFromPath(libPath); err != nil {
+			return newLibraryLoadError(libPath, err,
+				"Remove the cached library at "+libPath+" to force a fresh download",
+				"For development, set HTML_TO_MARKDOWN_FFI_PATH to a local library path")
+		}
+		return nil
 	}
 	archiveName := fmt.Sprintf("html-to-markdown-ffi-%s-%s.%s", version, platform, archiveExt)
 	downloadURL := fmt.Sprintf("https://github.com/%s/releases/download/v%s/%s", githubRepo, version, archiveName)
 	archivePath := filepath.Join(cacheDir, archiveName)
 
 	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
-		return fmt.Errorf("create FFI cache dir: %w", err)
+		return newLibraryLoadError(libPath, fmt.Errorf("create FFI cache dir: %w", err),
+			"Set HTML_TO_MARKDOWN_FFI_CACHE_DIR to a writable directory")
 	}
 	if err := downloadFile(downloadURL, archivePath); err != nil {
-		// Provide helpful diagnostic information
-		return fmt.Errorf("failed to load html-to-markdown FFI library for %s/%s (version %s): %w. "+
-			"Troubleshooting:\n"+
-			"1. Check if version %s is published on GitHub: https://github.com/%s/releases/tag/v%s\n"+
-			"2. For development, set HTML_TO_MARKDOWN_FFI_PATH to a local library path\n"+
-			"3. Override the version with HTML_TO_MARKDOWN_FFI_VERSION environment variable",
-			runtime.GOOS, runtime.GOARCH, version, err, version, githubRepo, version)
+		return newLibraryLoadError(libPath, fmt.Errorf("version %s: %w", version, err),
+			fmt.Sprintf("Check if version %s is published on GitHub: https://github.com/%s/releases/tag/v%s", version, githubRepo, version),
+			"For development, set HTML_TO_MARKDOWN_FFI_PATH to a local library path",
+			"Override the version with HTML_TO_MARKDOWN_FFI_VERSION environment variable")
 	}
 	if err := extractArchive(archivePath, cacheDir); err != nil {
-		return fmt.Errorf("extract FFI archive: %w", err)
+		return newLibraryLoadError(libPath, fmt.Errorf("extract FFI archive: %w", err),
+			"Remove "+archivePath+" to force a fresh download")
 	}
 	if err := loadFFIFromPath(libPath); err != nil {
-		return fmt.Errorf("load FFI from extracted path %s: %w", libPath, err)
+		return newLibraryLoadError(libPath, err,
+			"Check that the archive "+archiveName+" contains "+libName,
+			"For development, set HTML_TO_MARKDOWN_FFI_PATH to a local library path")
 	}
 	return nil
 }
@@ -261,11 +450,44 @@ func loadFFIFromPath(path string) error {
 	cPath := C.CString(path)
 	defer C.free(unsafe.Pointer(cPath))
 	if ok := C.html_to_markdown_ffi_load(cPath); !bool(ok) {
-		return fmt.Errorf("failed to load html-to-markdown FFI from %s", path)
+		if reason := C.GoString(C.html_to_markdown_ffi_load_error()); reason != "" {
+			return errors.New(reason)
+		}
+		return errors.New("dynamic loader rejected the library")
 	}
 	return nil
 }
 
+func newLibraryLoadError(path string, err error, hints ...string) *LibraryLoadError {
+	return &LibraryLoadError{
+		LibraryName: expectedLibraryName(),
+		Path:        path,
+		Platform:    currentPlatform(),
+		Hints:       hints,
+		Err:         err,
+	}
+}
+
+// expectedLibraryName returns the FFI library filename for the current platform,
+// falling back to the OS naming convention on unsupported architectures.
+func expectedLibraryName() string {
+	if _, _, libName, err := resolveFFIPlatform(); err == nil {
+		return libName
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return "html_to_markdown_ffi.dll"
+	case "darwin":
+		return "libhtml_to_markdown_ffi.dylib"
+	default:
+		return "libhtml_to_markdown_ffi.so"
+	}
+}
+
+func currentPlatform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
 func resolveFFIPlatform() (platform string, archiveExt string, libraryName string, err error) {
 	switch runtime.GOOS {
 	case "linux":
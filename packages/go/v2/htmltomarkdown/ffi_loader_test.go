@@ -0,0 +1,172 @@
+//go:build cgo
+
+package htmltomarkdown
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFFI_MissingLibrary(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing", expectedLibraryName())
+	t.Setenv("HTML_TO_MARKDOWN_FFI_PATH", missing)
+
+	err := loadFFI()
+	if err == nil {
+		t.Fatal("loadFFI() should fail for a missing library")
+	}
+
+	var loadErr *LibraryLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("loadFFI() error type = %T, expected *LibraryLoadError", err)
+	}
+	if loadErr.Path != missing {
+		t.Errorf("LibraryLoadError.Path = %s, expected %s", loadErr.Path, missing)
+	}
+	if loadErr.LibraryName == "" {
+		t.Error("LibraryLoadError.LibraryName should not be empty")
+	}
+	if loadErr.Err == nil {
+		t.Error("LibraryLoadError.Err should carry the loader failure")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{loadErr.LibraryName, currentPlatform(), missing, "Troubleshooting", "HTML_TO_MARKDOWN_FFI_PATH"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error message %q should contain %q", msg, want)
+		}
+	}
+}
+
+func TestLoadFFI_DownloadDisabled(t *testing.T) {
+	t.Setenv("HTML_TO_MARKDOWN_FFI_PATH", "")
+	t.Setenv("HTML_TO_MARKDOWN_FFI_DISABLE_DOWNLOAD", "1")
+
+	err := loadFFI()
+	var loadErr *LibraryLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("loadFFI() error type = %T, expected *LibraryLoadError", err)
+	}
+	if len(loadErr.Hints) == 0 {
+		t.Error("LibraryLoadError.Hints should not be empty")
+	}
+	if !strings.Contains(err.Error(), "HTML_TO_MARKDOWN_FFI_DISABLE_DOWNLOAD") {
+		t.Errorf("error message %q should mention HTML_TO_MARKDOWN_FFI_DISABLE_DOWNLOAD", err.Error())
+	}
+}
+
+func TestCheckFFIABI(t *testing.T) {
+	tests := []struct {
+		name    string
+		version LibraryVersion
+		refused bool
+	}{
+		{
+			name:    "supported",
+			version: LibraryVersion{Semver: "2.19.1", Major: 2, Minor: 19, Patch: 1, ABIVersion: minFFIABIVersion},
+		},
+		{
+			name:    "too old",
+			version: LibraryVersion{Semver: "1.6.0", Major: 1, Minor: 6, ABIVersion: minFFIABIVersion - 1},
+			refused: true,
+		},
+		{
+			name:    "too new",
+			version: LibraryVersion{Semver: "9.0.0", Major: 9, ABIVersion: maxFFIABIVersion + 1},
+			refused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFFIABI(func() (LibraryVersion, error) {
+				return tt.version, nil
+			})
+			if !tt.refused {
+				if err != nil {
+					t.Errorf("checkFFIABI() = %v, expected nil", err)
+				}
+				return
+			}
+
+			var loadErr *LibraryLoadError
+			if !errors.As(err, &loadErr) {
+				t.Fatalf("checkFFIABI() error type = %T, expected *LibraryLoadError", err)
+			}
+			var abiErr *ABIMismatchError
+			if !errors.As(err, &abiErr) {
+				t.Fatalf("checkFFIABI() error should wrap *ABIMismatchError, got %v", err)
+			}
+			if abiErr.LibraryABI != tt.version.ABIVersion {
+				t.Errorf("ABIMismatchError.LibraryABI = %d, expected %d", abiErr.LibraryABI, tt.version.ABIVersion)
+			}
+			if !strings.Contains(err.Error(), tt.version.Semver) {
+				t.Errorf("error message %q should mention library version %s", err.Error(), tt.version.Semver)
+			}
+		})
+	}
+}
+
+func TestCheckFFIABI_VersionUnavailable(t *testing.T) {
+	err := checkFFIABI(func() (LibraryVersion, error) {
+		return LibraryVersion{}, errors.New("no version")
+	})
+	var loadErr *LibraryLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("checkFFIABI() error type = %T, expected *LibraryLoadError", err)
+	}
+}
+
+func TestConvert_UsesFallbackWhenLoadFails(t *testing.T) {
+	_ = ensureFFILoaded()
+	savedErr := ffiLoadErr
+	ffiLoadErr = newLibraryLoadError("", errors.New("forced load failure"))
+	t.Cleanup(func() {
+		ffiLoadErr = savedErr
+		SetFallback(nil)
+	})
+
+	if _, err := Convert("<p>Text</p>"); err == nil {
+		t.Fatal("Convert() should fail without a fallback when the library cannot be loaded")
+	}
+
+	var got string
+	SetFallback(func(html string) (string, error) {
+		got = html
+		return "stub output", nil
+	})
+	result, err := Convert("<p>Text</p>")
+	if err != nil {
+		t.Fatalf("Convert() with fallback failed: %v", err)
+	}
+	if result != "stub output" || got != "<p>Text</p>" {
+		t.Errorf("Convert() = %q with fallback input %q, expected the stub to convert the original HTML", result, got)
+	}
+
+	SetFallback(ConvertFallback)
+	result, err = Convert("<h1>Title</h1>")
+	if err != nil {
+		t.Fatalf("Convert() with ConvertFallback failed: %v", err)
+	}
+	if result != "# Title\n" {
+		t.Errorf("Convert() with ConvertFallback = %q, expected %q", result, "# Title\n")
+	}
+}
+
+func TestVersionInfo_ReportsLoadFailure(t *testing.T) {
+	_ = ensureFFILoaded()
+	savedErr := ffiLoadErr
+	ffiLoadErr = newLibraryLoadError("", errors.New("forced load failure"))
+	t.Cleanup(func() { ffiLoadErr = savedErr })
+
+	_, err := VersionInfo()
+	var loadErr *LibraryLoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("VersionInfo() error type = %T, expected *LibraryLoadError", err)
+	}
+	if !strings.Contains(err.Error(), "forced load failure") {
+		t.Errorf("VersionInfo() error %q should carry the load failure", err.Error())
+	}
+}
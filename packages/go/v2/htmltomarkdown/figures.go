@@ -0,0 +1,45 @@
+package htmltomarkdown
+
+import "strings"
+
+// renderFigures replaces every <figure> with a block fragment in the
+// requested style. Caption-below figures render their content first and the
+// <figcaption> as an italic line underneath, wherever it appears in the source.
+func renderFigures(root *htmlNode, style FigureStyle, fragments *fragmentSet) error {
+	figures := root.findAll("figure")
+	for i := len(figures) - 1; i >= 0; i-- {
+		figure := figures[i]
+		if style == FigureStyleHTML {
+			figure.replaceWith(fragments.node(fragments.restore(figure.render()), false))
+			continue
+		}
+
+		var content, caption strings.Builder
+		for _, c := range figure.children {
+			if c.typ == htmlElementNode && c.tag == "figcaption" {
+				caption.WriteString(c.renderChildren())
+				caption.WriteByte(' ')
+				continue
+			}
+			c.writeHTML(&content)
+		}
+
+		markdown, err := convertFFI(content.String())
+		if err != nil {
+			return err
+		}
+		markdown = strings.Trim(fragments.restore(markdown), "\n")
+		if caption.Len() > 0 {
+			text, err := convertFFI(caption.String())
+			if err != nil {
+				return err
+			}
+			text = strings.Join(strings.Fields(fragments.restore(text)), " ")
+			if text != "" {
+				markdown = strings.TrimLeft(markdown+"\n\n*"+text+"*", "\n")
+			}
+		}
+		figure.replaceWith(fragments.node(markdown, false))
+	}
+	return nil
+}
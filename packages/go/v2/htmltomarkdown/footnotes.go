@@ -0,0 +1,147 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// footnote is a footnote definition referenced from the document.
+type footnote struct {
+	label  string
+	target *htmlNode
+}
+
+// isFootnoteReference reports whether a looks like a footnote reference: an
+// in-page link inside <sup>, wrapping a <sup>, or marked as a note reference.
+func isFootnoteReference(a *htmlNode) bool {
+	if !strings.HasPrefix(a.attrOr("href", ""), "#") {
+		return false
+	}
+	if a.parentTag() == "sup" || a.attrOr("role", "") == "doc-noteref" || a.attrOr("rel", "") == "footnote" {
+		return true
+	}
+	for _, c := range a.children {
+		if c.typ == htmlElementNode && c.tag == "sup" {
+			return true
+		}
+	}
+	return strings.Contains(a.attrOr("class", ""), "footnote")
+}
+
+// convertFootnotes turns footnote references into GFM [^n] references and
+// moves the definitions they point to, matched by fragment id, to the end of
+// the document as [^n]: definitions.
+func convertFootnotes(root *htmlNode, fragments *fragmentSet) error {
+	ids := map[string]*htmlNode{}
+	root.walk(func(n *htmlNode) bool {
+		if id := n.attrOr("id", ""); n.typ == htmlElementNode && id != "" {
+			if _, seen := ids[id]; !seen {
+				ids[id] = n
+			}
+		}
+		return true
+	})
+
+	var notes []*footnote
+	byTarget := map[*htmlNode]*footnote{}
+	backrefs := map[string]bool{}
+	for _, a := range root.findAll("a") {
+		if !isFootnoteReference(a) {
+			continue
+		}
+		target := ids[strings.TrimPrefix(a.attrOr("href", ""), "#")]
+		if target == nil || target == a || containsNode(target, a) {
+			continue
+		}
+		note := byTarget[target]
+		if note == nil {
+			note = &footnote{label: strconv.Itoa(len(notes) + 1), target: target}
+			byTarget[target] = note
+			notes = append(notes, note)
+		}
+
+		ref := a
+		if sup := a.parent; sup != nil && sup.tag == "sup" && onlyChild(sup, a) {
+			ref = sup
+		}
+		for _, n := range []*htmlNode{a, ref} {
+			if id := n.attrOr("id", ""); id != "" {
+				backrefs["#"+id] = true
+			}
+		}
+		ref.replaceWith(fragments.node("[^"+note.label+"]", true))
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	for _, note := range notes {
+		removeBackLinks(note.target, backrefs)
+		markdown, err := convertFFI(note.target.renderChildren())
+		if err != nil {
+			return err
+		}
+		markdown = strings.TrimSpace(fragments.restore(markdown))
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("[^" + note.label + "]: " + indentContinuation(markdown, "    ") + "\n")
+		removeEmptyContainers(note.target)
+	}
+
+	container := root
+	if bodies := root.findAll("body"); len(bodies) > 0 {
+		container = bodies[0]
+	}
+	container.appendChild(fragments.node(b.String(), false))
+	return nil
+}
+
+// removeBackLinks drops the links of a definition that lead back to its
+// references.
+func removeBackLinks(definition *htmlNode, backrefs map[string]bool) {
+	for _, a := range definition.findAll("a") {
+		text := strings.TrimSpace(a.text())
+		if backrefs[a.attrOr("href", "")] || text == "↩" || text == "↩︎" || text == "^" {
+			a.remove()
+		}
+	}
+}
+
+// removeEmptyContainers detaches n, then every ancestor left without visible
+// content, such as the footnotes list and its section.
+func removeEmptyContainers(n *htmlNode) {
+	for {
+		parent := n.parent
+		n.remove()
+		if parent == nil || parent.typ != htmlElementNode || parent.tag == "body" || parent.tag == "html" {
+			return
+		}
+		for _, c := range parent.children {
+			if hasVisibleContent(c) {
+				return
+			}
+		}
+		n = parent
+	}
+}
+
+func containsNode(ancestor, n *htmlNode) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyChild reports whether child is the only non-blank child of n.
+func onlyChild(n, child *htmlNode) bool {
+	for _, c := range n.children {
+		if c != child && (c.typ != htmlTextNode || strings.TrimSpace(c.data) != "") {
+			return false
+		}
+	}
+	return true
+}
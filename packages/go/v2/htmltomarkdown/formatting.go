@@ -0,0 +1,65 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// formattingFamilies groups the inline formatting tags that render with the
+// same Markdown delimiters.
+var formattingFamilies = map[string]string{
+	"strong": "strong", "b": "strong",
+	"em": "em", "i": "em",
+	"del": "del", "s": "del", "strike": "del",
+}
+
+// mergeAdjacentFormatting merges sibling formatting elements of the same
+// family, such as <strong>a</strong><strong>b</strong>, which would render
+// as **a****b**. Whitespace between the runs moves inside the merged element.
+func mergeAdjacentFormatting(root *htmlNode) {
+	root.walk(func(n *htmlNode) bool {
+		if n.typ == htmlElementNode && (containsString(literalTextElements, n.tag) || rawTextElements[n.tag]) {
+			return false
+		}
+		children := make([]*htmlNode, 0, len(n.children))
+		for i := 0; i < len(n.children); i++ {
+			c := n.children[i]
+			children = append(children, c)
+			family := formattingFamily(c)
+			if family == "" {
+				continue
+			}
+			for {
+				next := i + 1
+				if next < len(n.children) && isWhitespaceText(n.children[next]) {
+					next++
+				}
+				if next >= len(n.children) || formattingFamily(n.children[next]) != family {
+					break
+				}
+				for _, moved := range n.children[i+1 : next] {
+					c.appendChild(moved)
+				}
+				for _, moved := range n.children[next].children {
+					c.appendChild(moved)
+				}
+				i = next
+			}
+		}
+		n.children = children
+		return n.typ != htmlTextNode
+	})
+}
+
+// formattingFamily returns the formatting family of n, or "" when n is not
+// a formatting element.
+func formattingFamily(n *htmlNode) string {
+	if n.typ != htmlElementNode {
+		return ""
+	}
+	return formattingFamilies[n.tag]
+}
+
+func isWhitespaceText(n *htmlNode) bool {
+	return n.typ == htmlTextNode && strings.TrimSpace(html.UnescapeString(n.data)) == ""
+}
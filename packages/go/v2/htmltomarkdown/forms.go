@@ -0,0 +1,206 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"slices"
+	"strings"
+)
+
+// formFieldTags are the elements holding the fields of a form.
+var formFieldTags = []string{"input", "select", "textarea", "button"}
+
+// renderForms rewrites every <form> in the requested style.
+// FormStyleList replaces the form with a list of its fields, so headings
+// and paragraphs inside the form are dropped with it.
+func renderForms(root *htmlNode, style FormStyle, fragments *fragmentSet) {
+	labels := labelsByFor(root)
+	forms := root.findAll("form")
+	for i := len(forms) - 1; i >= 0; i-- {
+		form := forms[i]
+		if form.hasAncestor("form") {
+			continue
+		}
+		switch style {
+		case FormStyleDrop:
+			form.remove()
+		case FormStyleHTML:
+			form.replaceWith(fragments.node(fragments.restore(form.render()), false))
+		case FormStyleList:
+			if list := formFieldList(form, labels); len(list.children) > 0 {
+				form.replaceWith(list)
+			} else {
+				form.remove()
+			}
+		}
+	}
+}
+
+// formFieldList returns a <ul> describing the fields of form, one item per
+// field: "Label (type): value", with checkboxes and radio buttons rendered
+// as task list items.
+func formFieldList(form *htmlNode, labels map[string]*htmlNode) *htmlNode {
+	list := newElementNode("ul")
+	for _, field := range form.findAll(formFieldTags...) {
+		if field.hasAncestor("button", "select") {
+			continue
+		}
+		kind, value := field.tag, ""
+		label := strings.TrimRight(fieldLabel(field, labels), ": ")
+		switch field.tag {
+		case "input":
+			kind = strings.ToLower(field.attrOr("type", "text"))
+			value = field.attrOr("value", "")
+			switch kind {
+			case "hidden":
+				continue
+			case "checkbox", "radio":
+				li := newElementNode("li")
+				checkbox := newElementNode("input", htmlAttr{Key: "type", Val: "checkbox"})
+				if _, checked := field.attr("checked"); checked {
+					checkbox.attrs = append(checkbox.attrs, htmlAttr{Key: "checked"})
+				}
+				if label == "" {
+					label = value
+				}
+				li.appendChild(checkbox)
+				li.appendChild(&htmlNode{typ: htmlTextNode, data: " " + textEscaper.Replace(label)})
+				list.appendChild(li)
+				continue
+			case "submit", "reset", "button", "image":
+				if label == "" {
+					label = value
+				}
+				value = ""
+			}
+		case "button":
+			if label == "" {
+				label = field.normalizedText()
+			}
+		case "select":
+			value = selectedOption(field)
+		case "textarea":
+			value = strings.Join(strings.Fields(field.text()), " ")
+		}
+		if label == "" {
+			label = field.attrOr("name", "")
+		}
+		text := label + " (" + kind + ")"
+		if label == "" {
+			text = "(" + kind + ")"
+		}
+		if value != "" {
+			text += ": " + value
+		}
+		li := newElementNode("li")
+		li.appendChild(&htmlNode{typ: htmlTextNode, data: textEscaper.Replace(text)})
+		list.appendChild(li)
+	}
+	return list
+}
+
+// selectedOption returns the text of the selected options of a <select>.
+func selectedOption(sel *htmlNode) string {
+	options, selected := selectOptions(sel)
+	texts := make([]string, len(selected))
+	for i, index := range selected {
+		texts[i] = options[index]
+	}
+	return strings.Join(texts, ", ")
+}
+
+// selectOptions returns the option texts of a <select> and the indexes of
+// the selected ones. As in browsers, a single-choice select without a
+// selected option selects its first option.
+func selectOptions(sel *htmlNode) (options []string, selected []int) {
+	for i, option := range sel.findAll("option") {
+		options = append(options, option.normalizedText())
+		if _, ok := option.attr("selected"); ok {
+			selected = append(selected, i)
+		}
+	}
+	if _, multiple := sel.attr("multiple"); len(selected) == 0 && len(options) > 0 && !multiple {
+		selected = []int{0}
+	}
+	return options, selected
+}
+
+// renderSelects rewrites every <select> in the requested style.
+func renderSelects(root *htmlNode, style SelectStyle) {
+	for _, sel := range root.findAll("select") {
+		options, selected := selectOptions(sel)
+		switch style {
+		case SelectStyleDrop:
+			sel.remove()
+		case SelectStyleSelected:
+			sel.replaceWith(&htmlNode{typ: htmlTextNode, data: textEscaper.Replace(selectedOption(sel))})
+		case SelectStyleList:
+			list := newElementNode("ul")
+			for i, option := range options {
+				checkbox := newElementNode("input", htmlAttr{Key: "type", Val: "checkbox"})
+				if slices.Contains(selected, i) {
+					checkbox.attrs = append(checkbox.attrs, htmlAttr{Key: "checked"})
+				}
+				li := newElementNode("li")
+				li.appendChild(checkbox)
+				li.appendChild(&htmlNode{typ: htmlTextNode, data: " " + textEscaper.Replace(option)})
+				list.appendChild(li)
+			}
+			sel.replaceWith(list)
+		}
+	}
+}
+
+// labelsByFor indexes the <label> elements of root by their for attribute.
+func labelsByFor(root *htmlNode) map[string]*htmlNode {
+	labels := make(map[string]*htmlNode)
+	for _, label := range root.findAll("label") {
+		if id := label.attrOr("for", ""); id != "" && labels[id] == nil {
+			labels[id] = label
+		}
+	}
+	return labels
+}
+
+// fieldLabel returns the human-readable label of a form field: the text of
+// the <label> naming its id, or of the <label> wrapping it, falling back
+// to its aria-label and placeholder attributes.
+func fieldLabel(field *htmlNode, labels map[string]*htmlNode) string {
+	if label := labels[field.attrOr("id", "")]; label != nil {
+		if text := labelText(label); text != "" {
+			return text
+		}
+	}
+	for p := field.parent; p != nil; p = p.parent {
+		if p.typ == htmlElementNode && p.tag == "label" {
+			if text := labelText(p); text != "" {
+				return text
+			}
+			break
+		}
+	}
+	for _, key := range []string{"aria-label", "placeholder"} {
+		if text := strings.TrimSpace(field.attrOr(key, "")); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// labelText returns the collapsed text of a <label>, leaving out the form
+// fields it wraps, such as the options of a <select>.
+func labelText(label *htmlNode) string {
+	var b strings.Builder
+	for _, c := range label.children {
+		c.walk(func(n *htmlNode) bool {
+			switch n.typ {
+			case htmlTextNode:
+				b.WriteString(html.UnescapeString(n.data))
+				b.WriteByte(' ')
+			case htmlElementNode:
+				return !containsString(formFieldTags, n.tag) && !rawTextElements[n.tag]
+			}
+			return true
+		})
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,160 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Fragment placeholders are built from Unicode private use characters so they
+// survive the native conversion untouched and never collide with real text.
+const (
+	fragmentOpen  = "\uE000"
+	fragmentClose = "\uE001"
+)
+
+// fragmentSet holds Markdown produced on the Go side for parts of the tree.
+//
+// Each fragment is spliced into the HTML as an opaque placeholder before the
+// native conversion, and restored in the resulting Markdown afterwards.
+//
+// It also carries the headingChanges made by NormalizeHeadingHierarchy, so
+// ConvertWithMetadataOptions can report them.
+type fragmentSet struct {
+	items          []string
+	headingChanges []HeadingChange
+}
+
+// placeholder registers markdown and returns the token standing in for it.
+func (f *fragmentSet) placeholder(markdown string) string {
+	f.items = append(f.items, markdown)
+	return fragmentOpen + strconv.Itoa(len(f.items)-1) + fragmentClose
+}
+
+// node returns a node that renders as a placeholder for markdown. Block
+// fragments are wrapped in a <div> so the converter separates them from
+// surrounding content.
+func (f *fragmentSet) node(markdown string, inline bool) *htmlNode {
+	if inline {
+		return &htmlNode{typ: htmlTextNode, data: f.placeholder(markdown)}
+	}
+	div := newElementNode("div")
+	div.appendChild(&htmlNode{typ: htmlTextNode, data: f.placeholder(strings.Trim(markdown, "\n"))})
+	return div
+}
+
+// restore replaces every placeholder in markdown with its fragment. Fragments
+// spanning several lines inherit the line's quote and list indentation.
+func (f *fragmentSet) restore(markdown string) string {
+	if f == nil || len(f.items) == 0 || !strings.Contains(markdown, fragmentOpen) {
+		return markdown
+	}
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, fragmentOpen) {
+			continue
+		}
+		lines[i] = f.restoreLine(line, continuationPrefix(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (f *fragmentSet) restoreLine(line, prefix string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(line, fragmentOpen)
+		if start < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		end := strings.Index(line[start:], fragmentClose)
+		if end < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		end += start
+		id, err := strconv.Atoi(line[start+len(fragmentOpen) : end])
+		if err != nil || id < 0 || id >= len(f.items) {
+			b.WriteString(line[:end+len(fragmentClose)])
+			line = line[end+len(fragmentClose):]
+			continue
+		}
+		b.WriteString(line[:start])
+		b.WriteString(indentContinuation(f.restore(f.items[id]), prefix))
+		line = line[end+len(fragmentClose):]
+	}
+}
+
+// continuationPrefix returns the prefix that continuation lines of a block
+// nested at the same position as line need: quote markers are repeated and
+// list markers are replaced by spaces.
+func continuationPrefix(line string) string {
+	var b strings.Builder
+	i := 0
+	for i < len(line) {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t' || c == '>':
+			b.WriteByte(c)
+			i++
+		case (c == '-' || c == '*' || c == '+') && i+1 < len(line) && line[i+1] == ' ':
+			b.WriteString("  ")
+			i += 2
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(line) && line[j] >= '0' && line[j] <= '9' {
+				j++
+			}
+			if j+1 < len(line) && (line[j] == '.' || line[j] == ')') && line[j+1] == ' ' {
+				b.WriteString(strings.Repeat(" ", j-i+2))
+				i = j + 2
+				continue
+			}
+			return b.String()
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func indentContinuation(s, prefix string) string {
+	if prefix == "" || !strings.Contains(s, "\n") {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "" {
+			lines[i] = strings.TrimRight(prefix, " \t")
+			continue
+		}
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unwrap replaces element n with its children enclosed in the open and close
+// fragments, so the native converter still renders the content in place.
+// Whitespace at the edges of the content moves outside the fragments, since
+// CommonMark does not recognize delimiters followed or preceded by a space;
+// content that is only whitespace is kept without the fragments.
+func (f *fragmentSet) unwrap(n *htmlNode, open, closing string) {
+	leading, trailing := n.trimEdgeSpace()
+	if !hasInlineContent(n.children) {
+		if leading || trailing {
+			n.replaceWith(&htmlNode{typ: htmlTextNode, data: " "})
+		} else {
+			n.remove()
+		}
+		return
+	}
+	nodes := make([]*htmlNode, 0, len(n.children)+4)
+	if leading {
+		nodes = append(nodes, &htmlNode{typ: htmlTextNode, data: " "})
+	}
+	nodes = append(nodes, f.node(open, true))
+	nodes = append(nodes, n.children...)
+	nodes = append(nodes, f.node(closing, true))
+	if trailing {
+		nodes = append(nodes, &htmlNode{typ: htmlTextNode, data: " "})
+	}
+	n.replaceWith(nodes...)
+}
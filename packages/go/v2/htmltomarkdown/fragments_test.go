@@ -0,0 +1,43 @@
+package htmltomarkdown
+
+import (
+	"testing"
+)
+
+func TestFragmentSetRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		line     func(token string) string
+		expected string
+	}{
+		{
+			name:     "inline fragment",
+			fragment: "**x**",
+			line:     func(token string) string { return "a " + token + " b" },
+			expected: "a **x** b",
+		},
+		{
+			name:     "multi-line fragment in a blockquote",
+			fragment: "one\n\ntwo",
+			line:     func(token string) string { return "> " + token },
+			expected: "> one\n>\n> two",
+		},
+		{
+			name:     "multi-line fragment in a list item",
+			fragment: "one\ntwo",
+			line:     func(token string) string { return "- " + token },
+			expected: "- one\n  two",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragments := &fragmentSet{}
+			token := fragments.placeholder(tt.fragment)
+			if got := fragments.restore(tt.line(token)); got != tt.expected {
+				t.Errorf("restore() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
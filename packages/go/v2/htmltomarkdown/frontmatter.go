@@ -0,0 +1,224 @@
+package htmltomarkdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FrontmatterFormat selects the syntax of the block written by
+// ConvertWithFrontmatter.
+type FrontmatterFormat string
+
+const (
+	// FrontmatterFormatYAML writes YAML between --- lines.
+	FrontmatterFormatYAML FrontmatterFormat = "yaml"
+
+	// FrontmatterFormatTOML writes TOML between +++ lines.
+	FrontmatterFormatTOML FrontmatterFormat = "toml"
+
+	// FrontmatterFormatJSON writes a JSON object, as read by Hugo.
+	FrontmatterFormatJSON FrontmatterFormat = "json"
+)
+
+// frontmatterFields lists the document metadata fields ConvertWithFrontmatter
+// can emit.
+var frontmatterFields = []string{"title", "description", "author", "canonical", "keywords"}
+
+// frontmatterEntry is a field of a frontmatter block. Keywords is the only
+// list-valued field.
+type frontmatterEntry struct {
+	key    string
+	value  string
+	values []string
+	list   bool
+}
+
+// ConvertWithFrontmatter converts html like ConvertWithMetadata and prepends
+// a frontmatter block in format holding the requested document metadata
+// fields, in the order given: "title", "description", "author", "canonical"
+// (the canonical URL) and "keywords". An empty format means
+// FrontmatterFormatYAML. Fields missing from the document are left out, and
+// no block is written when none of them is present.
+//
+// Example:
+//
+//	markdown, err := htmltomarkdown.ConvertWithFrontmatter(html, []string{"title", "keywords"}, htmltomarkdown.FrontmatterFormatYAML)
+//	// markdown == "---\ntitle: \"My Article\"\nkeywords:\n  - \"go\"\n---\n\n# My Article\n..."
+func ConvertWithFrontmatter(html string, fields []string, format FrontmatterFormat) (string, error) {
+	if err := checkFrontmatterFormat(format); err != nil {
+		return "", err
+	}
+	for _, field := range fields {
+		if !containsString(frontmatterFields, field) {
+			return "", fmt.Errorf("invalid frontmatter field %q: expected one of %q", field, frontmatterFields)
+		}
+	}
+	result, err := ConvertWithMetadata(html)
+	if err != nil {
+		return "", err
+	}
+	entries := frontmatterEntries(&result.Metadata.Document, fields)
+	if len(entries) == 0 {
+		return result.Markdown, nil
+	}
+	var block string
+	switch format {
+	case FrontmatterFormatTOML:
+		block = tomlFrontmatter(entries)
+	case FrontmatterFormatJSON:
+		block = jsonFrontmatter(entries)
+	default:
+		block = yamlFrontmatter(entries)
+	}
+	return block + "\n" + result.Markdown, nil
+}
+
+func checkFrontmatterFormat(format FrontmatterFormat) error {
+	formats := []FrontmatterFormat{FrontmatterFormatYAML, FrontmatterFormatTOML, FrontmatterFormatJSON}
+	if format == "" || slices.Contains(formats, format) {
+		return nil
+	}
+	return fmt.Errorf("invalid frontmatter format %q: expected one of %q", format, formats)
+}
+
+// frontmatterEntries returns the fields of doc that have a value.
+func frontmatterEntries(doc *DocumentMetadata, fields []string) []frontmatterEntry {
+	var entries []frontmatterEntry
+	for _, field := range fields {
+		var value *string
+		switch field {
+		case "title":
+			value = doc.Title
+		case "description":
+			value = doc.Description
+		case "author":
+			value = doc.Author
+		case "canonical":
+			value = doc.CanonicalURL
+		case "keywords":
+			if len(doc.Keywords) > 0 {
+				entries = append(entries, frontmatterEntry{key: field, values: doc.Keywords, list: true})
+			}
+			continue
+		}
+		if value != nil && *value != "" {
+			entries = append(entries, frontmatterEntry{key: field, value: *value})
+		}
+	}
+	return entries
+}
+
+// yamlFrontmatter renders entries as a YAML block between --- lines. Strings
+// are double-quoted, so colons, quotes and newlines in values stay literal.
+func yamlFrontmatter(entries []frontmatterEntry) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, e := range entries {
+		if !e.list {
+			b.WriteString(e.key + ": " + yamlQuote(e.value) + "\n")
+			continue
+		}
+		b.WriteString(e.key + ":\n")
+		for _, v := range e.values {
+			b.WriteString("  - " + yamlQuote(v) + "\n")
+		}
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// yamlQuote returns s as a YAML double-quoted scalar. The escapes produced
+// by strconv.Quote are all valid in YAML double-quoted strings.
+func yamlQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// tomlFrontmatter renders entries as a TOML block between +++ lines.
+func tomlFrontmatter(entries []frontmatterEntry) string {
+	var b strings.Builder
+	b.WriteString("+++\n")
+	for _, e := range entries {
+		if !e.list {
+			b.WriteString(e.key + " = " + tomlQuote(e.value) + "\n")
+			continue
+		}
+		quoted := make([]string, len(e.values))
+		for i, v := range e.values {
+			quoted[i] = tomlQuote(v)
+		}
+		b.WriteString(e.key + " = [" + strings.Join(quoted, ", ") + "]\n")
+	}
+	b.WriteString("+++\n")
+	return b.String()
+}
+
+// tomlQuote returns s as a TOML basic string. TOML has no \a, \v or \x
+// escapes, so other control characters are written as \u escapes.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// jsonFrontmatter renders entries as an indented JSON object, keeping the
+// order of the fields.
+func jsonFrontmatter(entries []frontmatterEntry) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, e := range entries {
+		b.WriteString("  " + jsonQuote(e.key) + ": ")
+		if e.list {
+			quoted := make([]string, len(e.values))
+			for j, v := range e.values {
+				quoted[j] = jsonQuote(v)
+			}
+			b.WriteString("[" + strings.Join(quoted, ", ") + "]")
+		} else {
+			b.WriteString(jsonQuote(e.value))
+		}
+		if i < len(entries)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// jsonQuote returns s as a JSON string, leaving <, > and & unescaped.
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // encoding a string cannot fail
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,61 @@
+package htmltomarkdown
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestYAMLFrontmatter(t *testing.T) {
+	title := "Go: the \"fast\" parts\nand more \\ less"
+	doc := DocumentMetadata{Title: &title, Keywords: []string{"go", "a: b"}}
+	block := yamlFrontmatter(frontmatterEntries(&doc, []string{"title", "author", "keywords"}))
+
+	expected := "---\ntitle: \"Go: the \\\"fast\\\" parts\\nand more \\\\ less\"\nkeywords:\n  - \"go\"\n  - \"a: b\"\n---\n"
+	if block != expected {
+		t.Fatalf("block = %q, expected %q", block, expected)
+	}
+	line := strings.Split(block, "\n")[1]
+	value, err := strconv.Unquote(strings.TrimPrefix(line, "title: "))
+	if err != nil || value != title {
+		t.Errorf("title round-trip = %q (%v), expected %q", value, err, title)
+	}
+}
+
+func TestFrontmatterFormats(t *testing.T) {
+	title := `Go: the "fast" parts`
+	doc := DocumentMetadata{Title: &title, Keywords: []string{"go"}}
+	entries := frontmatterEntries(&doc, []string{"title", "keywords"})
+
+	tests := []struct {
+		name     string
+		block    string
+		expected string
+	}{
+		{"yaml", yamlFrontmatter(entries), "---\ntitle: \"Go: the \\\"fast\\\" parts\"\nkeywords:\n  - \"go\"\n---\n"},
+		{"toml", tomlFrontmatter(entries), "+++\ntitle = \"Go: the \\\"fast\\\" parts\"\nkeywords = [\"go\"]\n+++\n"},
+		{"json", jsonFrontmatter(entries), "{\n  \"title\": \"Go: the \\\"fast\\\" parts\",\n  \"keywords\": [\"go\"]\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.block != tt.expected {
+				t.Errorf("block = %q, expected %q", tt.block, tt.expected)
+			}
+		})
+	}
+
+	var decoded struct {
+		Title    string   `json:"title"`
+		Keywords []string `json:"keywords"`
+	}
+	if err := json.Unmarshal([]byte(jsonFrontmatter(entries)), &decoded); err != nil || decoded.Title != title {
+		t.Errorf("JSON round-trip = %+v (%v), expected title %q", decoded, err, title)
+	}
+}
+
+func TestTOMLQuote(t *testing.T) {
+	if got, expected := tomlQuote("a\nb\a\\"), `"a\nb\u0007\\"`; got != expected {
+		t.Errorf("tomlQuote = %s, expected %s", got, expected)
+	}
+}
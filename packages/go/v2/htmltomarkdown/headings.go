@@ -0,0 +1,86 @@
+package htmltomarkdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// setextSentinel marks the level 1 and 2 headings to rewrite in setext style,
+// since the native converter always writes ATX headings.
+const setextSentinel = "\uE004"
+
+var setextHeadingPattern = regexp.MustCompile(`^([ >]*)(#{1,2}) ` + setextSentinel + `(.*)$`)
+
+// shiftHeadings changes the level of every heading by offset, clamped to
+// the levels 1 through 6.
+func shiftHeadings(root *htmlNode, offset int) {
+	for _, h := range root.findAll("h1", "h2", "h3", "h4", "h5", "h6") {
+		level := min(max(int(h.tag[1]-'0')+offset, 1), 6)
+		h.tag = "h" + strconv.Itoa(level)
+	}
+}
+
+// normalizeHeadingHierarchy rewrites the heading levels of root into a
+// consistent outline: the first heading becomes the only <h1>, later <h1>
+// elements are demoted to <h2>, and no heading sits more than one level
+// below the previous one. It returns the headings it changed.
+func normalizeHeadingHierarchy(root *htmlNode) []HeadingChange {
+	var changes []HeadingChange
+	prev, seenH1 := 0, false
+	for _, h := range root.findAll("h1", "h2", "h3", "h4", "h5", "h6") {
+		from := int(h.tag[1] - '0')
+		level := from
+		if level == 1 && seenH1 {
+			level = 2
+		}
+		level = min(level, prev+1)
+		seenH1 = seenH1 || level == 1
+		prev = level
+		if level != from {
+			h.tag = "h" + strconv.Itoa(level)
+			changes = append(changes, HeadingChange{Text: h.normalizedText(), From: uint8(from), To: uint8(level)})
+		}
+	}
+	return changes
+}
+
+// markSetextHeadings prefixes the content of every <h1> and <h2> with the
+// setext sentinel. Deeper headings have no setext form and stay ATX.
+func markSetextHeadings(root *htmlNode) {
+	for _, h := range root.findAll("h1", "h2") {
+		sentinel := &htmlNode{typ: htmlTextNode, data: setextSentinel, parent: h}
+		h.children = append([]*htmlNode{sentinel}, h.children...)
+	}
+}
+
+// applySetextHeadings rewrites the sentinel-marked ATX headings of markdown
+// as setext headings underlined with = for level 1 and - for level 2, as wide
+// as the heading text. Headings without text keep their ATX form.
+func applySetextHeadings(markdown string) string {
+	if !strings.Contains(markdown, setextSentinel) {
+		return markdown
+	}
+	lines := strings.Split(markdown, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		m := setextHeadingPattern.FindStringSubmatch(line)
+		if m == nil {
+			out = append(out, strings.ReplaceAll(line, setextSentinel, ""))
+			continue
+		}
+		prefix, level, text := m[1], m[2], strings.TrimSpace(strings.ReplaceAll(m[3], setextSentinel, ""))
+		if text == "" {
+			out = append(out, strings.TrimRight(prefix+level, " "))
+			continue
+		}
+		underline := "="
+		if level == "##" {
+			underline = "-"
+		}
+		width := max(3, utf8.RuneCountInString(text))
+		out = append(out, prefix+text, prefix+strings.Repeat(underline, width))
+	}
+	return strings.Join(out, "\n")
+}
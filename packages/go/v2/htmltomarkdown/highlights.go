@@ -0,0 +1,45 @@
+package htmltomarkdown
+
+// renderHighlights rewrites every <mark> outside code in the requested style.
+func renderHighlights(root *htmlNode, style HighlightStyle, fragments *fragmentSet) {
+	marks := root.findAll("mark")
+	for i := len(marks) - 1; i >= 0; i-- {
+		n := marks[i]
+		if n.hasAncestor("code", "pre") {
+			continue
+		}
+		switch style {
+		case HighlightStyleDoubleEquals:
+			fragments.unwrap(n, "==", "==")
+		case HighlightStyleHTML:
+			fragments.unwrap(n, "<mark>", "</mark>")
+		case HighlightStyleNone:
+			n.replaceWith(n.children...)
+		}
+	}
+}
+
+// renderEdits rewrites every <ins> and <del> outside code in the requested
+// style.
+func renderEdits(root *htmlNode, style ChangeTrackingStyle, fragments *fragmentSet) {
+	edits := root.findAll("ins", "del")
+	for i := len(edits) - 1; i >= 0; i-- {
+		n := edits[i]
+		if n.hasAncestor("code", "pre") {
+			continue
+		}
+		kept := n.tag == "ins"
+		switch style {
+		case ChangeTrackingStyleHTML:
+			n.replaceWith(fragments.node(fragments.restore(n.render()), isInlineTag(n.tag)))
+			continue
+		case ChangeTrackingStyleReject:
+			kept = !kept
+		}
+		if kept {
+			n.replaceWith(n.children...)
+		} else {
+			n.remove()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package htmltomarkdown
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotHOCR is returned by ConvertHOCR and ConvertHOCRWithMetadata when the
+// input carries no hOCR markup.
+var ErrNotHOCR = errors.New("input is not an hOCR document: expected an ocr-system meta tag or ocr_page, ocr_carea, ocr_par, ocr_line or ocrx_word elements")
+
+// hocrClasses are the element classes that mark a document as hOCR.
+var hocrClasses = map[string]bool{
+	"ocr_page":  true,
+	"ocr_carea": true,
+	"ocr_par":   true,
+	"ocr_line":  true,
+	"ocrx_word": true,
+}
+
+// ConvertHOCR converts an hOCR document (HTML produced by OCR engines such as
+// Tesseract) to Markdown.
+//
+// The native library reconstructs paragraphs from the ocrx_word spans and
+// detects tables from the word bounding boxes instead of treating the page as
+// generic HTML. It returns ErrNotHOCR if hocr contains no hOCR markup.
+//
+// Example:
+//
+//	markdown, err := htmltomarkdown.ConvertHOCR(hocr)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func ConvertHOCR(hocr string) (string, error) {
+	if !isHOCR(hocr) {
+		return "", ErrNotHOCR
+	}
+	return convertFFI(hocr)
+}
+
+// ConvertHOCRWithMetadata is like ConvertHOCR but also returns the extracted
+// metadata. The OCR system details are emitted as YAML frontmatter by the
+// native library.
+func ConvertHOCRWithMetadata(hocr string) (MetadataExtraction, error) {
+	if !isHOCR(hocr) {
+		return MetadataExtraction{}, ErrNotHOCR
+	}
+	return ConvertWithMetadata(hocr)
+}
+
+// isHOCR reports whether document declares an OCR system or contains
+// elements with hOCR classes, mirroring the native library's detection.
+func isHOCR(document string) bool {
+	if !strings.Contains(document, "ocr") {
+		return false
+	}
+	found := false
+	parseHTML(document).walk(func(n *htmlNode) bool {
+		if found {
+			return false
+		}
+		if n.typ != htmlElementNode {
+			return true
+		}
+		if name := n.attrOr("name", ""); n.tag == "meta" && (name == "ocr-system" || name == "ocr-capabilities") {
+			found = true
+		}
+		for _, class := range strings.Fields(n.attrOr("class", "")) {
+			found = found || hocrClasses[class]
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,78 @@
+//go:build cgo
+
+package htmltomarkdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const hocrFixture = `<!DOCTYPE html>
+<html>
+<head>
+  <meta name="ocr-system" content="tesseract 5.3.0" />
+  <meta name="ocr-capabilities" content="ocr_page ocr_carea ocr_par ocr_line ocrx_word" />
+</head>
+<body>
+  <div class="ocr_page" id="page_1" title="bbox 0 0 1000 800">
+    <div class="ocr_carea" id="block_1_1" title="bbox 100 100 600 140">
+      <p class="ocr_par" id="par_1_1" title="bbox 100 100 600 140">
+        <span class="ocr_line" id="line_1_1" title="bbox 100 100 600 140">
+          <span class="ocrx_word" id="word_1_1" title="bbox 100 100 180 140; x_wconf 95">Hello</span>
+          <span class="ocrx_word" id="word_1_2" title="bbox 190 100 270 140; x_wconf 96">world</span>
+          <span class="ocrx_word" id="word_1_3" title="bbox 280 100 340 140; x_wconf 94">from</span>
+          <span class="ocrx_word" id="word_1_4" title="bbox 350 100 420 140; x_wconf 97">OCR</span>
+        </span>
+      </p>
+    </div>
+  </div>
+</body>
+</html>`
+
+func TestIsHOCR(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "hocr fixture", input: hocrFixture, want: true},
+		{name: "ocr-system meta only", input: `<meta name="ocr-system" content="tesseract">`, want: true},
+		{name: "word spans only", input: `<span class="ocrx_word">Hi</span>`, want: true},
+		{name: "plain html", input: `<p>Hello world</p>`},
+		{name: "class mentioning ocr", input: `<p class="ocr_pages-banner">Hello</p>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isHOCR(tt.input); got != tt.want {
+				t.Errorf("isHOCR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertHOCR(t *testing.T) {
+	markdown, err := ConvertHOCR(hocrFixture)
+	if err != nil {
+		t.Fatalf("ConvertHOCR failed: %v", err)
+	}
+	if !strings.Contains(markdown, "Hello world from OCR") {
+		t.Errorf("Expected words joined into a paragraph, got: %q", markdown)
+	}
+	if strings.Contains(markdown, "<span") || strings.Contains(markdown, "tesseract") {
+		t.Errorf("Expected no hOCR markup or OCR metadata in output, got: %q", markdown)
+	}
+}
+
+func TestConvertHOCR_RejectsPlainHTML(t *testing.T) {
+	_, err := ConvertHOCR("<p>Hello world</p>")
+	if !errors.Is(err, ErrNotHOCR) {
+		t.Errorf("ConvertHOCR error = %v, want ErrNotHOCR", err)
+	}
+
+	_, err = ConvertHOCRWithMetadata("<p>Hello world</p>")
+	if !errors.Is(err, ErrNotHOCR) {
+		t.Errorf("ConvertHOCRWithMetadata error = %v, want ErrNotHOCR", err)
+	}
+}
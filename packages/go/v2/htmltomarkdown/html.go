@@ -0,0 +1,630 @@
+package htmltomarkdown
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlNodeType identifies the kind of node in a parsed HTML tree.
+type htmlNodeType int
+
+const (
+	htmlDocumentNode htmlNodeType = iota
+	htmlElementNode
+	htmlTextNode
+	htmlCommentNode
+	htmlDoctypeNode
+)
+
+// htmlAttr is a single element attribute with its value already unescaped.
+type htmlAttr struct {
+	Key string
+	Val string
+}
+
+// htmlNode is a node of the lightweight HTML tree used to drive visitor
+// dispatch and HTML-level transforms before the native conversion runs.
+//
+// Text, comment and doctype nodes keep their markup in data, so that
+// transforms can splice in rendered HTML and the tree re-serializes as is.
+type htmlNode struct {
+	typ      htmlNodeType
+	tag      string
+	attrs    []htmlAttr
+	data     string
+	parent   *htmlNode
+	children []*htmlNode
+	offset   int  // byte offset of the start tag in the source, set by parseHTMLWithOffsets
+	implied  bool // created by the parser without a start tag, set by parseHTMLWithOffsets
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements hold text rather than markup. The text of textarea and
+// title decodes entities and is kept escaped in data; that of the others is
+// kept as written.
+var rawTextElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true, "plaintext": true,
+	"script": true, "style": true, "textarea": true, "title": true, "xmp": true,
+}
+
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "br": true,
+	"button": true, "cite": true, "code": true, "data": true, "del": true,
+	"dfn": true, "em": true, "i": true, "img": true, "input": true, "ins": true,
+	"kbd": true, "label": true, "mark": true, "q": true, "s": true, "samp": true,
+	"select": true, "small": true, "span": true, "strike": true, "strong": true,
+	"sub": true, "sup": true, "time": true, "u": true, "var": true, "wbr": true,
+}
+
+// paragraphClosers lists the start tags that implicitly close an open <p>.
+var paragraphClosers = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "div": true, "dl": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "ul": true,
+}
+
+func isInlineTag(tag string) bool {
+	return inlineElements[tag]
+}
+
+// offsetAttr carries the source offset of a start tag through the parser for
+// parseHTMLWithOffsets.
+const offsetAttr = "data-html-to-markdown-offset"
+
+// maxParseDepth is the deepest element nesting the parser accepts.
+const maxParseDepth = 512
+
+// parseDocument parses src into a tree rooted at a document node.
+//
+// It follows the HTML5 parsing algorithm, like the native library's parser,
+// so that implied elements, misnested tags and raw text come out the same way
+// and the tree re-serializes to a document the native library reads as it
+// would read src. Documents nesting elements deeper than maxParseDepth fail
+// with a *ConversionError of kind ConversionErrorMaxDepthExceeded.
+func parseDocument(src string) (*htmlNode, error) {
+	return parseNodes(src, false)
+}
+
+func parseNodes(src string, offsets bool) (*htmlNode, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, &ConversionError{
+			Kind:    ConversionErrorMaxDepthExceeded,
+			Message: fmt.Sprintf("element nesting exceeds the parser's maximum depth of %d", maxParseDepth),
+		}
+	}
+	return importNode(doc, offsets), nil
+}
+
+// parseHTML parses src like parseDocument, returning an empty document when
+// src nests too deep to parse.
+func parseHTML(src string) *htmlNode {
+	root, err := parseDocument(src)
+	if err != nil {
+		return &htmlNode{typ: htmlDocumentNode}
+	}
+	return root
+}
+
+// parseHTMLWithOffsets parses src like parseDocument and records the byte
+// offset of each element's start tag in src. Elements the parser creates
+// without a start tag, such as an implied <body> or <tbody>, are marked
+// implied instead.
+func parseHTMLWithOffsets(src string) (*htmlNode, error) {
+	return parseNodes(markStartTags(src), true)
+}
+
+// markStartTags adds an offsetAttr attribute holding the source offset to
+// every start tag of src. The tokenizer follows the parser into foreign
+// content, where CDATA sections and <style> hold markup-like text.
+func markStartTags(src string) string {
+	var b strings.Builder
+	b.Grow(len(src) + len(src)/8)
+	z := html.NewTokenizer(strings.NewReader(src))
+	offset, foreign := 0, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		end := len(raw) - 1
+		if tt == html.SelfClosingTagToken {
+			end--
+		}
+		if (tt == html.StartTagToken || tt == html.SelfClosingTagToken) && end > 0 && raw[len(raw)-1] == '>' {
+			b.Write(raw[:end])
+			b.WriteString(" " + offsetAttr + `="` + strconv.Itoa(offset) + `"`)
+			b.Write(raw[end:])
+		} else {
+			b.Write(raw)
+		}
+		offset += len(raw)
+
+		name, _ := z.TagName()
+		switch tag := string(name); {
+		case tt == html.StartTagToken && (tag == "svg" || tag == "math"):
+			foreign++
+		case tt == html.EndTagToken && (tag == "svg" || tag == "math") && foreign > 0:
+			foreign--
+		case tt == html.StartTagToken && foreign > 0 && rawTextElements[tag]:
+			z.NextIsNotRawText()
+		}
+		z.AllowCDATA(foreign > 0)
+	}
+	if offset < len(src) {
+		b.WriteString(src[offset:])
+	}
+	return b.String()
+}
+
+// importNode converts the parsed node n and its descendants. Text outside
+// raw-text elements is escaped again, so that data holds markup.
+func importNode(n *html.Node, offsets bool) *htmlNode {
+	node := &htmlNode{}
+	switch n.Type {
+	case html.DocumentNode:
+		node.typ = htmlDocumentNode
+	case html.ElementNode:
+		node.typ, node.tag, node.implied = htmlElementNode, n.Data, offsets
+		node.attrs = make([]htmlAttr, 0, len(n.Attr))
+		for _, a := range n.Attr {
+			if a.Key == offsetAttr && a.Namespace == "" {
+				node.offset, _ = strconv.Atoi(a.Val)
+				node.implied = false
+				continue
+			}
+			key := a.Key
+			if a.Namespace != "" {
+				key = a.Namespace + ":" + key
+			}
+			node.attrs = append(node.attrs, htmlAttr{Key: key, Val: a.Val})
+		}
+	case html.TextNode:
+		node.typ, node.data = htmlTextNode, n.Data
+		if p := n.Parent; p == nil || !rawTextElements[p.Data] || p.Data == "textarea" || p.Data == "title" {
+			node.data = textEscaper.Replace(n.Data)
+		}
+	case html.CommentNode, html.DoctypeNode:
+		node.typ = htmlCommentNode
+		if n.Type == html.DoctypeNode {
+			node.typ = htmlDoctypeNode
+		}
+		var b strings.Builder
+		if err := html.Render(&b, n); err != nil {
+			return nil
+		}
+		node.data = b.String()
+	default:
+		return nil
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if child := importNode(c, offsets); child != nil {
+			node.appendChild(child)
+		}
+	}
+	return node
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// newElementNode returns a detached element node.
+func newElementNode(tag string, attrs ...htmlAttr) *htmlNode {
+	return &htmlNode{typ: htmlElementNode, tag: tag, attrs: attrs}
+}
+
+// clone returns a detached deep copy of n.
+func (n *htmlNode) clone() *htmlNode {
+	c := &htmlNode{typ: n.typ, tag: n.tag, data: n.data, offset: n.offset}
+	c.attrs = append([]htmlAttr(nil), n.attrs...)
+	for _, child := range n.children {
+		c.appendChild(child.clone())
+	}
+	return c
+}
+
+// setAttr sets the value of attribute key, adding it if missing.
+func (n *htmlNode) setAttr(key, val string) {
+	for i := range n.attrs {
+		if n.attrs[i].Key == key {
+			n.attrs[i].Val = val
+			return
+		}
+	}
+	n.attrs = append(n.attrs, htmlAttr{Key: key, Val: val})
+}
+
+func (n *htmlNode) removeAttr(key string) {
+	for i := range n.attrs {
+		if n.attrs[i].Key == key {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *htmlNode) appendChild(child *htmlNode) {
+	child.parent = n
+	n.children = append(n.children, child)
+}
+
+// index returns the position of n among its parent's children.
+func (n *htmlNode) index() int {
+	if n.parent == nil {
+		return -1
+	}
+	for i, c := range n.parent.children {
+		if c == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// elementIndex returns the position of n among its parent's element children.
+func (n *htmlNode) elementIndex() int {
+	if n.parent == nil {
+		return 0
+	}
+	idx := 0
+	for _, c := range n.parent.children {
+		if c == n {
+			return idx
+		}
+		if c.typ == htmlElementNode {
+			idx++
+		}
+	}
+	return idx
+}
+
+// elementSiblingCount returns the number of element children of n's parent,
+// n included.
+func (n *htmlNode) elementSiblingCount() int {
+	if n.parent == nil {
+		return 1
+	}
+	count := 0
+	for _, c := range n.parent.children {
+		if c.typ == htmlElementNode {
+			count++
+		}
+	}
+	return count
+}
+
+// replaceWith substitutes n in its parent with the given nodes.
+func (n *htmlNode) replaceWith(nodes ...*htmlNode) {
+	parent := n.parent
+	idx := n.index()
+	if idx < 0 {
+		return
+	}
+	for _, node := range nodes {
+		node.parent = parent
+	}
+	children := make([]*htmlNode, 0, len(parent.children)-1+len(nodes))
+	children = append(children, parent.children[:idx]...)
+	children = append(children, nodes...)
+	children = append(children, parent.children[idx+1:]...)
+	parent.children = children
+	n.parent = nil
+}
+
+// remove detaches n from its parent.
+func (n *htmlNode) remove() {
+	n.replaceWith()
+}
+
+func (n *htmlNode) attr(key string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func (n *htmlNode) attrOr(key, fallback string) string {
+	if v, ok := n.attr(key); ok {
+		return v
+	}
+	return fallback
+}
+
+// hasClass reports whether n's class attribute contains class.
+func (n *htmlNode) hasClass(class string) bool {
+	for _, c := range strings.Fields(n.attrOr("class", "")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// styleProperty returns the lowercase value of the CSS property name in n's
+// style attribute, without any !important flag. The last declaration wins.
+func (n *htmlNode) styleProperty(name string) string {
+	value := ""
+	for _, decl := range strings.Split(n.attrOr("style", ""), ";") {
+		prop, val, ok := strings.Cut(decl, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(prop), name) {
+			val = strings.ToLower(strings.TrimSpace(val))
+			value = strings.TrimSpace(strings.TrimSuffix(val, "!important"))
+		}
+	}
+	return value
+}
+
+// hasAncestor reports whether any ancestor of n is one of the given tags.
+func (n *htmlNode) hasAncestor(tags ...string) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p.typ == htmlElementNode && containsString(tags, p.tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// elementDepth returns the number of element ancestors of n. Ancestors the
+// parser implied are not counted here, nor by parentTag and ancestorTags, so
+// that they describe the document as written.
+func (n *htmlNode) elementDepth() int {
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		if p.typ == htmlElementNode && !p.implied {
+			depth++
+		}
+	}
+	return depth
+}
+
+// parentTag returns the tag of the nearest element ancestor.
+func (n *htmlNode) parentTag() string {
+	for p := n.parent; p != nil; p = p.parent {
+		if p.typ == htmlElementNode && !p.implied {
+			return p.tag
+		}
+	}
+	return ""
+}
+
+// ancestorTags returns the tags of the elements enclosing n, innermost first.
+func (n *htmlNode) ancestorTags() []string {
+	var tags []string
+	for p := n.parent; p != nil; p = p.parent {
+		if p.typ == htmlElementNode && !p.implied {
+			tags = append(tags, p.tag)
+		}
+	}
+	return tags
+}
+
+// walk calls fn for n and its descendants in document order. Returning false
+// from fn skips the node's children.
+func (n *htmlNode) walk(fn func(*htmlNode) bool) {
+	if !fn(n) {
+		return
+	}
+	children := append([]*htmlNode(nil), n.children...)
+	for _, c := range children {
+		c.walk(fn)
+	}
+}
+
+// maxDepth returns the element nesting depth below n, counting up to just
+// past limit. The tree is traversed without recursion so that hostile input
+// cannot exhaust the stack.
+func (n *htmlNode) maxDepth(limit int) int {
+	type frame struct {
+		node  *htmlNode
+		depth int
+	}
+	deepest := 0
+	stack := []frame{{n, 0}}
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		deepest = max(deepest, f.depth)
+		if deepest > limit {
+			return deepest
+		}
+		for _, c := range f.node.children {
+			if c.typ == htmlElementNode {
+				stack = append(stack, frame{c, f.depth + 1})
+			}
+		}
+	}
+	return deepest
+}
+
+// findAll returns the descendant elements of n with one of the given tags.
+func (n *htmlNode) findAll(tags ...string) []*htmlNode {
+	var found []*htmlNode
+	for _, c := range n.children {
+		c.walk(func(node *htmlNode) bool {
+			if node.typ == htmlElementNode && containsString(tags, node.tag) {
+				found = append(found, node)
+			}
+			return true
+		})
+	}
+	return found
+}
+
+// text returns the unescaped text content of n and its descendants.
+func (n *htmlNode) text() string {
+	var b strings.Builder
+	n.writeText(&b)
+	return b.String()
+}
+
+func (n *htmlNode) writeText(b *strings.Builder) {
+	switch n.typ {
+	case htmlTextNode:
+		if n.parent != nil && rawTextElements[n.parent.tag] && n.parent.tag != "textarea" && n.parent.tag != "title" {
+			b.WriteString(n.data)
+			return
+		}
+		b.WriteString(html.UnescapeString(n.data))
+	case htmlElementNode, htmlDocumentNode:
+		if n.tag == "br" {
+			b.WriteByte('\n')
+			return
+		}
+		for _, c := range n.children {
+			c.writeText(b)
+		}
+	}
+}
+
+// normalizedText returns the text content of n with whitespace collapsed.
+func (n *htmlNode) normalizedText() string {
+	return strings.Join(strings.Fields(n.text()), " ")
+}
+
+// trimEdgeSpace removes the whitespace at the start and end of the content
+// of n, reporting whether any was removed at each edge. Trimming stops at an
+// image or line break, which count as content.
+func (n *htmlNode) trimEdgeSpace() (leading, trailing bool) {
+	var parts []*htmlNode
+	n.walk(func(c *htmlNode) bool {
+		if c.typ == htmlTextNode || (c.typ == htmlElementNode && (c.tag == "img" || c.tag == "br")) {
+			parts = append(parts, c)
+		}
+		return c.typ != htmlElementNode || !rawTextElements[c.tag]
+	})
+	for _, c := range parts {
+		if c.typ != htmlTextNode {
+			break
+		}
+		trimmed := strings.TrimLeftFunc(c.data, isCollapsibleSpace)
+		leading = leading || trimmed != c.data
+		if c.data = trimmed; c.data != "" {
+			break
+		}
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		c := parts[i]
+		if c.typ != htmlTextNode {
+			break
+		}
+		trimmed := strings.TrimRightFunc(c.data, isCollapsibleSpace)
+		trailing = trailing || trimmed != c.data
+		if c.data = trimmed; c.data != "" {
+			break
+		}
+	}
+	return leading, trailing
+}
+
+// render serializes n and its descendants back to HTML.
+func (n *htmlNode) render() string {
+	var b strings.Builder
+	n.writeHTML(&b)
+	return b.String()
+}
+
+// renderChildren serializes the descendants of n without n itself.
+func (n *htmlNode) renderChildren() string {
+	var b strings.Builder
+	for _, c := range n.children {
+		c.writeHTML(&b)
+	}
+	return b.String()
+}
+
+func (n *htmlNode) writeHTML(b *strings.Builder) {
+	switch n.typ {
+	case htmlTextNode, htmlCommentNode, htmlDoctypeNode:
+		b.WriteString(n.data)
+	case htmlDocumentNode:
+		for _, c := range n.children {
+			c.writeHTML(b)
+		}
+	case htmlElementNode:
+		// Leaving out the tags the parser implied keeps the document as
+		// written, with its <script> and <style> elements in place.
+		if n.implied {
+			for _, c := range n.children {
+				c.writeHTML(b)
+			}
+			return
+		}
+		n.writeStartTag(b)
+		if voidElements[n.tag] {
+			return
+		}
+		// The parser drops a newline right after these start tags, so a
+		// leading newline of the content needs another in front of it.
+		if n.tag == "pre" || n.tag == "listing" || n.tag == "textarea" {
+			if len(n.children) > 0 && n.children[0].typ == htmlTextNode && strings.HasPrefix(n.children[0].data, "\n") {
+				b.WriteByte('\n')
+			}
+		}
+		for _, c := range n.children {
+			c.writeHTML(b)
+		}
+		n.writeEndTag(b)
+	}
+}
+
+func (n *htmlNode) writeStartTag(b *strings.Builder) {
+	b.WriteByte('<')
+	b.WriteString(n.tag)
+	for _, a := range sortedAttrs(n.attrs) {
+		b.WriteByte(' ')
+		b.WriteString(a.Key)
+		b.WriteString(`="`)
+		b.WriteString(escapeAttr(a.Val))
+		b.WriteByte('"')
+	}
+	b.WriteByte('>')
+}
+
+func (n *htmlNode) writeEndTag(b *strings.Builder) {
+	b.WriteString("</")
+	b.WriteString(n.tag)
+	b.WriteByte('>')
+}
+
+// sortedAttrs returns attrs ordered by name, so serialized HTML does not
+// depend on the attribute order of the source.
+func sortedAttrs(attrs []htmlAttr) []htmlAttr {
+	if slices.IsSortedFunc(attrs, compareAttrs) {
+		return attrs
+	}
+	sorted := slices.Clone(attrs)
+	slices.SortStableFunc(sorted, compareAttrs)
+	return sorted
+}
+
+func compareAttrs(a, b htmlAttr) int {
+	return strings.Compare(a.Key, b.Key)
+}
+
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;")
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
@@ -0,0 +1,162 @@
+package htmltomarkdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseHTML_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "simple elements",
+			input:    `<p class="intro">Hello <b>World</b></p>`,
+			expected: `<p class="intro">Hello <b>World</b></p>`,
+		},
+		{
+			name:     "void and self-closing elements",
+			input:    `<p>a<br/>b<img src="x.png"></p>`,
+			expected: `<p>a<br>b<img src="x.png"></p>`,
+		},
+		{
+			name:     "entities are preserved in text",
+			input:    `<p>Fish &amp; Chips &lt;3</p>`,
+			expected: `<p>Fish &amp; Chips &lt;3</p>`,
+		},
+		{
+			name:     "raw text elements keep markup",
+			input:    `<p>x</p><script>if (a < b) { x = "</p>"; }</script>`,
+			expected: `<p>x</p><script>if (a < b) { x = "</p>"; }</script>`,
+		},
+		{
+			name:     "implicit list item close",
+			input:    `<ul><li>One<li>Two</ul>`,
+			expected: `<ul><li>One</li><li>Two</li></ul>`,
+		},
+		{
+			name:     "implicit paragraph close",
+			input:    `<p>One<p>Two<div>Three</div>`,
+			expected: `<p>One</p><p>Two</p><div>Three</div>`,
+		},
+		{
+			name:     "comments and doctype",
+			input:    `<!DOCTYPE html><p>x</p><!-- note -->`,
+			expected: `<p>x</p><!-- note -->`,
+		},
+		{
+			name:     "stray less-than is text",
+			input:    `<p>1 < 2</p>`,
+			expected: `<p>1 &lt; 2</p>`,
+		},
+		{
+			name:     "less-than without a tag name is text",
+			input:    `<p>x<1, <- and <=</p>`,
+			expected: `<p>x&lt;1, &lt;- and &lt;=</p>`,
+		},
+		{
+			name:     "unclosed inline element stays markup",
+			input:    `<p><b>bold</p>`,
+			expected: `<p><b>bold</b></p>`,
+		},
+		{
+			name:     "self-closing div inside paragraph",
+			input:    `<p>a<div/>b</p>`,
+			expected: `<p>a</p><div>b<p></p></div>`,
+		},
+		{
+			name:     "misnested inline and paragraph",
+			input:    `<b><p>x</b>y</p>`,
+			expected: `<b></b><p><b>x</b>y</p>`,
+		},
+		{
+			name:     "cdata in foreign content",
+			input:    `<svg><![CDATA[ a > <b> ]]></svg><p>after</p>`,
+			expected: `<svg> a &gt; &lt;b&gt; </svg><p>after</p>`,
+		},
+		{
+			name:     "raw text elements",
+			input:    `<p>a</p><iframe><b>x</b></iframe><noembed><i>y</i></noembed><xmp><u>z</u></xmp>`,
+			expected: `<p>a</p><iframe><b>x</b></iframe><noembed><i>y</i></noembed><xmp><u>z</u></xmp>`,
+		},
+		{
+			name:     "quote left open in an attribute",
+			input:    `<p>a <span title="x>y</span> b">c</span></p>`,
+			expected: `<p>a <span title="x&gt;y&lt;/span&gt; b">c</span></p>`,
+		},
+		{
+			name:     "leading newline in pre",
+			input:    "<pre>\n\ncode</pre>",
+			expected: "<pre>\n\ncode</pre>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseHTML(tt.input).findAll("body")[0].renderChildren(); got != tt.expected {
+				t.Errorf("parseHTML(%q) body = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHTML_Attributes(t *testing.T) {
+	root := parseHTML(`<a HREF='/x?a=1&amp;b=2' title="Say &quot;hi&quot;" download>link</a>`)
+	links := root.findAll("a")
+	if len(links) != 1 {
+		t.Fatalf("found %d links, expected 1", len(links))
+	}
+	a := links[0]
+	if a.tag != "a" {
+		t.Errorf("tag = %s, expected 'a'", a.tag)
+	}
+	if href, _ := a.attr("href"); href != "/x?a=1&b=2" {
+		t.Errorf("href = %s, expected '/x?a=1&b=2'", href)
+	}
+	if title, _ := a.attr("title"); title != `Say "hi"` {
+		t.Errorf("title = %s, expected 'Say \"hi\"'", title)
+	}
+	if _, ok := a.attr("download"); !ok {
+		t.Error("boolean attribute 'download' should be present")
+	}
+	if text := a.text(); text != "link" {
+		t.Errorf("text = %s, expected 'link'", text)
+	}
+}
+
+func TestParseHTMLWithOffsets(t *testing.T) {
+	src := `<p>One <b>two</b></p><svg><![CDATA[<i>]]></svg><ul><li>x<li>y</ul>`
+	root, err := parseHTMLWithOffsets(src)
+	if err != nil {
+		t.Fatalf("parseHTMLWithOffsets failed: %v", err)
+	}
+	root.walk(func(n *htmlNode) bool {
+		if len(n.attrs) > 0 {
+			t.Errorf("<%s> has attributes %v, expected none", n.tag, n.attrs)
+		}
+		return true
+	})
+	for _, n := range root.findAll("p", "b", "svg", "ul", "li") {
+		if !strings.HasPrefix(src[n.offset:], "<"+n.tag) {
+			t.Errorf("offset %d of <%s> does not point at its start tag", n.offset, n.tag)
+		}
+	}
+	if i := root.findAll("i"); len(i) != 0 {
+		t.Errorf("CDATA content parsed as %d elements, expected text", len(i))
+	}
+}
+
+func TestParseDocument_MaxDepth(t *testing.T) {
+	src := strings.Repeat("<div>", 2*maxParseDepth) + "deep"
+	_, err := parseDocument(src)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Kind != ConversionErrorMaxDepthExceeded {
+		t.Fatalf("parseDocument() error = %v, expected a %s ConversionError", err, ConversionErrorMaxDepthExceeded)
+	}
+	if root := parseHTML(src); len(root.children) != 0 {
+		t.Errorf("parseHTML() = %q, expected an empty document", root.render())
+	}
+}
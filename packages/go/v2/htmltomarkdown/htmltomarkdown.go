@@ -15,24 +15,20 @@
 //	    }
 //	    fmt.Println(markdown)
 //	}
+//
+// Building without cgo, for example for GOOS=js, replaces the native library
+// with a pure-Go converter. It supports headings, paragraphs, lists, links,
+// images, emphasis, code, block quotes and horizontal rules; other elements
+// keep their text but lose their structure. ConvertWithMetadata,
+// ConvertWithStats, VersionInfo and the profiling functions return
+// ErrNativeUnavailable in such builds.
 package htmltomarkdown
 
-// #include <stdlib.h>
-// #include <stdbool.h>
-// #include <stdint.h>
-//
-// char* html_to_markdown_convert_proxy(const char* html);
-// void html_to_markdown_free_string_proxy(char* s);
-// const char* html_to_markdown_version_proxy(void);
-// const char* html_to_markdown_last_error_proxy(void);
-// char* html_to_markdown_convert_with_metadata_proxy(const char* html, char** metadata_json);
-// bool html_to_markdown_profile_start_proxy(const char* output, int32_t frequency);
-// bool html_to_markdown_profile_stop_proxy(void);
-import "C"
 import (
-	"encoding/json"
-	"errors"
-	"unsafe"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 const unknownValue = "unknown"
@@ -40,6 +36,8 @@ const unknownValue = "unknown"
 // Convert converts HTML to Markdown using default options.
 //
 // It returns the converted Markdown string or an error if the conversion fails.
+// If the native library cannot be loaded, the error is a *LibraryLoadError,
+// unless a fallback converter was registered with SetFallback.
 // The function handles memory management automatically using defer.
 //
 // Example:
@@ -54,24 +52,12 @@ func Convert(html string) (string, error) {
 		return "", nil
 	}
 	if err := ensureFFILoaded(); err != nil {
-		return "", err
-	}
-
-	cHTML := C.CString(html)
-	defer C.free(unsafe.Pointer(cHTML))
-
-	result := C.html_to_markdown_convert_proxy(cHTML)
-	if result == nil {
-		errMsg := C.html_to_markdown_last_error_proxy()
-		if errMsg != nil {
-			return "", errors.New(C.GoString(errMsg))
+		if fn := currentFallback(); fn != nil {
+			return fn(html)
 		}
-		return "", errors.New("html to markdown conversion failed")
+		return "", err
 	}
-	defer C.html_to_markdown_free_string_proxy(result)
-
-	markdown := C.GoString(result)
-	return markdown, nil
+	return convertDocument(html, &ConversionOptions{})
 }
 
 // MustConvert is like Convert but panics if an error occurs.
@@ -91,62 +77,111 @@ func MustConvert(html string) string {
 	return markdown
 }
 
-// Version returns the version string of the underlying html-to-markdown library.
-//
-// Example:
+// ConvertStats describes the native library's memory use during a conversion
+// run by ConvertWithStats.
+type ConvertStats struct {
+	// PeakBytes is the most heap memory the native library held at once
+	// during the conversion, beyond what it held before the conversion began.
+	PeakBytes uint64
+}
+
+// statsMutex serializes ConvertWithStats calls, which share the native
+// library's process-wide allocation peak.
+var statsMutex sync.Mutex
+
+// ConvertWithStats converts HTML to Markdown like Convert and reports the
+// native library's peak allocation during the conversion, for sizing the
+// memory limits of workers.
 //
-//	version := htmltomarkdown.Version()
-//	fmt.Printf("Using html-to-markdown version: %s\n", version)
-func Version() string {
+// The counter is process-wide: it counts every allocation of the native
+// library on any thread, and statsMutex only serializes ConvertWithStats
+// calls, so Convert and the other functions running concurrently on other
+// goroutines inflate PeakBytes. Measure on an otherwise idle process for
+// exact figures. The statistics require a native library built with the
+// alloc-stats cargo feature, which is off by default; other libraries return
+// an error.
+func ConvertWithStats(html string) (string, ConvertStats, error) {
 	if err := ensureFFILoaded(); err != nil {
-		return unknownValue
+		return "", ConvertStats{}, err
 	}
-	cVersion := C.html_to_markdown_version_proxy()
-	if cVersion == nil {
-		return unknownValue
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	if err := resetPeakBytes(); err != nil {
+		return "", ConvertStats{}, err
+	}
+	markdown, err := Convert(html)
+	if err != nil {
+		return "", ConvertStats{}, err
 	}
-	return C.GoString(cVersion)
+	return markdown, ConvertStats{PeakBytes: readPeakBytes()}, nil
 }
 
-// StartProfiling begins Rust-side profiling and writes a flamegraph to outputPath.
-func StartProfiling(outputPath string, frequency int) error {
-	if outputPath == "" {
-		return errors.New("output path is required")
-	}
+// LibraryVersion describes the version of the loaded native html-to-markdown library.
+//
+// It is returned by VersionInfo and allows programmatic version comparisons.
+type LibraryVersion struct {
+	// Semver is the full version string reported by the library (e.g. "2.19.1").
+	Semver string
+
+	Major int
+
+	Minor int
+
+	Patch int
+
+	// ABIVersion is the FFI ABI revision implemented by the library.
+	// Libraries that do not export an explicit ABI revision report their major version.
+	ABIVersion int
+}
+
+// VersionInfo returns structured version information for the loaded native library.
+//
+// Unlike Version, it reports why the information is unavailable: a *LibraryLoadError
+// when the library cannot be loaded, or a parse error for a malformed version string.
+//
+// Example:
+//
+//	info, err := htmltomarkdown.VersionInfo()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if info.Major < 2 {
+//	    log.Fatalf("html-to-markdown %s is too old", info.Semver)
+//	}
+func VersionInfo() (LibraryVersion, error) {
 	if err := ensureFFILoaded(); err != nil {
-		return err
+		return LibraryVersion{}, err
 	}
-	if frequency <= 0 {
-		frequency = 1000
-	}
-	cOutput := C.CString(outputPath)
-	defer C.free(unsafe.Pointer(cOutput))
-
-	ok := C.html_to_markdown_profile_start_proxy(cOutput, C.int32_t(frequency))
-	if !bool(ok) {
-		errMsg := C.html_to_markdown_last_error_proxy()
-		if errMsg != nil {
-			return errors.New(C.GoString(errMsg))
-		}
-		return errors.New("profiling start failed")
-	}
-	return nil
+	return readLibraryVersion()
 }
 
-// StopProfiling stops Rust-side profiling and flushes the flamegraph.
-func StopProfiling() error {
-	if err := ensureFFILoaded(); err != nil {
-		return err
+// parseLibraryVersion parses a semantic version string such as "2.19.1" or "2.20.0-rc.1".
+// ABIVersion defaults to the major version.
+func parseLibraryVersion(version string) (LibraryVersion, error) {
+	core := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(core, "-+"); idx >= 0 {
+		core = core[:idx]
 	}
-	ok := C.html_to_markdown_profile_stop_proxy()
-	if !bool(ok) {
-		errMsg := C.html_to_markdown_last_error_proxy()
-		if errMsg != nil {
-			return errors.New(C.GoString(errMsg))
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return LibraryVersion{}, fmt.Errorf("invalid html-to-markdown version %q", version)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return LibraryVersion{}, fmt.Errorf("invalid html-to-markdown version %q", version)
 		}
-		return errors.New("profiling stop failed")
+		numbers[i] = n
 	}
-	return nil
+	return LibraryVersion{
+		Semver:     version,
+		Major:      numbers[0],
+		Minor:      numbers[1],
+		Patch:      numbers[2],
+		ABIVersion: numbers[0],
+	}, nil
 }
 
 // TextDirection represents the directionality of text content.
@@ -207,6 +242,10 @@ const (
 type DocumentMetadata struct {
 	Title *string `json:"title,omitempty"`
 
+	// TitleRaw is the <title> as written in the source, with its character
+	// references such as &amp; intact; Title holds the decoded text.
+	TitleRaw *string `json:"title_raw,omitempty"`
+
 	Description *string `json:"description,omitempty"`
 
 	Keywords []string `json:"keywords,omitempty"`
@@ -226,6 +265,148 @@ type DocumentMetadata struct {
 	TwitterCard map[string]string `json:"twitter_card,omitempty"`
 
 	MetaTags map[string]string `json:"meta_tags,omitempty"`
+
+	// OpenGraphImages lists the og:image entries in document order, each
+	// with the og:image:* properties following it.
+	OpenGraphImages []OGImage `json:"open_graph_images,omitempty"`
+
+	// AlternateLinks lists the <link rel="alternate" hreflang="..."> language
+	// versions of the document.
+	AlternateLinks []AlternateLink `json:"alternate_links,omitempty"`
+
+	// FeedLinks lists the RSS and Atom feeds advertised with
+	// <link rel="alternate" type="...">.
+	FeedLinks []FeedLink `json:"feed_links,omitempty"`
+
+	// Robots holds the directives of the robots and googlebot <meta> tags,
+	// or is nil when the document has neither.
+	Robots *RobotsDirectives `json:"robots,omitempty"`
+
+	// DublinCore maps the lowercased names of the DC.* and dcterms.* <meta>
+	// tags, such as "dc.title", to their content. Repeated tags, like one
+	// DC.creator per author, are joined with "; ".
+	DublinCore map[string]string `json:"dublin_core,omitempty"`
+}
+
+// RobotsDirectives are the crawler directives of a document, parsed from the
+// comma-separated content of its robots and googlebot <meta> tags. A
+// directive set by either tag applies; "none" sets NoIndex and NoFollow.
+type RobotsDirectives struct {
+	NoIndex bool `json:"noindex"`
+
+	NoFollow bool `json:"nofollow"`
+
+	NoArchive bool `json:"noarchive"`
+
+	NoSnippet bool `json:"nosnippet"`
+
+	NoImageIndex bool `json:"noimageindex"`
+
+	NoTranslate bool `json:"notranslate"`
+}
+
+// FeedLink is an RSS or Atom feed of the document.
+type FeedLink struct {
+	Title string `json:"title,omitempty"`
+
+	Href string `json:"href"`
+
+	// Type is the feed's media type, such as "application/rss+xml".
+	Type string `json:"type"`
+}
+
+// AlternateLink is a language version of the document.
+type AlternateLink struct {
+	Hreflang string `json:"hreflang"`
+
+	Href string `json:"href"`
+}
+
+// OGImage is an og:image entry with its structured properties.
+type OGImage struct {
+	URL string `json:"url"`
+
+	SecureURL string `json:"secure_url,omitempty"`
+
+	Type string `json:"type,omitempty"`
+
+	Width uint32 `json:"width,omitempty"`
+
+	Height uint32 `json:"height,omitempty"`
+
+	Alt string `json:"alt,omitempty"`
+}
+
+// OGTitle returns the og:title of the document, falling back to its <title>.
+func (d DocumentMetadata) OGTitle() string {
+	return firstNonEmpty(d.OpenGraph["title"], deref(d.Title))
+}
+
+// OGDescription returns the og:description of the document, falling back to
+// its description <meta>.
+func (d DocumentMetadata) OGDescription() string {
+	return firstNonEmpty(d.OpenGraph["description"], deref(d.Description))
+}
+
+// OGImage returns the og:image URL of the document, or its og:image:url or
+// og:image:secure_url.
+func (d DocumentMetadata) OGImage() string {
+	return firstNonEmpty(d.OpenGraph["image"], d.OpenGraph["image_url"], d.OpenGraph["image_secure_url"])
+}
+
+// OGURL returns the og:url of the document, falling back to its canonical URL.
+func (d DocumentMetadata) OGURL() string {
+	return firstNonEmpty(d.OpenGraph["url"], deref(d.CanonicalURL))
+}
+
+// OGType returns the og:type of the document, such as "article".
+func (d DocumentMetadata) OGType() string {
+	return d.OpenGraph["type"]
+}
+
+// OGSiteName returns the og:site_name of the document.
+func (d DocumentMetadata) OGSiteName() string {
+	return d.OpenGraph["site_name"]
+}
+
+// TwitterCardType returns the twitter:card of the document, such as
+// "summary_large_image".
+func (d DocumentMetadata) TwitterCardType() string {
+	return d.TwitterCard["card"]
+}
+
+// TwitterTitle returns the twitter:title of the document, falling back to
+// OGTitle.
+func (d DocumentMetadata) TwitterTitle() string {
+	return firstNonEmpty(d.TwitterCard["title"], d.OGTitle())
+}
+
+// TwitterDescription returns the twitter:description of the document,
+// falling back to OGDescription.
+func (d DocumentMetadata) TwitterDescription() string {
+	return firstNonEmpty(d.TwitterCard["description"], d.OGDescription())
+}
+
+// TwitterImage returns the twitter:image of the document, falling back to
+// OGImage.
+func (d DocumentMetadata) TwitterImage() string {
+	return firstNonEmpty(d.TwitterCard["image"], d.TwitterCard["image_src"], d.OGImage())
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // HeaderMetadata contains header element metadata with hierarchy tracking.
@@ -291,6 +472,16 @@ type StructuredData struct {
 	SchemaType *string `json:"schema_type,omitempty"`
 }
 
+// HeadingChange records a heading whose level NormalizeHeadingHierarchy
+// rewrote.
+type HeadingChange struct {
+	Text string `json:"text"`
+
+	From uint8 `json:"from"`
+
+	To uint8 `json:"to"`
+}
+
 // ExtendedMetadata is the comprehensive metadata extraction result from an HTML document.
 //
 // Contains all extracted metadata types in a single structure,
@@ -305,6 +496,10 @@ type ExtendedMetadata struct {
 	Images []ImageMetadata `json:"images,omitempty"`
 
 	StructuredData []StructuredData `json:"structured_data,omitempty"`
+
+	// HeadingChanges is filled by ConvertWithMetadataOptions with the
+	// headings rewritten under ConversionOptions.NormalizeHeadingHierarchy.
+	HeadingChanges []HeadingChange `json:"heading_changes,omitempty"`
 }
 
 // MetadataExtraction contains the conversion result with metadata.
@@ -316,90 +511,6 @@ type MetadataExtraction struct {
 	Metadata ExtendedMetadata
 }
 
-// ConvertWithMetadata converts HTML to Markdown and extracts comprehensive metadata.
-//
-// This function calls the underlying FFI layer to perform conversion and metadata
-// extraction in a single pass. It returns the markdown along with structured metadata
-// including document information, headers, links, images, and structured data.
-//
-// The metadata extraction includes:
-// - Document metadata: title, description, keywords, author, canonical URL, language, etc.
-// - Headers: h1-h6 elements with hierarchy tracking
-// - Links: anchor elements with type classification and attributes
-// - Images: img and svg elements with source and dimensions
-// - Structured data: JSON-LD, Microdata, and RDFa blocks
-//
-// Example:
-//
-//	html := `<html>
-//	  <head>
-//	    <title>My Article</title>
-//	    <meta name="description" content="A great article">
-//	  </head>
-//	  <body>
-//	    <h1>Main Title</h1>
-//	    <p>Content with <a href="https://example.com">a link</a></p>
-//	    <img src="image.jpg" alt="An image">
-//	  </body>
-//	</html>`
-//	result, err := ConvertWithMetadata(html)
-//	if err != nil {
-//	    log.Fatal(err)
-//	}
-//	fmt.Println(result.Markdown)
-//	fmt.Printf("Title: %s\n", *result.Metadata.Document.Title)
-//	fmt.Printf("Headers: %d\n", len(result.Metadata.Headers))
-//	fmt.Printf("Links: %d\n", len(result.Metadata.Links))
-//	fmt.Printf("Images: %d\n", len(result.Metadata.Images))
-func ConvertWithMetadata(html string) (MetadataExtraction, error) {
-	if html == "" {
-		return MetadataExtraction{
-			Markdown: "",
-			Metadata: ExtendedMetadata{},
-		}, nil
-	}
-	if err := ensureFFILoaded(); err != nil {
-		return MetadataExtraction{}, err
-	}
-
-	cHTML := C.CString(html)
-	defer C.free(unsafe.Pointer(cHTML))
-
-	// Allocate output pointer for metadata JSON
-	var metadataPtr *C.char
-
-	result := C.html_to_markdown_convert_with_metadata_proxy(cHTML, &metadataPtr) // nolint:gocritic
-	if result == nil {
-		errMsg := C.html_to_markdown_last_error_proxy()
-		if errMsg != nil {
-			return MetadataExtraction{}, errors.New(C.GoString(errMsg))
-		}
-		return MetadataExtraction{}, errors.New("html to markdown conversion with metadata failed")
-	}
-
-	defer C.html_to_markdown_free_string_proxy(result)
-
-	if metadataPtr != nil {
-		defer C.html_to_markdown_free_string_proxy(metadataPtr)
-	}
-
-	markdown := C.GoString(result)
-
-	// Parse metadata JSON if available
-	var metadata ExtendedMetadata
-	if metadataPtr != nil {
-		metadataJSON := C.GoString(metadataPtr)
-		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
-			return MetadataExtraction{}, errors.New("failed to parse metadata JSON: " + err.Error())
-		}
-	}
-
-	return MetadataExtraction{
-		Markdown: markdown,
-		Metadata: metadata,
-	}, nil
-}
-
 // MustConvertWithMetadata is like ConvertWithMetadata but panics if an error occurs.
 //
 // This is useful in situations where metadata extraction errors are unexpected
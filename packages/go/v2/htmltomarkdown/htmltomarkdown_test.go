@@ -1,7 +1,12 @@
+//go:build cgo
+
 package htmltomarkdown
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -105,6 +110,60 @@ func TestVersion(t *testing.T) {
 	t.Logf("Library version: %s", version)
 }
 
+func TestVersionInfo(t *testing.T) {
+	info, err := VersionInfo()
+	if err != nil {
+		t.Fatalf("VersionInfo() failed: %v", err)
+	}
+	if info.Semver != Version() {
+		t.Errorf("VersionInfo().Semver = %s, expected %s", info.Semver, Version())
+	}
+	if info.Major == 0 {
+		t.Error("VersionInfo().Major should be non-zero")
+	}
+	if info.Minor == 0 && info.Patch == 0 {
+		t.Errorf("VersionInfo() minor/patch = %d.%d, expected a non-zero component", info.Minor, info.Patch)
+	}
+	if info.ABIVersion == 0 {
+		t.Error("VersionInfo().ABIVersion should be non-zero")
+	}
+}
+
+func TestParseLibraryVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LibraryVersion
+		wantErr bool
+	}{
+		{
+			input: "2.19.1",
+			want:  LibraryVersion{Semver: "2.19.1", Major: 2, Minor: 19, Patch: 1, ABIVersion: 2},
+		},
+		{
+			input: "2.20.0-rc.1",
+			want:  LibraryVersion{Semver: "2.20.0-rc.1", Major: 2, Minor: 20, Patch: 0, ABIVersion: 2},
+		},
+		{
+			input: "3.0.4+build.7",
+			want:  LibraryVersion{Semver: "3.0.4+build.7", Major: 3, Minor: 0, Patch: 4, ABIVersion: 3},
+		},
+		{input: "unknown", wantErr: true},
+		{input: "2.19", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseLibraryVersion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLibraryVersion(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseLibraryVersion(%q) = %+v, expected %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func BenchmarkConvert(b *testing.B) {
 	html := `
 		<html>
@@ -160,6 +219,30 @@ func ExampleVersion() {
 	println("html-to-markdown version:", version)
 }
 
+func TestConvertWithStats(t *testing.T) {
+	page := `<h2>Section</h2><p>Some <em>text</em> with <a href="/x">a link</a>.</p>`
+	_, small, err := ConvertWithStats(strings.Repeat(page, 10))
+	if errors.Is(err, errNoAllocationStats) {
+		t.Skip("native library does not report allocation statistics")
+	}
+	if err != nil {
+		t.Fatalf("ConvertWithStats failed: %v", err)
+	}
+	markdown, large, err := ConvertWithStats(strings.Repeat(page, 1000))
+	if err != nil {
+		t.Fatalf("ConvertWithStats failed: %v", err)
+	}
+	if !strings.Contains(markdown, "## Section") {
+		t.Errorf("ConvertWithStats() = %q, expected the converted document", markdown)
+	}
+	if small.PeakBytes == 0 {
+		t.Error("PeakBytes should be positive for a non-trivial document")
+	}
+	if large.PeakBytes <= small.PeakBytes {
+		t.Errorf("PeakBytes = %d for 100x the input, expected more than %d", large.PeakBytes, small.PeakBytes)
+	}
+}
+
 func TestConvertWithMetadata(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -369,8 +452,8 @@ func TestMustConvertWithMetadata(t *testing.T) {
 	})
 }
 
-func TestMetadataJSONMarshaling(t *testing.T) {
-	html := `<html>
+// jsonMetadataPage has a title, a description, a header and a link.
+const jsonMetadataPage = `<html>
 		<head>
 			<title>JSON Test</title>
 			<meta name="description" content="Test">
@@ -381,7 +464,8 @@ func TestMetadataJSONMarshaling(t *testing.T) {
 		</body>
 	</html>`
 
-	result, err := ConvertWithMetadata(html)
+func TestMetadataJSONMarshaling(t *testing.T) {
+	result, err := ConvertWithMetadata(jsonMetadataPage)
 	if err != nil {
 		t.Fatalf("ConvertWithMetadata failed: %v", err)
 	}
@@ -412,8 +496,8 @@ func TestMetadataJSONMarshaling(t *testing.T) {
 	}
 }
 
-func TestHeaderMetadataValidation(t *testing.T) {
-	html := `<html>
+// headersPage has a header of every level.
+const headersPage = `<html>
 		<body>
 			<h1>H1 Title</h1>
 			<h2>H2 Title</h2>
@@ -424,7 +508,8 @@ func TestHeaderMetadataValidation(t *testing.T) {
 		</body>
 	</html>`
 
-	result, err := ConvertWithMetadata(html)
+func TestHeaderMetadataValidation(t *testing.T) {
+	result, err := ConvertWithMetadata(headersPage)
 	if err != nil {
 		t.Fatalf("ConvertWithMetadata failed: %v", err)
 	}
@@ -441,8 +526,8 @@ func TestHeaderMetadataValidation(t *testing.T) {
 	}
 }
 
-func TestLinkTypeClassification(t *testing.T) {
-	html := `<html>
+// linkTypesPage has a link of every LinkType.
+const linkTypesPage = `<html>
 		<body>
 			<a href="https://example.com">External</a>
 			<a href="http://example.com">HTTP External</a>
@@ -454,7 +539,8 @@ func TestLinkTypeClassification(t *testing.T) {
 		</body>
 	</html>`
 
-	result, err := ConvertWithMetadata(html)
+func TestLinkTypeClassification(t *testing.T) {
+	result, err := ConvertWithMetadata(linkTypesPage)
 	if err != nil {
 		t.Fatalf("ConvertWithMetadata failed: %v", err)
 	}
@@ -481,8 +567,8 @@ func TestLinkTypeClassification(t *testing.T) {
 	}
 }
 
-func TestImageTypeClassification(t *testing.T) {
-	html := `<html>
+// imageTypesPage has external and relative images.
+const imageTypesPage = `<html>
 		<body>
 			<img src="https://example.com/image.jpg" alt="External">
 			<img src="http://cdn.example.com/image.png" alt="HTTP External">
@@ -491,7 +577,8 @@ func TestImageTypeClassification(t *testing.T) {
 		</body>
 	</html>`
 
-	result, err := ConvertWithMetadata(html)
+func TestImageTypeClassification(t *testing.T) {
+	result, err := ConvertWithMetadata(imageTypesPage)
 	if err != nil {
 		t.Fatalf("ConvertWithMetadata failed: %v", err)
 	}
@@ -509,6 +596,209 @@ func TestImageTypeClassification(t *testing.T) {
 	}
 }
 
+func TestExtractMetadata(t *testing.T) {
+	html := `<html lang="en" dir="RTL">
+<head>
+<title>Sample Page</title>
+<meta name="description" content="A sample page">
+<meta name="keywords" content="go, html, , markdown">
+<meta property="og:image:width" content="640">
+<meta name="twitter:card" content="summary">
+<meta name="viewport" content="width=device-width">
+<link rel="canonical" href="https://example.com/sample">
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Article"}</script>
+</head>
+<body>
+<h1 id="top">Sample Page</h1>
+<p><a href="https://example.com" rel="nofollow noopener" class="ext">External</a>
+<a href="#top">Back</a> <a href="/docs">Docs</a> <a href="mailto:me@example.com">Mail</a></p>
+<img src="logo.png" alt="Logo" width="64" height="32">
+<img src="https://cdn.example.com/a.png" alt="">
+<script type="application/ld+json">{"@graph": [{"name": "x"}, {"@type": "Person"}]}</script>
+</body>
+</html>`
+
+	metadata, err := ExtractMetadata(html)
+	if err != nil {
+		t.Fatalf("ExtractMetadata failed: %v", err)
+	}
+
+	doc := metadata.Document
+	if doc.Title == nil || *doc.Title != "Sample Page" {
+		t.Errorf("Title = %v, expected %q", doc.Title, "Sample Page")
+	}
+	if doc.Description == nil || *doc.Description != "A sample page" {
+		t.Errorf("Description = %v, expected %q", doc.Description, "A sample page")
+	}
+	if !reflect.DeepEqual(doc.Keywords, []string{"go", "html", "markdown"}) {
+		t.Errorf("Keywords = %q", doc.Keywords)
+	}
+	if doc.CanonicalURL == nil || *doc.CanonicalURL != "https://example.com/sample" {
+		t.Errorf("CanonicalURL = %v", doc.CanonicalURL)
+	}
+	if doc.Language == nil || *doc.Language != "en" {
+		t.Errorf("Language = %v, expected en", doc.Language)
+	}
+	if doc.TextDirection == nil || *doc.TextDirection != TextDirectionRTL {
+		t.Errorf("TextDirection = %v, expected rtl", doc.TextDirection)
+	}
+	if doc.OpenGraph["image_width"] != "640" || doc.TwitterCard["card"] != "summary" {
+		t.Errorf("OpenGraph = %v, TwitterCard = %v", doc.OpenGraph, doc.TwitterCard)
+	}
+	if doc.MetaTags["viewport"] != "width=device-width" {
+		t.Errorf("MetaTags = %v, expected the viewport", doc.MetaTags)
+	}
+
+	if len(metadata.Headers) != 1 {
+		t.Fatalf("Headers = %+v, expected one header", metadata.Headers)
+	}
+	header := metadata.Headers[0]
+	if header.Level != 1 || header.Text != "Sample Page" || header.ID == nil || *header.ID != "top" {
+		t.Errorf("Header = %+v", header)
+	}
+
+	var types []LinkType
+	for _, link := range metadata.Links {
+		types = append(types, link.LinkType)
+	}
+	expectedTypes := []LinkType{LinkTypeExternal, LinkTypeAnchor, LinkTypeInternal, LinkTypeEmail}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Errorf("Link types = %v, expected %v", types, expectedTypes)
+	}
+	external := metadata.Links[0]
+	if !reflect.DeepEqual(external.Rel, []string{"nofollow", "noopener"}) || external.Attributes["class"] != "ext" {
+		t.Errorf("External link = %+v", external)
+	}
+
+	if len(metadata.Images) != 2 {
+		t.Fatalf("Images = %+v, expected two images", metadata.Images)
+	}
+	logo, cdn := metadata.Images[0], metadata.Images[1]
+	if logo.ImageType != ImageTypeRelative || logo.Dimensions == nil || *logo.Dimensions != [2]uint32{64, 32} {
+		t.Errorf("Logo = %+v", logo)
+	}
+	if cdn.ImageType != ImageTypeExternal || cdn.Alt != nil {
+		t.Errorf("CDN image = %+v", cdn)
+	}
+
+	var schemas []string
+	for _, data := range metadata.StructuredData {
+		if data.SchemaType != nil {
+			schemas = append(schemas, *data.SchemaType)
+		}
+	}
+	if !reflect.DeepEqual(schemas, []string{"Article", "Person"}) {
+		t.Errorf("Schema types = %q, expected Article and Person", schemas)
+	}
+}
+
+func TestDocumentMetadataAccessors(t *testing.T) {
+	full := `<head>
+<title>Page title</title>
+<meta property="og:title" content="OG title">
+<meta property="og:description" content="OG description">
+<meta property="og:image" content="https://example.com/og.png">
+<meta property="og:url" content="https://example.com/page">
+<meta property="og:type" content="article">
+<meta property="og:site_name" content="Example">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="Twitter title">
+</head>`
+	metadata, err := ExtractMetadata(full)
+	if err != nil {
+		t.Fatalf("ExtractMetadata failed: %v", err)
+	}
+	doc := metadata.Document
+	for name, got := range map[string][2]string{
+		"OGTitle":            {doc.OGTitle(), "OG title"},
+		"OGDescription":      {doc.OGDescription(), "OG description"},
+		"OGImage":            {doc.OGImage(), "https://example.com/og.png"},
+		"OGURL":              {doc.OGURL(), "https://example.com/page"},
+		"OGType":             {doc.OGType(), "article"},
+		"OGSiteName":         {doc.OGSiteName(), "Example"},
+		"TwitterCardType":    {doc.TwitterCardType(), "summary_large_image"},
+		"TwitterTitle":       {doc.TwitterTitle(), "Twitter title"},
+		"TwitterDescription": {doc.TwitterDescription(), "OG description"},
+		"TwitterImage":       {doc.TwitterImage(), "https://example.com/og.png"},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s() = %q, expected %q", name, got[0], got[1])
+		}
+	}
+
+	partial := `<head>
+<title>Page title</title>
+<meta name="description" content="Page description">
+<link rel="canonical" href="https://example.com/canonical">
+</head>`
+	metadata, err = ExtractMetadata(partial)
+	if err != nil {
+		t.Fatalf("ExtractMetadata failed: %v", err)
+	}
+	doc = metadata.Document
+	for name, got := range map[string][2]string{
+		"OGTitle":         {doc.OGTitle(), "Page title"},
+		"OGDescription":   {doc.OGDescription(), "Page description"},
+		"OGURL":           {doc.OGURL(), "https://example.com/canonical"},
+		"OGImage":         {doc.OGImage(), ""},
+		"TwitterTitle":    {doc.TwitterTitle(), "Page title"},
+		"TwitterCardType": {doc.TwitterCardType(), ""},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s() = %q, expected %q", name, got[0], got[1])
+		}
+	}
+}
+
+// TestExtractMetadataMatchesConvertWithMetadata checks that ExtractMetadata
+// reports the same metadata as a full conversion.
+func TestExtractMetadataMatchesConvertWithMetadata(t *testing.T) {
+	pages := []struct {
+		name string
+		html string
+	}{
+		{"json", jsonMetadataPage},
+		{"headers", headersPage},
+		{"link types", linkTypesPage},
+		{"image types", imageTypesPage},
+		{"open graph images", ogImagesPage},
+	}
+	for _, page := range pages {
+		t.Run(page.name, func(t *testing.T) {
+			result, err := ConvertWithMetadata(page.html)
+			if err != nil {
+				t.Fatalf("ConvertWithMetadata failed: %v", err)
+			}
+			extracted, err := ExtractMetadata(page.html)
+			if err != nil {
+				t.Fatalf("ExtractMetadata failed: %v", err)
+			}
+			native, err := json.Marshal(result.Metadata)
+			if err != nil {
+				t.Fatalf("marshaling native metadata: %v", err)
+			}
+			got, err := json.Marshal(extracted)
+			if err != nil {
+				t.Fatalf("marshaling extracted metadata: %v", err)
+			}
+			if string(got) != string(native) {
+				t.Errorf("ExtractMetadata() = %s\nexpected ConvertWithMetadata().Metadata = %s", got, native)
+			}
+		})
+	}
+}
+
+func TestConvertWithMetadataOpenGraphImages(t *testing.T) {
+	result, err := ConvertWithMetadata(ogImagesPage)
+	if err != nil {
+		t.Fatalf("ConvertWithMetadata failed: %v", err)
+	}
+	images := result.Metadata.Document.OpenGraphImages
+	if len(images) != 2 || images[1].Alt != "Second image" || images[0].Width != 1200 {
+		t.Errorf("OpenGraphImages = %+v, expected both og:image entries", images)
+	}
+}
+
 func BenchmarkConvertWithMetadata(b *testing.B) {
 	html := `<html>
 		<head>
@@ -547,6 +837,43 @@ func BenchmarkConvertWithMetadataSimple(b *testing.B) {
 	}
 }
 
+// largeMetadataDocument returns a document with many sections, links and
+// images, for comparing ExtractMetadata with ConvertWithMetadata.
+func largeMetadataDocument() string {
+	var b strings.Builder
+	b.WriteString(`<html><head><title>Large</title><meta name="description" content="Many sections"></head><body>`)
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, `<h2 id="s%d">Section %d</h2><p>Text with <strong>bold</strong>, <a href="https://example.com/%d">a link</a>`+
+			` and <img src="img/%d.png" alt="Image %d">.</p><ul><li>One</li><li>Two</li></ul>`, i, i, i, i, i)
+	}
+	b.WriteString(`</body></html>`)
+	return b.String()
+}
+
+func BenchmarkConvertWithMetadataLarge(b *testing.B) {
+	html := largeMetadataDocument()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ConvertWithMetadata(html)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExtractMetadataLarge(b *testing.B) {
+	html := largeMetadataDocument()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ExtractMetadata(html)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func ExampleConvertWithMetadata() {
 	html := `<html>
 		<head>
@@ -578,3 +905,91 @@ func ExampleMustConvertWithMetadata() {
 	result := MustConvertWithMetadata("<h1>Title</h1>")
 	println("Markdown:", result.Markdown)
 }
+
+func TestConvert_Deterministic(t *testing.T) {
+	html := `<html>
+		<head>
+			<title>Determinism</title>
+			<meta property="og:title" content="OG Title">
+			<meta property="og:type" content="article">
+			<meta property="og:url" content="https://example.com/a">
+			<meta name="twitter:card" content="summary">
+			<meta name="twitter:site" content="@example">
+			<meta name="keywords" content="a, b, c">
+			<meta name="author" content="Someone">
+		</head>
+		<body>
+			<h1 id="top">Title</h1>
+			<p>Text with <a href="https://example.com" rel="nofollow" target="_blank" data-x="1">a link</a>.</p>
+			<img src="a.png" alt="A" width="10" height="20" loading="lazy">
+			<table><tr><th align="right">N</th><th>M</th></tr><tr><td>1</td><td>2</td></tr></table>
+		</body>
+	</html>`
+	opts := ConversionOptions{ListSpacing: ListSpacingLoose, EscapeMode: EscapeModeSmart, Footnotes: true}
+
+	const iterations = 50
+	var firstMarkdown, firstOptions, firstJSON string
+	for i := 0; i < iterations; i++ {
+		markdown, err := Convert(html)
+		if err != nil {
+			t.Fatalf("Convert failed: %v", err)
+		}
+		withOptions, err := ConvertWithOptions(html, opts)
+		if err != nil {
+			t.Fatalf("ConvertWithOptions failed: %v", err)
+		}
+		result, err := ConvertWithMetadata(html)
+		if err != nil {
+			t.Fatalf("ConvertWithMetadata failed: %v", err)
+		}
+		jsonData, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("Failed to marshal metadata to JSON: %v", err)
+		}
+
+		if i == 0 {
+			firstMarkdown, firstOptions, firstJSON = markdown, withOptions, string(jsonData)
+			continue
+		}
+		if markdown != firstMarkdown {
+			t.Fatalf("Convert output changed on iteration %d:\n%q\nvs\n%q", i, markdown, firstMarkdown)
+		}
+		if withOptions != firstOptions {
+			t.Fatalf("ConvertWithOptions output changed on iteration %d:\n%q\nvs\n%q", i, withOptions, firstOptions)
+		}
+		if string(jsonData) != firstJSON {
+			t.Fatalf("ConvertWithMetadata JSON changed on iteration %d:\n%s\nvs\n%s", i, jsonData, firstJSON)
+		}
+	}
+}
+
+func TestConvertWithFrontmatter(t *testing.T) {
+	html := `<html><head><title>Notes: a "quick" start</title>` +
+		`<meta name="description" content="Getting started">` +
+		`<meta name="keywords" content="go, markdown">` +
+		`<link rel="canonical" href="https://example.com/notes"></head>` +
+		`<body><h1>Notes</h1><p>Body text.</p></body></html>`
+
+	result, err := ConvertWithFrontmatter(html, []string{"title", "canonical", "keywords", "author"}, FrontmatterFormatYAML)
+	if err != nil {
+		t.Fatalf("ConvertWithFrontmatter failed: %v", err)
+	}
+	block, body, ok := strings.Cut(strings.TrimPrefix(result, "---\n"), "\n---\n")
+	if !strings.HasPrefix(result, "---\n") || !ok {
+		t.Fatalf("Result = %q, expected a leading frontmatter block", result)
+	}
+	expected := "title: \"Notes: a \\\"quick\\\" start\"\ncanonical: \"https://example.com/notes\"\nkeywords:\n  - \"go\"\n  - \"markdown\""
+	if block != expected {
+		t.Errorf("frontmatter = %q, expected %q", block, expected)
+	}
+	if !strings.Contains(body, "Body text.") {
+		t.Errorf("body = %q, expected the converted document", body)
+	}
+
+	if _, err := ConvertWithFrontmatter(html, []string{"date"}, ""); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := ConvertWithFrontmatter(html, []string{"title"}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,180 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// srcsetCandidate is one image candidate of a srcset attribute.
+type srcsetCandidate struct {
+	url        string
+	descriptor string
+}
+
+// parseSrcset splits a srcset attribute into its candidates.
+func parseSrcset(srcset string) []srcsetCandidate {
+	var candidates []srcsetCandidate
+	for _, entry := range strings.Split(srcset, ",") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		c := srcsetCandidate{url: fields[0]}
+		if len(fields) > 1 {
+			c.descriptor = fields[1]
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// normalizePictures replaces every <picture> with a single <img>. The
+// fallback <img> src is used when present, otherwise the first <source>
+// candidate, so responsive images are not dropped.
+func normalizePictures(root *htmlNode) {
+	for _, picture := range root.findAll("picture") {
+		var img, source *htmlNode
+		for _, c := range picture.children {
+			if c.typ != htmlElementNode {
+				continue
+			}
+			switch {
+			case c.tag == "img" && img == nil:
+				img = c
+			case c.tag == "source" && source == nil && sourceCandidateURL(c) != "":
+				source = c
+			}
+		}
+		if img == nil {
+			img = newElementNode("img")
+		}
+		if strings.TrimSpace(img.attrOr("src", "")) == "" && source != nil {
+			img.setAttr("src", sourceCandidateURL(source))
+			if _, ok := img.attr("srcset"); !ok {
+				if srcset, ok := source.attr("srcset"); ok {
+					img.setAttr("srcset", srcset)
+				}
+			}
+		}
+		picture.replaceWith(img)
+	}
+}
+
+func sourceCandidateURL(source *htmlNode) string {
+	if candidates := parseSrcset(source.attrOr("srcset", "")); len(candidates) > 0 {
+		return candidates[0].url
+	}
+	return strings.TrimSpace(source.attrOr("src", ""))
+}
+
+// hasPicture reports whether root holds a <picture> element.
+func hasPicture(root *htmlNode) bool {
+	return len(root.findAll("picture")) > 0
+}
+
+// candidateSize returns the numeric value and unit of a descriptor such as
+// "2x" or "640w". A missing descriptor means "1x".
+func (c srcsetCandidate) candidateSize() (float64, byte) {
+	if c.descriptor == "" {
+		return 1, 'x'
+	}
+	unit := c.descriptor[len(c.descriptor)-1]
+	value, err := strconv.ParseFloat(c.descriptor[:len(c.descriptor)-1], 64)
+	if err != nil || (unit != 'x' && unit != 'w') {
+		return 0, 0
+	}
+	return value, unit
+}
+
+// selectSrcsetCandidate returns the URL of the largest or smallest candidate.
+// Width descriptors are preferred over densities when both are present.
+func selectSrcsetCandidate(candidates []srcsetCandidate, selection SrcsetSelection) string {
+	best := ""
+	var bestValue float64
+	var bestUnit byte
+	for _, candidate := range candidates {
+		value, unit := candidate.candidateSize()
+		if unit == 0 {
+			continue
+		}
+		switch {
+		case best == "", unit == 'w' && bestUnit == 'x':
+			// First usable candidate, or the first width after densities.
+		case unit != bestUnit:
+			continue
+		case selection == SrcsetSelectionHighest && value <= bestValue:
+			continue
+		case selection == SrcsetSelectionLowest && value >= bestValue:
+			continue
+		}
+		best, bestValue, bestUnit = candidate.url, value, unit
+	}
+	return best
+}
+
+// selectImageSources sets the src of every <img> with a srcset to the
+// candidate chosen by selection.
+func selectImageSources(root *htmlNode, selection SrcsetSelection) {
+	for _, img := range root.findAll("img") {
+		if url := selectSrcsetCandidate(parseSrcset(img.attrOr("srcset", "")), selection); url != "" {
+			img.setAttr("src", url)
+		}
+	}
+}
+
+// defaultLazyLoadAttrs are the attributes lazy-loading scripts commonly read
+// the real image URL from.
+var defaultLazyLoadAttrs = []string{"data-src", "data-original"}
+
+// applyLazyLoadAttrs sets the src of every <img> carrying one of attrs to
+// that attribute's value, replacing the placeholder src.
+func applyLazyLoadAttrs(root *htmlNode, attrs []string) {
+	for _, img := range root.findAll("img") {
+		for _, key := range attrs {
+			if url := strings.TrimSpace(img.attrOr(key, "")); url != "" {
+				img.setAttr("src", url)
+				break
+			}
+		}
+	}
+}
+
+// hasLazyLoadImages reports whether root holds an <img> whose src
+// applyLazyLoadAttrs would replace.
+func hasLazyLoadImages(root *htmlNode, attrs []string) bool {
+	for _, img := range root.findAll("img") {
+		for _, key := range attrs {
+			if url := strings.TrimSpace(img.attrOr(key, "")); url != "" {
+				if url != img.attrOr("src", "") {
+					return true
+				}
+				break
+			}
+		}
+	}
+	return false
+}
+
+// replaceImagesWithAlt replaces every <img> with its alt text as plain text.
+// Images without alt text are removed.
+func replaceImagesWithAlt(root *htmlNode) {
+	for _, img := range root.findAll("img") {
+		alt := strings.TrimSpace(img.attrOr("alt", ""))
+		if alt == "" {
+			img.remove()
+			continue
+		}
+		img.replaceWith(&htmlNode{typ: htmlTextNode, data: html.EscapeString(alt)})
+	}
+}
+
+// dropEmptyImages removes the images without a src, which would render as
+// ![](). Images with a src but no alt text are kept.
+func dropEmptyImages(root *htmlNode) {
+	for _, img := range root.findAll("img") {
+		if strings.TrimSpace(img.attrOr("src", "")) == "" {
+			img.remove()
+		}
+	}
+}
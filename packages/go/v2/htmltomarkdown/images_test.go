@@ -0,0 +1,189 @@
+//go:build cgo
+
+package htmltomarkdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSrcset(t *testing.T) {
+	got := parseSrcset(" small.jpg 480w, large.jpg 1080w ,plain.jpg")
+	want := []srcsetCandidate{
+		{url: "small.jpg", descriptor: "480w"},
+		{url: "large.jpg", descriptor: "1080w"},
+		{url: "plain.jpg"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSrcset() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvert_Picture(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name: "img fallback",
+			html: `<picture>
+<source srcset="photo.avif" type="image/avif">
+<source srcset="photo.webp 1x, photo@2x.webp 2x" type="image/webp">
+<img src="photo.jpg" alt="Photo">
+</picture>`,
+			expected: "![Photo](photo.jpg)",
+		},
+		{
+			name:     "first source without img src",
+			html:     `<picture><source srcset="first.webp 1x, first@2x.webp 2x"><source srcset="second.webp"><img alt="Photo"></picture>`,
+			expected: "![Photo](first.webp)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasPicture(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected bool
+	}{
+		{`<p><picture><img src="a.jpg"></picture></p>`, true},
+		{`<!-- <picture> --><p>Use <code>&lt;picture&gt;</code> here.</p>`, false},
+		{`<script>document.write("<picture>")</script>`, false},
+	}
+	for _, tt := range tests {
+		if got := hasPicture(parseHTML(tt.html)); got != tt.expected {
+			t.Errorf("hasPicture(%q) = %v, expected %v", tt.html, got, tt.expected)
+		}
+	}
+}
+
+func TestSelectSrcsetCandidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		srcset    string
+		selection SrcsetSelection
+		want      string
+	}{
+		{name: "highest density", srcset: "a.jpg 1x, c.jpg 3x, b.jpg 2x", selection: SrcsetSelectionHighest, want: "c.jpg"},
+		{name: "lowest density", srcset: "b.jpg 2x, a.jpg", selection: SrcsetSelectionLowest, want: "a.jpg"},
+		{name: "highest width", srcset: "s.jpg 320w, l.jpg 1280w, m.jpg 640w", selection: SrcsetSelectionHighest, want: "l.jpg"},
+		{name: "lowest width", srcset: "m.jpg 640w, s.jpg 320w", selection: SrcsetSelectionLowest, want: "s.jpg"},
+		{name: "widths preferred over densities", srcset: "x.jpg 3x, w.jpg 100w", selection: SrcsetSelectionHighest, want: "w.jpg"},
+		{name: "invalid descriptors", srcset: "a.jpg huge", selection: SrcsetSelectionHighest, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectSrcsetCandidate(parseSrcset(tt.srcset), tt.selection); got != tt.want {
+				t.Errorf("selectSrcsetCandidate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_SrcsetSelection(t *testing.T) {
+	html := `<img src="a.jpg" srcset="a.jpg 1x, b.jpg 2x" alt="Pic">`
+
+	tests := []struct {
+		selection SrcsetSelection
+		expected  string
+	}{
+		{selection: "", expected: "![Pic](a.jpg)"},
+		{selection: SrcsetSelectionSrc, expected: "![Pic](a.jpg)"},
+		{selection: SrcsetSelectionHighest, expected: "![Pic](b.jpg)"},
+		{selection: SrcsetSelectionLowest, expected: "![Pic](a.jpg)"},
+	}
+
+	for _, tt := range tests {
+		result, err := ConvertWithOptions(html, ConversionOptions{SrcsetSelection: tt.selection})
+		if err != nil {
+			t.Fatalf("ConvertWithOptions failed: %v", err)
+		}
+		if !strings.Contains(result, tt.expected) {
+			t.Errorf("SrcsetSelection %q: result = %q, expected to contain %q", tt.selection, result, tt.expected)
+		}
+	}
+}
+
+func TestHasLazyLoadImages(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected bool
+	}{
+		{`<img src="placeholder.gif" data-src="real.jpg">`, true},
+		{`<img data-original="real.jpg">`, true},
+		{`<img src="real.jpg" data-src="real.jpg">`, false},
+		{`<p>Set data-src or data-original on the image.</p><div data-src="x"></div>`, false},
+	}
+	for _, tt := range tests {
+		if got := hasLazyLoadImages(parseHTML(tt.html), defaultLazyLoadAttrs); got != tt.expected {
+			t.Errorf("hasLazyLoadImages(%q) = %v, expected %v", tt.html, got, tt.expected)
+		}
+	}
+}
+
+func TestConvertWithOptions_LazyLoadAttrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		attrs    []string
+		expected string
+	}{
+		{
+			name:     "data-src by default",
+			html:     `<img src="placeholder.gif" data-src="real.jpg" alt="Real">`,
+			expected: "![Real](real.jpg)",
+		},
+		{
+			name:     "data-original by default",
+			html:     `<img src="placeholder.gif" data-original="original.jpg" alt="Real">`,
+			expected: "![Real](original.jpg)",
+		},
+		{
+			name:     "custom attribute",
+			html:     `<img src="placeholder.gif" data-lazy="lazy.jpg" data-src="real.jpg" alt="Real">`,
+			attrs:    []string{"data-lazy"},
+			expected: "![Real](lazy.jpg)",
+		},
+		{
+			name:     "disabled",
+			html:     `<img src="placeholder.gif" data-src="real.jpg" alt="Real">`,
+			attrs:    []string{},
+			expected: "![Real](placeholder.gif)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{LazyLoadAttrs: tt.attrs})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+
+	result, err := Convert(`<img src="placeholder.gif" data-src="real.jpg" alt="Real">`)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if !strings.Contains(result, "![Real](real.jpg)") {
+		t.Errorf("Convert result = %q, expected the lazy-loaded URL", result)
+	}
+}
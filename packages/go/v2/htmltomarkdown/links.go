@@ -0,0 +1,188 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// applyLinkStyle rewrites every <a href> for style. LinkStyleTextOnly replaces
+// the link with its content, so image links keep their image and links
+// without content disappear. LinkStyleTextWithURL follows the content with
+// the URL in parentheses, omitted for internal links when omitInternal is set.
+func applyLinkStyle(root *htmlNode, style LinkStyle, omitInternal bool) {
+	for _, a := range root.findAll("a") {
+		href, ok := a.attr("href")
+		if !ok {
+			continue
+		}
+		text := strings.TrimSpace(a.normalizedText())
+		hasContent := text != "" || len(a.findAll("img")) > 0
+
+		if style == LinkStyleTextOnly || href == "" || (omitInternal && !isExternalURL(href)) {
+			if hasContent {
+				a.replaceWith(a.children...)
+			} else {
+				a.remove()
+			}
+			continue
+		}
+
+		switch {
+		case !hasContent:
+			a.replaceWith(&htmlNode{typ: htmlTextNode, data: html.EscapeString(href)})
+		case text == href || text == strings.TrimPrefix(href, "mailto:"):
+			a.replaceWith(a.children...)
+		default:
+			suffix := &htmlNode{typ: htmlTextNode, data: " (" + html.EscapeString(href) + ")"}
+			a.replaceWith(append(a.children, suffix)...)
+		}
+	}
+}
+
+// isExternalURL reports whether href points outside the current document
+// and site: an absolute URL with a scheme or a protocol-relative URL.
+func isExternalURL(href string) bool {
+	if strings.HasPrefix(href, "//") {
+		return true
+	}
+	u, err := url.Parse(href)
+	return err == nil && u.Scheme != ""
+}
+
+// trimLinkText removes the whitespace at the edges of link content and
+// collapses the whitespace in image alt text, so that neither leaves spaces
+// inside the brackets. Trimming stops at an image or line break, which count
+// as content.
+func trimLinkText(root *htmlNode) {
+	for _, a := range root.findAll("a") {
+		a.trimEdgeSpace()
+	}
+	for _, img := range root.findAll("img") {
+		if alt, ok := img.attr("alt"); ok {
+			img.setAttr("alt", strings.Join(strings.FieldsFunc(alt, isCollapsibleSpace), " "))
+		}
+	}
+}
+
+// liftBlockLinks rewrites the links wrapping block elements, such as a card
+// <a><h3>Title</h3><p>Summary</p></a>, which have no inline Markdown
+// form. BlockInLinkModeLift keeps the blocks and repeats the link inside each
+// of them; BlockInLinkModeInlineText flattens the blocks into a single inline
+// link.
+func liftBlockLinks(root *htmlNode, mode BlockInLinkMode) {
+	links := root.findAll("a")
+	for i := len(links) - 1; i >= 0; i-- {
+		a := links[i]
+		if !hasBlockChild(a) {
+			continue
+		}
+		if mode == BlockInLinkModeInlineText {
+			a.children = flattenBlocks(a.children)
+			for _, c := range a.children {
+				c.parent = a
+			}
+			continue
+		}
+		a.replaceWith(liftLink(a, a.children)...)
+	}
+}
+
+func hasBlockChild(n *htmlNode) bool {
+	for _, c := range n.children {
+		if c.typ == htmlElementNode && fallbackIsBlock(c.tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// liftLink returns nodes with each run of inline content wrapped in a copy
+// of the link a. Block elements are kept and their content is lifted in turn;
+// whitespace between blocks is left unwrapped.
+func liftLink(a *htmlNode, nodes []*htmlNode) []*htmlNode {
+	var lifted, run []*htmlNode
+	flush := func() {
+		if !hasInlineContent(run) {
+			lifted = append(lifted, run...)
+		} else {
+			link := newElementNode("a", append([]htmlAttr(nil), a.attrs...)...)
+			for _, n := range run {
+				link.appendChild(n)
+			}
+			lifted = append(lifted, link)
+		}
+		run = nil
+	}
+	for _, n := range nodes {
+		if n.typ != htmlElementNode || !fallbackIsBlock(n.tag) {
+			run = append(run, n)
+			continue
+		}
+		flush()
+		children := liftLink(a, n.children)
+		n.children = nil
+		for _, c := range children {
+			n.appendChild(c)
+		}
+		lifted = append(lifted, n)
+	}
+	flush()
+	return lifted
+}
+
+// hasInlineContent reports whether nodes hold text or an image.
+func hasInlineContent(nodes []*htmlNode) bool {
+	for _, n := range nodes {
+		switch n.typ {
+		case htmlTextNode:
+			if strings.TrimSpace(html.UnescapeString(n.data)) != "" {
+				return true
+			}
+		case htmlElementNode:
+			if n.tag == "img" || strings.TrimSpace(n.normalizedText()) != "" || len(n.findAll("img")) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flattenBlocks replaces the block elements among nodes and their
+// descendants with their content, separated from the surrounding text by
+// spaces.
+func flattenBlocks(nodes []*htmlNode) []*htmlNode {
+	var flat []*htmlNode
+	for _, n := range nodes {
+		if n.typ != htmlElementNode {
+			flat = append(flat, n)
+			continue
+		}
+		children := flattenBlocks(n.children)
+		if !fallbackIsBlock(n.tag) {
+			n.children = nil
+			for _, c := range children {
+				n.appendChild(c)
+			}
+			flat = append(flat, n)
+			continue
+		}
+		flat = append(flat, &htmlNode{typ: htmlTextNode, data: " "})
+		flat = append(flat, children...)
+		flat = append(flat, &htmlNode{typ: htmlTextNode, data: " "})
+	}
+	return flat
+}
+
+// dropEmptyLinks removes the links without text, image or title, which
+// would render as [](url).
+func dropEmptyLinks(root *htmlNode) {
+	for _, a := range root.findAll("a") {
+		if _, ok := a.attr("href"); !ok || a.attrOr("title", "") != "" {
+			continue
+		}
+		if !hasInlineContent(a.children) {
+			a.remove()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package htmltomarkdown
+
+import "strings"
+
+// isListBlock reports whether an element inside a list item starts block
+// content that makes its list loose. Nested lists do not.
+func isListBlock(n *htmlNode) bool {
+	return n.typ == htmlElementNode && paragraphClosers[n.tag] && n.tag != "ul" && n.tag != "ol" && n.tag != "hr"
+}
+
+// listItems returns the <li> children of a list.
+func listItems(list *htmlNode) []*htmlNode {
+	var items []*htmlNode
+	for _, c := range list.children {
+		if c.typ == htmlElementNode && c.tag == "li" {
+			items = append(items, c)
+		}
+	}
+	return items
+}
+
+// applyListSpacing rewrites list items so the native renderer lays every list
+// out with the requested spacing. The native library renders a list loose as
+// soon as one of its items holds a paragraph, so tight lists unwrap the single
+// paragraph of simple items and loose lists wrap the inline content of every
+// item in one.
+func applyListSpacing(root *htmlNode, spacing ListSpacing) {
+	for _, list := range root.findAll("ul", "ol") {
+		items := listItems(list)
+		switch spacing {
+		case ListSpacingTight:
+			for _, li := range items {
+				unwrapSimpleItem(li)
+			}
+		case ListSpacingLoose:
+			if !hasBlockItem(items) {
+				continue
+			}
+			for _, li := range items {
+				wrapInlineRuns(li)
+			}
+		}
+	}
+}
+
+func hasBlockItem(items []*htmlNode) bool {
+	for _, li := range items {
+		for _, c := range li.children {
+			if isListBlock(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unwrapSimpleItem replaces the paragraph of an item whose only block content
+// is a single <p> with the paragraph's children.
+func unwrapSimpleItem(li *htmlNode) {
+	var para *htmlNode
+	for _, c := range li.children {
+		if !isListBlock(c) {
+			continue
+		}
+		if c.tag != "p" || para != nil {
+			return
+		}
+		para = c
+	}
+	if para != nil {
+		para.replaceWith(para.children...)
+	}
+}
+
+// wrapInlineRuns moves each run of inline content of li into its own <p>.
+func wrapInlineRuns(li *htmlNode) {
+	children := li.children
+	li.children = nil
+	var run []*htmlNode
+	flush := func() {
+		if isBlankRun(run) {
+			for _, n := range run {
+				li.appendChild(n)
+			}
+		} else {
+			para := newElementNode("p")
+			for _, n := range run {
+				para.appendChild(n)
+			}
+			li.appendChild(para)
+		}
+		run = nil
+	}
+	for _, c := range children {
+		if c.typ == htmlElementNode && !isInlineTag(c.tag) {
+			flush()
+			li.appendChild(c)
+			continue
+		}
+		run = append(run, c)
+	}
+	flush()
+}
+
+// isBlankRun reports whether run holds nothing but whitespace and comments.
+func isBlankRun(run []*htmlNode) bool {
+	for _, n := range run {
+		switch n.typ {
+		case htmlTextNode:
+			if strings.TrimSpace(n.data) != "" {
+				return false
+			}
+		case htmlElementNode:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// texSymbols maps operators and identifiers to their LaTeX commands.
+var texSymbols = map[string]string{
+	"×": `\times`, "÷": `\div`, "·": `\cdot`, "±": `\pm`, "∓": `\mp`,
+	"≤": `\leq`, "≥": `\geq`, "≠": `\neq`, "≈": `\approx`, "≡": `\equiv`,
+	"→": `\to`, "←": `\leftarrow`, "⇒": `\Rightarrow`, "⇔": `\Leftrightarrow`,
+	"∞": `\infty`, "∑": `\sum`, "∏": `\prod`, "∫": `\int`, "∂": `\partial`,
+	"∇": `\nabla`, "∈": `\in`, "∉": `\notin`, "⊂": `\subset`, "⊆": `\subseteq`,
+	"∪": `\cup`, "∩": `\cap`, "∀": `\forall`, "∃": `\exists`, "′": `'`,
+	"α": `\alpha`, "β": `\beta`, "γ": `\gamma`, "δ": `\delta`, "ε": `\epsilon`,
+	"ζ": `\zeta`, "η": `\eta`, "θ": `\theta`, "κ": `\kappa`, "λ": `\lambda`,
+	"μ": `\mu`, "ν": `\nu`, "ξ": `\xi`, "π": `\pi`, "ρ": `\rho`, "σ": `\sigma`,
+	"τ": `\tau`, "φ": `\phi`, "χ": `\chi`, "ψ": `\psi`, "ω": `\omega`,
+	"Γ": `\Gamma`, "Δ": `\Delta`, "Θ": `\Theta`, "Λ": `\Lambda`, "Π": `\Pi`,
+	"Σ": `\Sigma`, "Φ": `\Phi`, "Ψ": `\Psi`, "Ω": `\Omega`,
+}
+
+// renderMath rewrites every MathML <math> element in the requested style.
+// LaTeX output is delimited by $...$, or by $$ lines for display="block".
+func renderMath(root *htmlNode, style MathStyle, fragments *fragmentSet) {
+	maths := root.findAll("math")
+	for i := len(maths) - 1; i >= 0; i-- {
+		n := maths[i]
+		if n.hasAncestor("math") {
+			continue
+		}
+		display := n.attrOr("display", "") == "block"
+		switch style {
+		case MathStyleDrop:
+			n.remove()
+		case MathStyleHTML:
+			n.replaceWith(fragments.node(fragments.restore(n.render()), !display))
+		case MathStyleLaTeX:
+			n.replaceWith(mathFragment(strings.TrimSpace(mathMLToTeX(n)), display, fragments))
+		}
+	}
+}
+
+// defaultMathClasses and defaultMathScriptTypes mark the TeX sources left in
+// the page by KaTeX and MathJax when MathStyle is set and no patterns are
+// configured.
+var (
+	defaultMathClasses     = []string{"math"}
+	defaultMathScriptTypes = []string{"math/tex"}
+)
+
+// renderTeXElements rewrites the elements holding TeX source: elements with
+// one of classes and <script> elements with one of scriptTypes. Display math
+// is recognized from a "mode=display" script type, a "display" class, a
+// <div> element or \[...\] and $$...$$ delimiters in the source.
+func renderTeXElements(root *htmlNode, style MathStyle, classes, scriptTypes []string, fragments *fragmentSet) {
+	var sources []*htmlNode
+	root.walk(func(n *htmlNode) bool {
+		if n.typ != htmlElementNode || n.hasAncestor("code", "pre") {
+			return true
+		}
+		if n.tag == "script" {
+			mediaType, _, _ := strings.Cut(n.attrOr("type", ""), ";")
+			if containsString(scriptTypes, strings.ToLower(strings.TrimSpace(mediaType))) {
+				sources = append(sources, n)
+			}
+			return false
+		}
+		for _, class := range classes {
+			if n.hasClass(class) {
+				sources = append(sources, n)
+				return false
+			}
+		}
+		return true
+	})
+
+	for _, n := range sources {
+		switch style {
+		case MathStyleDrop:
+			n.remove()
+		case MathStyleHTML:
+			n.replaceWith(fragments.node(fragments.restore(n.render()), true))
+		case MathStyleLaTeX:
+			tex := strings.TrimSpace(n.text())
+			if n.tag != "script" {
+				tex = strings.TrimSpace(html.UnescapeString(tex))
+			}
+			tex, delimitedDisplay := stripTeXDelimiters(tex)
+			display := delimitedDisplay || n.tag == "div" || n.hasClass("display") ||
+				strings.Contains(n.attrOr("type", ""), "mode=display")
+			if tex == "" {
+				n.remove()
+				continue
+			}
+			n.replaceWith(mathFragment(tex, display, fragments))
+		}
+	}
+}
+
+// texDelimiters are the math delimiters MathJax and KaTeX accept in source.
+var texDelimiters = []struct {
+	open, closing string
+	display       bool
+}{
+	{`\[`, `\]`, true},
+	{"$$", "$$", true},
+	{`\(`, `\)`, false},
+	{"$", "$", false},
+}
+
+// stripTeXDelimiters removes the math delimiters around tex, reporting
+// whether they mark display math.
+func stripTeXDelimiters(tex string) (string, bool) {
+	for _, d := range texDelimiters {
+		if len(tex) >= len(d.open)+len(d.closing) && strings.HasPrefix(tex, d.open) && strings.HasSuffix(tex, d.closing) {
+			return strings.TrimSpace(tex[len(d.open) : len(tex)-len(d.closing)]), d.display
+		}
+	}
+	return tex, false
+}
+
+// mathFragment returns the fragment holding tex with its math delimiters.
+func mathFragment(tex string, display bool, fragments *fragmentSet) *htmlNode {
+	if display {
+		return fragments.node("$$\n"+tex+"\n$$", false)
+	}
+	return fragments.node("$"+tex+"$", true)
+}
+
+// mathMLToTeX converts the common MathML presentation elements to LaTeX.
+// Unknown elements contribute the conversion of their children.
+func mathMLToTeX(n *htmlNode) string {
+	if n.typ == htmlTextNode {
+		return strings.TrimSpace(html.UnescapeString(n.data))
+	}
+	if n.typ != htmlElementNode {
+		return ""
+	}
+
+	args := mathArgs(n)
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	switch n.tag {
+	case "semantics":
+		for _, c := range n.children {
+			if c.typ == htmlElementNode && c.tag == "annotation" && c.attrOr("encoding", "") == "application/x-tex" {
+				return strings.TrimSpace(html.UnescapeString(c.text()))
+			}
+		}
+		return arg(0)
+	case "annotation", "annotation-xml":
+		return ""
+	case "mi", "mn", "mo":
+		text := strings.TrimSpace(html.UnescapeString(n.text()))
+		if symbol, ok := texSymbols[text]; ok {
+			return symbol
+		}
+		if n.tag == "mi" && len([]rune(text)) > 1 {
+			return `\mathrm{` + text + `}`
+		}
+		return text
+	case "mtext":
+		return `\text{` + html.UnescapeString(n.text()) + `}`
+	case "mspace":
+		return `\,`
+	case "mfrac":
+		return `\frac{` + arg(0) + `}{` + arg(1) + `}`
+	case "msup":
+		return texBase(arg(0)) + `^{` + arg(1) + `}`
+	case "msub":
+		return texBase(arg(0)) + `_{` + arg(1) + `}`
+	case "msubsup":
+		return texBase(arg(0)) + `_{` + arg(1) + `}^{` + arg(2) + `}`
+	case "munder":
+		return texBase(arg(0)) + `_{` + arg(1) + `}`
+	case "mover":
+		return texBase(arg(0)) + `^{` + arg(1) + `}`
+	case "munderover":
+		return texBase(arg(0)) + `_{` + arg(1) + `}^{` + arg(2) + `}`
+	case "msqrt":
+		return `\sqrt{` + strings.Join(args, " ") + `}`
+	case "mroot":
+		return `\sqrt[` + arg(1) + `]{` + arg(0) + `}`
+	case "mfenced":
+		open, closing := n.attrOr("open", "("), n.attrOr("close", ")")
+		return `\left` + open + strings.Join(args, n.attrOr("separators", ",")) + `\right` + closing
+	default:
+		return strings.Join(args, " ")
+	}
+}
+
+// mathArgs converts the element children of n, which MathML treats as the
+// arguments of layout elements such as mfrac and msup.
+func mathArgs(n *htmlNode) []string {
+	var args []string
+	for _, c := range n.children {
+		if c.typ == htmlElementNode {
+			args = append(args, mathMLToTeX(c))
+		} else if text := mathMLToTeX(c); text != "" {
+			args = append(args, text)
+		}
+	}
+	return args
+}
+
+// texBase wraps a multi-token base in braces so scripts apply to all of it.
+func texBase(base string) string {
+	if strings.ContainsAny(base, " ") {
+		return "{" + base + "}"
+	}
+	return base
+}
@@ -0,0 +1,159 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// addHeadMetadata fills the fields of doc that the native library does not
+// extract from the <head>.
+func addHeadMetadata(doc *DocumentMetadata, head *htmlNode) {
+	for _, n := range head.children {
+		if n.typ != htmlElementNode {
+			continue
+		}
+		switch n.tag {
+		case "title":
+			var raw strings.Builder
+			for _, c := range n.children {
+				if c.typ == htmlTextNode {
+					raw.WriteString(c.data)
+				}
+			}
+			if title := strings.Join(strings.Fields(raw.String()), " "); title != "" {
+				doc.TitleRaw = &title
+			}
+		case "meta":
+			property := strings.ToLower(n.attrOr("property", n.attrOr("name", "")))
+			switch {
+			case strings.HasPrefix(property, "og:image"):
+				doc.OpenGraphImages = addOGImageProperty(doc.OpenGraphImages, property, n.attrOr("content", ""))
+			case property == "robots", property == "googlebot":
+				if doc.Robots == nil {
+					doc.Robots = &RobotsDirectives{}
+				}
+				doc.Robots.add(n.attrOr("content", ""))
+			case strings.HasPrefix(property, "dc."), strings.HasPrefix(property, "dcterms."):
+				doc.addDublinCore(property, n.attrOr("content", ""))
+			}
+		case "link":
+			href, ok := n.attr("href")
+			if !ok || !hasRel(n, "alternate") {
+				continue
+			}
+			if hreflang, ok := n.attr("hreflang"); ok {
+				doc.AlternateLinks = append(doc.AlternateLinks, AlternateLink{Hreflang: hreflang, Href: href})
+			}
+			mediaType, _, _ := strings.Cut(n.attrOr("type", ""), ";")
+			if mediaType = strings.ToLower(strings.TrimSpace(mediaType)); feedTypes[mediaType] {
+				doc.FeedLinks = append(doc.FeedLinks, FeedLink{Title: n.attrOr("title", ""), Href: href, Type: mediaType})
+			}
+		}
+	}
+}
+
+// feedTypes are the media types of RSS and Atom feeds.
+var feedTypes = map[string]bool{"application/rss+xml": true, "application/atom+xml": true}
+
+// hasRel reports whether the rel attribute of n holds the link type rel.
+func hasRel(n *htmlNode, rel string) bool {
+	for _, r := range strings.Fields(n.attrOr("rel", "")) {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// add sets the directives listed in content.
+func (r *RobotsDirectives) add(content string) {
+	for _, directive := range strings.Split(content, ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "none":
+			r.NoIndex, r.NoFollow = true, true
+		case "noindex":
+			r.NoIndex = true
+		case "nofollow":
+			r.NoFollow = true
+		case "noarchive":
+			r.NoArchive = true
+		case "nosnippet":
+			r.NoSnippet = true
+		case "noimageindex":
+			r.NoImageIndex = true
+		case "notranslate":
+			r.NoTranslate = true
+		}
+	}
+}
+
+func (doc *DocumentMetadata) addDublinCore(name, content string) {
+	if doc.DublinCore == nil {
+		doc.DublinCore = map[string]string{}
+	}
+	if previous, ok := doc.DublinCore[name]; ok {
+		content = previous + "; " + content
+	}
+	doc.DublinCore[name] = content
+}
+
+// addOGImageProperty applies an og:image property to images. og:image, and
+// og:image:url unless it repeats the URL of the current image, start a new
+// image; the other properties describe the last one.
+func addOGImageProperty(images []OGImage, property, content string) []OGImage {
+	var last *OGImage
+	if len(images) > 0 {
+		last = &images[len(images)-1]
+	}
+	switch property {
+	case "og:image", "og:image:url":
+		if property == "og:image:url" && last != nil && (last.URL == "" || last.URL == content) {
+			last.URL = content
+			return images
+		}
+		return append(images, OGImage{URL: content})
+	}
+	if last == nil {
+		return images
+	}
+	switch property {
+	case "og:image:secure_url":
+		last.SecureURL = content
+	case "og:image:type":
+		last.Type = content
+	case "og:image:alt":
+		last.Alt = content
+	case "og:image:width", "og:image:height":
+		size, err := strconv.ParseUint(strings.TrimSpace(content), 10, 32)
+		if err != nil {
+			break
+		}
+		if property == "og:image:width" {
+			last.Width = uint32(size)
+		} else {
+			last.Height = uint32(size)
+		}
+	}
+	return images
+}
+
+// headOf parses the <head> of src, reading only up to its closing tag when
+// there is one. It returns nil for documents without a <head>.
+func headOf(src string) *htmlNode {
+	for i := strings.Index(src, "</"); i >= 0 && i+len("</head>") <= len(src); {
+		if strings.EqualFold(src[i:i+len("</head>")], "</head>") {
+			src = src[:i+len("</head>")]
+			break
+		}
+		next := strings.Index(src[i+2:], "</")
+		if next < 0 {
+			break
+		}
+		i += 2 + next
+	}
+	heads := parseHTML(src).findAll("head")
+	if len(heads) == 0 {
+		return nil
+	}
+	return heads[0]
+}
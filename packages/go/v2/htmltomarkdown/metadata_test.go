@@ -0,0 +1,157 @@
+package htmltomarkdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMetadataEmpty(t *testing.T) {
+	metadata, err := ExtractMetadata("")
+	if err != nil || !reflect.DeepEqual(metadata, ExtendedMetadata{}) {
+		t.Errorf("ExtractMetadata(\"\") = %+v, %v, expected empty metadata", metadata, err)
+	}
+}
+
+const ogImagesPage = `<html><head>
+<meta property="og:title" content="Gallery">
+<meta property="og:image" content="https://example.com/a.png">
+<meta property="og:image:width" content="1200">
+<meta property="og:image:height" content="630">
+<meta property="og:image:alt" content="First image">
+<meta property="og:image" content="https://example.com/b.png">
+<meta property="og:image:secure_url" content="https://secure.example.com/b.png">
+<meta property="og:image:type" content="image/png">
+<meta property="og:image:width" content="400">
+<meta property="og:image:height" content="300">
+<meta property="og:image:alt" content="Second image">
+</head><body><p>Body mentioning </head> again</p></body></html>`
+
+func TestOpenGraphImages(t *testing.T) {
+	doc := headMetadata(t, ogImagesPage)
+	expected := []OGImage{
+		{URL: "https://example.com/a.png", Width: 1200, Height: 630, Alt: "First image"},
+		{
+			URL: "https://example.com/b.png", SecureURL: "https://secure.example.com/b.png",
+			Type: "image/png", Width: 400, Height: 300, Alt: "Second image",
+		},
+	}
+	if !reflect.DeepEqual(doc.OpenGraphImages, expected) {
+		t.Errorf("OpenGraphImages = %+v, expected %+v", doc.OpenGraphImages, expected)
+	}
+	if head := headOf(ogImagesPage); head == nil || len(head.findAll("meta")) != 11 {
+		t.Errorf("headOf did not return the document head")
+	}
+}
+
+func TestAlternateLinks(t *testing.T) {
+	html := `<head>
+<link rel="alternate" hreflang="en" href="https://example.com/en/">
+<link rel="alternate" hreflang="fr" href="https://example.com/fr/">
+<link rel="Alternate" hreflang="x-default" href="https://example.com/">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+<link rel="stylesheet" hreflang="de" href="/style.css">
+</head>`
+
+	doc := headMetadata(t, html)
+	expected := []AlternateLink{
+		{Hreflang: "en", Href: "https://example.com/en/"},
+		{Hreflang: "fr", Href: "https://example.com/fr/"},
+		{Hreflang: "x-default", Href: "https://example.com/"},
+	}
+	if !reflect.DeepEqual(doc.AlternateLinks, expected) {
+		t.Errorf("AlternateLinks = %+v, expected %+v", doc.AlternateLinks, expected)
+	}
+}
+
+func TestFeedLinks(t *testing.T) {
+	html := `<head>
+<link rel="alternate" type="application/rss+xml" title="Blog (RSS)" href="/feed.rss">
+<link rel="alternate" type="application/atom+xml; charset=utf-8" title="Blog (Atom)" href="/feed.atom">
+<link rel="alternate" type="text/html" href="/print">
+</head>`
+
+	doc := headMetadata(t, html)
+	expected := []FeedLink{
+		{Title: "Blog (RSS)", Href: "/feed.rss", Type: "application/rss+xml"},
+		{Title: "Blog (Atom)", Href: "/feed.atom", Type: "application/atom+xml"},
+	}
+	if !reflect.DeepEqual(doc.FeedLinks, expected) {
+		t.Errorf("FeedLinks = %+v, expected %+v", doc.FeedLinks, expected)
+	}
+}
+
+func TestRobotsDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected *RobotsDirectives
+	}{
+		{
+			name:     "noindex nofollow",
+			html:     `<head><meta name="robots" content="noindex,nofollow"></head>`,
+			expected: &RobotsDirectives{NoIndex: true, NoFollow: true},
+		},
+		{
+			name:     "robots and googlebot",
+			html:     `<head><meta name="Robots" content="NoArchive, max-snippet:0"><meta name="googlebot" content="nosnippet"></head>`,
+			expected: &RobotsDirectives{NoArchive: true, NoSnippet: true},
+		},
+		{
+			name:     "none",
+			html:     `<head><meta name="robots" content="none"></head>`,
+			expected: &RobotsDirectives{NoIndex: true, NoFollow: true},
+		},
+		{
+			name: "absent",
+			html: `<head><title>Open</title></head>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := headMetadata(t, tt.html)
+			if !reflect.DeepEqual(doc.Robots, tt.expected) {
+				t.Errorf("Robots = %+v, expected %+v", doc.Robots, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDublinCore(t *testing.T) {
+	html := `<head>
+<meta name="DC.title" content="On Markdown">
+<meta name="DC.creator" content="Doe, Jane">
+<meta name="DC.creator" content="Roe, Richard">
+<meta name="dcterms.issued" content="2024-03-01">
+<meta name="description" content="Not Dublin Core">
+</head>`
+
+	doc := headMetadata(t, html)
+	expected := map[string]string{
+		"dc.title":       "On Markdown",
+		"dc.creator":     "Doe, Jane; Roe, Richard",
+		"dcterms.issued": "2024-03-01",
+	}
+	if !reflect.DeepEqual(doc.DublinCore, expected) {
+		t.Errorf("DublinCore = %v, expected %v", doc.DublinCore, expected)
+	}
+}
+
+func TestTitleRaw(t *testing.T) {
+	doc := headMetadata(t, `<head><title>A &amp; B</title></head>`)
+	if doc.TitleRaw == nil || *doc.TitleRaw != "A &amp; B" {
+		t.Errorf("TitleRaw = %v, expected %q", doc.TitleRaw, "A &amp; B")
+	}
+}
+
+// headMetadata returns the document metadata that addHeadMetadata adds for
+// the <head> of html.
+func headMetadata(t *testing.T, html string) DocumentMetadata {
+	t.Helper()
+	var doc DocumentMetadata
+	head := headOf(html)
+	if head == nil {
+		t.Fatalf("headOf(%q) = nil, expected the document head", html)
+	}
+	addHeadMetadata(&doc, head)
+	return doc
+}
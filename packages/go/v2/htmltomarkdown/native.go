@@ -0,0 +1,284 @@
+//go:build cgo
+
+package htmltomarkdown
+
+// #include <stdlib.h>
+// #include <stdbool.h>
+// #include <stdint.h>
+//
+// char* html_to_markdown_convert_proxy(const char* html);
+// void html_to_markdown_free_string_proxy(char* s);
+// const char* html_to_markdown_version_proxy(void);
+// const char* html_to_markdown_last_error_proxy(void);
+// char* html_to_markdown_convert_with_metadata_proxy(const char* html, char** metadata_json);
+// bool html_to_markdown_has_extract_metadata_proxy(void);
+// char* html_to_markdown_extract_metadata_proxy(const char* html);
+// bool html_to_markdown_profile_start_proxy(const char* output, int32_t frequency);
+// bool html_to_markdown_profile_stop_proxy(void);
+// int32_t html_to_markdown_abi_version_proxy(void);
+// bool html_to_markdown_reset_peak_bytes_proxy(void);
+// uint64_t html_to_markdown_peak_bytes_proxy(void);
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"unsafe"
+)
+
+// convertFFI runs the native conversion with default options.
+func convertFFI(html string) (string, error) {
+	if err := ensureFFILoaded(); err != nil {
+		return "", err
+	}
+
+	cHTML := C.CString(html)
+	defer C.free(unsafe.Pointer(cHTML))
+
+	result := C.html_to_markdown_convert_proxy(cHTML)
+	if result == nil {
+		errMsg := C.html_to_markdown_last_error_proxy()
+		if errMsg != nil {
+			return "", errors.New(C.GoString(errMsg))
+		}
+		return "", errors.New("html to markdown conversion failed")
+	}
+	defer C.html_to_markdown_free_string_proxy(result)
+
+	markdown := C.GoString(result)
+	return markdown, nil
+}
+
+// Version returns the version string of the underlying html-to-markdown library,
+// or "unknown" if the native library cannot be loaded.
+//
+// Deprecated: Use VersionInfo, which returns the *LibraryLoadError describing
+// a load failure instead of "unknown".
+func Version() string {
+	if err := ensureFFILoaded(); err != nil {
+		return unknownValue
+	}
+	cVersion := C.html_to_markdown_version_proxy()
+	if cVersion == nil {
+		return unknownValue
+	}
+	return C.GoString(cVersion)
+}
+
+// readLibraryVersion queries the loaded native library for its version and ABI revision.
+func readLibraryVersion() (LibraryVersion, error) {
+	cVersion := C.html_to_markdown_version_proxy()
+	if cVersion == nil {
+		return LibraryVersion{}, errors.New("html-to-markdown library did not report a version")
+	}
+	info, err := parseLibraryVersion(C.GoString(cVersion))
+	if err != nil {
+		return LibraryVersion{}, err
+	}
+	if abi := int(C.html_to_markdown_abi_version_proxy()); abi >= 0 {
+		info.ABIVersion = abi
+	}
+	return info, nil
+}
+
+// errNoAllocationStats is returned by ConvertWithStats for libraries built
+// without the alloc-stats feature, or predating it.
+var errNoAllocationStats = errors.New("html-to-markdown library does not report allocation statistics (build it with the alloc-stats feature)")
+
+// resetPeakBytes restarts the native allocation peak read by readPeakBytes.
+func resetPeakBytes() error {
+	if ok := C.html_to_markdown_reset_peak_bytes_proxy(); !bool(ok) {
+		return errNoAllocationStats
+	}
+	return nil
+}
+
+// readPeakBytes returns the native allocation peak since resetPeakBytes.
+func readPeakBytes() uint64 {
+	return uint64(C.html_to_markdown_peak_bytes_proxy())
+}
+
+// StartProfiling begins Rust-side profiling and writes a flamegraph to outputPath.
+func StartProfiling(outputPath string, frequency int) error {
+	if outputPath == "" {
+		return errors.New("output path is required")
+	}
+	if err := ensureFFILoaded(); err != nil {
+		return err
+	}
+	if frequency <= 0 {
+		frequency = 1000
+	}
+	cOutput := C.CString(outputPath)
+	defer C.free(unsafe.Pointer(cOutput))
+
+	ok := C.html_to_markdown_profile_start_proxy(cOutput, C.int32_t(frequency))
+	if !bool(ok) {
+		errMsg := C.html_to_markdown_last_error_proxy()
+		if errMsg != nil {
+			return errors.New(C.GoString(errMsg))
+		}
+		return errors.New("profiling start failed")
+	}
+	return nil
+}
+
+// StopProfiling stops Rust-side profiling and flushes the flamegraph.
+func StopProfiling() error {
+	if err := ensureFFILoaded(); err != nil {
+		return err
+	}
+	ok := C.html_to_markdown_profile_stop_proxy()
+	if !bool(ok) {
+		errMsg := C.html_to_markdown_last_error_proxy()
+		if errMsg != nil {
+			return errors.New(C.GoString(errMsg))
+		}
+		return errors.New("profiling stop failed")
+	}
+	return nil
+}
+
+// ConvertWithMetadata converts HTML to Markdown and extracts comprehensive metadata.
+//
+// This function calls the underlying FFI layer to perform conversion and metadata
+// extraction in a single pass. It returns the markdown along with structured metadata
+// including document information, headers, links, images, and structured data.
+//
+// The metadata extraction includes:
+// - Document metadata: title, description, keywords, author, canonical URL, language, etc.
+// - Headers: h1-h6 elements with hierarchy tracking
+// - Links: anchor elements with type classification and attributes
+// - Images: img and svg elements with source and dimensions
+// - Structured data: JSON-LD, Microdata, and RDFa blocks
+//
+// The output is deterministic: the same input always yields the same Markdown
+// and the same metadata, and the map fields (OpenGraph, TwitterCard, MetaTags
+// and Attributes) marshal to JSON with their keys sorted.
+//
+// Example:
+//
+//	html := `<html>
+//	  <head>
+//	    <title>My Article</title>
+//	    <meta name="description" content="A great article">
+//	  </head>
+//	  <body>
+//	    <h1>Main Title</h1>
+//	    <p>Content with <a href="https://example.com">a link</a></p>
+//	    <img src="image.jpg" alt="An image">
+//	  </body>
+//	</html>`
+//	result, err := ConvertWithMetadata(html)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(result.Markdown)
+//	fmt.Printf("Title: %s\n", *result.Metadata.Document.Title)
+//	fmt.Printf("Headers: %d\n", len(result.Metadata.Headers))
+//	fmt.Printf("Links: %d\n", len(result.Metadata.Links))
+//	fmt.Printf("Images: %d\n", len(result.Metadata.Images))
+func ConvertWithMetadata(html string) (MetadataExtraction, error) {
+	if html == "" {
+		return MetadataExtraction{
+			Markdown: "",
+			Metadata: ExtendedMetadata{},
+		}, nil
+	}
+	if err := ensureFFILoaded(); err != nil {
+		return MetadataExtraction{}, err
+	}
+
+	cHTML := C.CString(html)
+	defer C.free(unsafe.Pointer(cHTML))
+
+	// Allocate output pointer for metadata JSON
+	var metadataPtr *C.char
+
+	result := C.html_to_markdown_convert_with_metadata_proxy(cHTML, &metadataPtr) // nolint:gocritic
+	if result == nil {
+		errMsg := C.html_to_markdown_last_error_proxy()
+		if errMsg != nil {
+			return MetadataExtraction{}, errors.New(C.GoString(errMsg))
+		}
+		return MetadataExtraction{}, errors.New("html to markdown conversion with metadata failed")
+	}
+
+	defer C.html_to_markdown_free_string_proxy(result)
+
+	if metadataPtr != nil {
+		defer C.html_to_markdown_free_string_proxy(metadataPtr)
+	}
+
+	markdown := C.GoString(result)
+
+	// Parse metadata JSON if available
+	var metadataJSON string
+	if metadataPtr != nil {
+		metadataJSON = C.GoString(metadataPtr)
+	}
+	metadata, err := decodeMetadata(html, metadataJSON)
+	if err != nil {
+		return MetadataExtraction{}, err
+	}
+
+	return MetadataExtraction{
+		Markdown: markdown,
+		Metadata: metadata,
+	}, nil
+}
+
+// ExtractMetadata returns the metadata of html without converting it to
+// Markdown. It collects the same document, header, link, image and JSON-LD
+// metadata as ConvertWithMetadata through the native library's extractor, but
+// skips rendering the Markdown. Libraries predating the extraction export fall
+// back to ConvertWithMetadata.
+//
+// Example:
+//
+//	metadata, err := htmltomarkdown.ExtractMetadata(html)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(len(metadata.Links))
+func ExtractMetadata(html string) (ExtendedMetadata, error) {
+	if html == "" {
+		return ExtendedMetadata{}, nil
+	}
+	if err := ensureFFILoaded(); err != nil {
+		return ExtendedMetadata{}, err
+	}
+	if !bool(C.html_to_markdown_has_extract_metadata_proxy()) {
+		result, err := ConvertWithMetadata(html)
+		return result.Metadata, err
+	}
+
+	cHTML := C.CString(html)
+	defer C.free(unsafe.Pointer(cHTML))
+
+	result := C.html_to_markdown_extract_metadata_proxy(cHTML)
+	if result == nil {
+		errMsg := C.html_to_markdown_last_error_proxy()
+		if errMsg != nil {
+			return ExtendedMetadata{}, errors.New(C.GoString(errMsg))
+		}
+		return ExtendedMetadata{}, errors.New("html metadata extraction failed")
+	}
+	defer C.html_to_markdown_free_string_proxy(result)
+
+	return decodeMetadata(html, C.GoString(result))
+}
+
+// decodeMetadata parses the metadata JSON returned by the native library and
+// adds the <head> fields it does not extract.
+func decodeMetadata(html, metadataJSON string) (ExtendedMetadata, error) {
+	var metadata ExtendedMetadata
+	if metadataJSON != "" {
+		if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+			return ExtendedMetadata{}, errors.New("failed to parse metadata JSON: " + err.Error())
+		}
+	}
+	if head := headOf(html); head != nil {
+		addHeadMetadata(&metadata.Document, head)
+	}
+	return metadata, nil
+}
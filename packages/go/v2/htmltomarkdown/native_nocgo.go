@@ -0,0 +1,62 @@
+//go:build !cgo
+
+package htmltomarkdown
+
+// Without cgo the native library cannot be loaded, so conversions run on the
+// pure-Go fallback converter and the native-only entry points return
+// ErrNativeUnavailable.
+
+func convertFFI(html string) (string, error) {
+	return ConvertFallback(html)
+}
+
+func ensureFFILoaded() error {
+	return nil
+}
+
+// Version returns "unknown" when the package is built without cgo.
+//
+// Deprecated: Use VersionInfo, which returns ErrNativeUnavailable instead.
+func Version() string {
+	return unknownValue
+}
+
+func readLibraryVersion() (LibraryVersion, error) {
+	return LibraryVersion{}, ErrNativeUnavailable
+}
+
+func resetPeakBytes() error {
+	return ErrNativeUnavailable
+}
+
+func readPeakBytes() uint64 {
+	return 0
+}
+
+// StartProfiling returns ErrNativeUnavailable when the package is built without cgo.
+func StartProfiling(outputPath string, frequency int) error {
+	return ErrNativeUnavailable
+}
+
+// StopProfiling returns ErrNativeUnavailable when the package is built without cgo.
+func StopProfiling() error {
+	return ErrNativeUnavailable
+}
+
+// ConvertWithMetadata returns ErrNativeUnavailable when the package is built
+// without cgo, since the pure-Go fallback extracts no metadata.
+func ConvertWithMetadata(html string) (MetadataExtraction, error) {
+	if html == "" {
+		return MetadataExtraction{}, nil
+	}
+	return MetadataExtraction{}, ErrNativeUnavailable
+}
+
+// ExtractMetadata returns ErrNativeUnavailable when the package is built
+// without cgo, since the pure-Go fallback extracts no metadata.
+func ExtractMetadata(html string) (ExtendedMetadata, error) {
+	if html == "" {
+		return ExtendedMetadata{}, nil
+	}
+	return ExtendedMetadata{}, ErrNativeUnavailable
+}
@@ -0,0 +1,47 @@
+//go:build !cgo
+
+package htmltomarkdown
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvert_WithoutCgo(t *testing.T) {
+	result, err := Convert("<h2>Notes</h2><ul><li><a href=\"/a\">First</a></li><li><em>Second</em></li></ul>")
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	expected := "## Notes\n\n- [First](/a)\n- *Second*\n"
+	if result != expected {
+		t.Errorf("Convert() = %q, expected %q", result, expected)
+	}
+}
+
+func TestConvertWithOptions_WithoutCgo(t *testing.T) {
+	result, err := ConvertWithOptions("<p>Read <a href=\"https://example.com\">this</a>.</p>", ConversionOptions{LinkStyle: LinkStyleTextOnly})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if result != "Read this.\n" {
+		t.Errorf("ConvertWithOptions() = %q, expected %q", result, "Read this.\n")
+	}
+}
+
+func TestNativeOnlyFunctions_WithoutCgo(t *testing.T) {
+	if _, err := ConvertWithMetadata("<p>Text</p>"); !errors.Is(err, ErrNativeUnavailable) {
+		t.Errorf("ConvertWithMetadata error = %v, expected ErrNativeUnavailable", err)
+	}
+	if _, err := ExtractMetadata("<p>Text</p>"); !errors.Is(err, ErrNativeUnavailable) {
+		t.Errorf("ExtractMetadata error = %v, expected ErrNativeUnavailable", err)
+	}
+	if _, _, err := ConvertWithStats("<p>Text</p>"); !errors.Is(err, ErrNativeUnavailable) {
+		t.Errorf("ConvertWithStats error = %v, expected ErrNativeUnavailable", err)
+	}
+	if _, err := VersionInfo(); !errors.Is(err, ErrNativeUnavailable) {
+		t.Errorf("VersionInfo error = %v, expected ErrNativeUnavailable", err)
+	}
+	if Version() != "unknown" {
+		t.Errorf("Version() = %q, expected unknown", Version())
+	}
+}
@@ -0,0 +1,1268 @@
+package htmltomarkdown
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TableSpanMode controls how table cells spanning several rows or columns are laid out.
+type TableSpanMode string
+
+const (
+	// TableSpanModeIgnore fills the positions covered by a span with empty cells.
+	TableSpanModeIgnore TableSpanMode = "ignore"
+
+	// TableSpanModeExpand repeats the spanning cell's content in every position it covers.
+	TableSpanModeExpand TableSpanMode = "expand"
+)
+
+// TableFormat selects how tables are rendered.
+type TableFormat string
+
+const (
+	// TableFormatMarkdown renders tables as Markdown pipe tables.
+	TableFormatMarkdown TableFormat = "markdown"
+
+	// TableFormatCSV renders each table as a fenced csv code block.
+	TableFormatCSV TableFormat = "csv"
+
+	// TableFormatTSV renders each table as a fenced tsv code block.
+	TableFormatTSV TableFormat = "tsv"
+)
+
+// ListSpacing controls the blank lines between list items.
+type ListSpacing string
+
+const (
+	// ListSpacingTight renders lists of simple items without blank lines between them.
+	ListSpacingTight ListSpacing = "tight"
+
+	// ListSpacingLoose separates every item with a blank line when any item holds block content.
+	ListSpacingLoose ListSpacing = "loose"
+)
+
+// DefinitionListStyle selects how <dl> definition lists are rendered.
+type DefinitionListStyle string
+
+const (
+	// DefinitionListStyleHTML keeps definition lists as raw HTML.
+	DefinitionListStyleHTML DefinitionListStyle = "html"
+
+	// DefinitionListStyleColon renders Pandoc-style definition lists, each
+	// description on its own line introduced by ": ".
+	DefinitionListStyleColon DefinitionListStyle = "colon"
+
+	// DefinitionListStyleBold renders each term in bold followed by its descriptions.
+	DefinitionListStyleBold DefinitionListStyle = "bold"
+)
+
+// SubSupStyle selects how <sub> and <sup> are rendered.
+type SubSupStyle string
+
+const (
+	// SubSupStyleMarkdown renders ~subscript~ and ^superscript^.
+	SubSupStyleMarkdown SubSupStyle = "markdown"
+
+	// SubSupStyleHTML keeps the <sub> and <sup> tags.
+	SubSupStyleHTML SubSupStyle = "html"
+
+	// SubSupStyleUnicode maps digits, signs and letters to their Unicode
+	// subscript or superscript forms, falling back to HTML when a character
+	// has none.
+	SubSupStyleUnicode SubSupStyle = "unicode"
+)
+
+// HighlightStyle selects how <mark> highlights are rendered.
+type HighlightStyle string
+
+const (
+	// HighlightStyleDoubleEquals renders ==highlighted== text.
+	HighlightStyleDoubleEquals HighlightStyle = "double_equals"
+
+	// HighlightStyleHTML keeps the <mark> tags.
+	HighlightStyleHTML HighlightStyle = "html"
+
+	// HighlightStyleNone drops the highlight and keeps its content.
+	HighlightStyleNone HighlightStyle = "none"
+)
+
+// TrailingNewline controls the line endings at the end of the output.
+type TrailingNewline string
+
+const (
+	// TrailingNewlineSingle ends non-empty output with exactly one newline.
+	TrailingNewlineSingle TrailingNewline = "single"
+
+	// TrailingNewlineNone removes every trailing newline.
+	TrailingNewlineNone TrailingNewline = "none"
+
+	// TrailingNewlinePreserve keeps the native library's trailing newlines.
+	TrailingNewlinePreserve TrailingNewline = "preserve"
+)
+
+// EscapeMode controls backslash escaping of Markdown syntax characters in text.
+type EscapeMode string
+
+const (
+	// EscapeModeSmart escapes characters only where they would be read as
+	// Markdown: emphasis markers, list and heading markers at the start of a
+	// line, link brackets, code span backticks and pipes in table cells.
+	EscapeModeSmart EscapeMode = "smart"
+
+	// EscapeModeAll escapes every Markdown syntax character.
+	EscapeModeAll EscapeMode = "all"
+
+	// EscapeModeNone leaves text unescaped.
+	EscapeModeNone EscapeMode = "none"
+)
+
+// TableSectionStyle selects how the <thead>, <tbody> and <tfoot> sections
+// of a table are laid out.
+type TableSectionStyle string
+
+const (
+	// TableSectionStyleSource keeps the <tfoot> rows where they appear in
+	// the source, as body rows.
+	TableSectionStyleSource TableSectionStyle = "source"
+
+	// TableSectionStyleFooterLast moves the <tfoot> rows after the other
+	// body rows, where browsers display them.
+	TableSectionStyleFooterLast TableSectionStyle = "footer_last"
+)
+
+// RowHeaderStyle selects how row header cells are rendered.
+type RowHeaderStyle string
+
+const (
+	// RowHeaderStylePlain renders row headers like the other cells.
+	RowHeaderStylePlain RowHeaderStyle = "plain"
+
+	// RowHeaderStyleBold renders row headers in bold.
+	RowHeaderStyleBold RowHeaderStyle = "bold"
+)
+
+// TableCaptionStyle selects how table captions are rendered.
+type TableCaptionStyle string
+
+const (
+	// TableCaptionStyleItalic renders the caption as an italic line above
+	// the table.
+	TableCaptionStyleItalic TableCaptionStyle = "italic"
+
+	// TableCaptionStyleBold renders the caption as a bold line above the
+	// table.
+	TableCaptionStyleBold TableCaptionStyle = "bold"
+)
+
+// ChangeTrackingStyle selects how <ins> and <del> edits are rendered.
+type ChangeTrackingStyle string
+
+const (
+	// ChangeTrackingStyleMarkdown converts edits like Convert does, with
+	// deletions as ~~strikethrough~~.
+	ChangeTrackingStyleMarkdown ChangeTrackingStyle = "markdown"
+
+	// ChangeTrackingStyleHTML keeps the <ins> and <del> elements, with their
+	// attributes, as raw HTML.
+	ChangeTrackingStyleHTML ChangeTrackingStyle = "html"
+
+	// ChangeTrackingStyleAccept applies the edits: insertions are kept as
+	// plain text and deletions are dropped.
+	ChangeTrackingStyleAccept ChangeTrackingStyle = "accept"
+
+	// ChangeTrackingStyleReject reverts the edits: deletions are kept as
+	// plain text and insertions are dropped.
+	ChangeTrackingStyleReject ChangeTrackingStyle = "reject"
+)
+
+// FigureStyle selects how <figure> elements are rendered.
+type FigureStyle string
+
+const (
+	// FigureStyleCaptionBelow renders the figure content followed by its
+	// caption as an italic line.
+	FigureStyleCaptionBelow FigureStyle = "caption_below"
+
+	// FigureStyleHTML keeps figures as raw HTML.
+	FigureStyleHTML FigureStyle = "html"
+)
+
+// AddressStyle selects how <address> elements are rendered.
+type AddressStyle string
+
+const (
+	// AddressStyleItalic renders each contact line in italics.
+	AddressStyleItalic AddressStyle = "italic"
+
+	// AddressStyleBlockquote renders the address as a block quote.
+	AddressStyleBlockquote AddressStyle = "blockquote"
+)
+
+// SrcsetSelection selects which srcset candidate provides an image's URL.
+type SrcsetSelection string
+
+const (
+	// SrcsetSelectionSrc uses the src attribute and ignores srcset.
+	SrcsetSelectionSrc SrcsetSelection = "src"
+
+	// SrcsetSelectionHighest uses the candidate with the largest width or density.
+	SrcsetSelectionHighest SrcsetSelection = "highest"
+
+	// SrcsetSelectionLowest uses the candidate with the smallest width or density.
+	SrcsetSelectionLowest SrcsetSelection = "lowest"
+)
+
+// LinkStyle selects how links are rendered.
+type LinkStyle string
+
+const (
+	// LinkStyleInline renders links as [text](url "title").
+	LinkStyleInline LinkStyle = "inline"
+
+	// LinkStyleTextOnly drops links and keeps their content.
+	LinkStyleTextOnly LinkStyle = "text_only"
+
+	// LinkStyleTextWithURL renders links as their content followed by the
+	// URL in parentheses, as in "text (https://example.com)", for print.
+	LinkStyleTextWithURL LinkStyle = "text_with_url"
+)
+
+// TimeStyle selects how <time> elements are rendered.
+type TimeStyle string
+
+const (
+	// TimeStyleText renders the element's text.
+	TimeStyleText TimeStyle = "text"
+
+	// TimeStyleISO renders the datetime attribute instead of the text.
+	TimeStyleISO TimeStyle = "iso"
+
+	// TimeStyleBoth renders the text followed by the datetime in
+	// parentheses, as in "Jan 1 (2024-01-01)".
+	TimeStyleBoth TimeStyle = "both"
+)
+
+// RubyStyle selects how <ruby> annotations are rendered.
+type RubyStyle string
+
+const (
+	// RubyStyleHTML keeps ruby annotations as raw HTML.
+	RubyStyleHTML RubyStyle = "html"
+
+	// RubyStyleParentheses renders each annotation in parentheses after its
+	// base text, as in "漢(kan)".
+	RubyStyleParentheses RubyStyle = "parentheses"
+
+	// RubyStyleBaseOnly renders the base text and drops the annotations.
+	RubyStyleBaseOnly RubyStyle = "base_only"
+)
+
+// MathStyle selects how MathML <math> elements are rendered.
+type MathStyle string
+
+const (
+	// MathStyleDrop removes math elements.
+	MathStyleDrop MathStyle = "drop"
+
+	// MathStyleLaTeX converts MathML to LaTeX delimited by $...$, or by $$
+	// lines for display math. Fractions, scripts, roots, fences, Greek
+	// letters and common operators are mapped; other elements contribute
+	// their content.
+	MathStyleLaTeX MathStyle = "latex"
+
+	// MathStyleHTML keeps math elements as raw HTML.
+	MathStyleHTML MathStyle = "html"
+)
+
+// SmartPunctuation selects the handling of typographic quotes and dashes.
+type SmartPunctuation string
+
+const (
+	// SmartPunctuationPreserve leaves quotes and dashes as written.
+	SmartPunctuationPreserve SmartPunctuation = "preserve"
+
+	// SmartPunctuationAsciify replaces curly quotes with straight ones, em
+	// dashes with "--", en dashes with "-" and ellipses with "...".
+	SmartPunctuationAsciify SmartPunctuation = "asciify"
+
+	// SmartPunctuationSmarten replaces straight quotes with curly ones, "--"
+	// with an em dash and "..." with an ellipsis.
+	SmartPunctuationSmarten SmartPunctuation = "smarten"
+)
+
+// BlockInLinkMode selects how links wrapping block elements are rendered.
+type BlockInLinkMode string
+
+const (
+	// BlockInLinkModeLift keeps the blocks and links the content of each,
+	// so <a href="/post"><h3>Title</h3><p>Summary</p></a> becomes a linked
+	// heading followed by a linked paragraph.
+	BlockInLinkModeLift BlockInLinkMode = "lift"
+
+	// BlockInLinkModeInlineText flattens the blocks into a single inline link
+	// whose text joins their content with spaces.
+	BlockInLinkModeInlineText BlockInLinkMode = "inline_text"
+)
+
+// WbrStyle selects how <wbr> word-break opportunities are rendered.
+type WbrStyle string
+
+const (
+	// WbrStyleRemove drops <wbr>, joining the surrounding text.
+	WbrStyleRemove WbrStyle = "remove"
+
+	// WbrStyleZeroWidth replaces <wbr> with a zero-width space (U+200B), which
+	// keeps the break opportunity in the Markdown.
+	WbrStyleZeroWidth WbrStyle = "zero_width"
+)
+
+// QuoteStyle selects how <q> quotations are rendered.
+type QuoteStyle string
+
+const (
+	// QuoteStyleStraight encloses quotations in straight double quotes,
+	// alternating with single quotes for nested quotations.
+	QuoteStyleStraight QuoteStyle = "straight"
+
+	// QuoteStyleCurly encloses quotations in typographic double quotes,
+	// alternating with single quotes for nested quotations.
+	QuoteStyleCurly QuoteStyle = "curly"
+
+	// QuoteStyleNone drops the quotation and keeps its content.
+	QuoteStyleNone QuoteStyle = "none"
+)
+
+// InlineSemanticStyle selects how semantic inline elements such as <cite>
+// and <dfn> are rendered.
+type InlineSemanticStyle string
+
+const (
+	// InlineSemanticStyleEmphasis renders the element as *emphasis*.
+	InlineSemanticStyleEmphasis InlineSemanticStyle = "emphasis"
+
+	// InlineSemanticStyleBold renders the element as **bold** text.
+	InlineSemanticStyleBold InlineSemanticStyle = "bold"
+
+	// InlineSemanticStyleNone drops the element and keeps its content.
+	InlineSemanticStyleNone InlineSemanticStyle = "none"
+)
+
+// ElementStrategy selects how ConversionOptions.ElementOverrides converts an
+// element.
+type ElementStrategy string
+
+const (
+	// ElementStrategyKeepHTML keeps the element as raw HTML.
+	ElementStrategyKeepHTML ElementStrategy = "keep_html"
+
+	// ElementStrategyDrop removes the element and its content.
+	ElementStrategyDrop ElementStrategy = "drop"
+
+	// ElementStrategyUnwrap discards the tag and converts its children in
+	// its place.
+	ElementStrategyUnwrap ElementStrategy = "unwrap"
+
+	// ElementStrategyCode renders the element's text as inline code, or as
+	// a fenced code block for block elements.
+	ElementStrategyCode ElementStrategy = "code"
+)
+
+// HeadingStyle selects how headings are written.
+type HeadingStyle string
+
+const (
+	// HeadingStyleATX writes headings with leading hashes, as in "## Title".
+	HeadingStyleATX HeadingStyle = "atx"
+
+	// HeadingStyleSetext underlines level 1 headings with = and level 2
+	// headings with -. Deeper headings have no setext form and stay ATX.
+	HeadingStyleSetext HeadingStyle = "setext"
+)
+
+// FormStyle selects how <form> elements are rendered.
+type FormStyle string
+
+const (
+	// FormStyleDrop removes forms and their content.
+	FormStyleDrop FormStyle = "drop"
+
+	// FormStyleList replaces each form with a list of its fields, each
+	// described by its label, type and current value. Checkboxes and radio
+	// buttons become task list items.
+	FormStyleList FormStyle = "list"
+
+	// FormStyleHTML keeps forms as raw HTML.
+	FormStyleHTML FormStyle = "html"
+)
+
+// SelectStyle selects how <select> elements are rendered.
+type SelectStyle string
+
+const (
+	// SelectStyleList renders the options as a task list whose checked
+	// items are the selected options.
+	SelectStyleList SelectStyle = "list"
+
+	// SelectStyleSelected keeps only the text of the selected options.
+	SelectStyleSelected SelectStyle = "selected"
+
+	// SelectStyleDrop removes selects and their options.
+	SelectStyleDrop SelectStyle = "drop"
+)
+
+// TextareaStyle selects how <textarea> content is converted.
+type TextareaStyle string
+
+const (
+	// TextareaStyleCode renders the content as a fenced code block.
+	TextareaStyleCode TextareaStyle = "code"
+
+	// TextareaStyleText renders the content as a paragraph, keeping its
+	// line breaks.
+	TextareaStyleText TextareaStyle = "text"
+
+	// TextareaStyleDrop removes textareas and their content.
+	TextareaStyleDrop TextareaStyle = "drop"
+)
+
+// ConversionOptions configures ConvertWithOptions.
+//
+// The zero value converts exactly like Convert. Options are applied by the Go
+// bindings around the native conversion, so they are available with any
+// version of the native library.
+type ConversionOptions struct {
+	// TableSpanMode controls colspan and rowspan handling. Empty keeps the
+	// native library's default layout.
+	TableSpanMode TableSpanMode
+
+	// TableFormat selects the table output format. Empty means TableFormatMarkdown.
+	TableFormat TableFormat
+
+	// TableSectionStyle lays out tables with <thead>, <tbody> or <tfoot>
+	// sections: the <thead> rows merge into the single header row, followed
+	// by the separator row and the body rows. Empty keeps the native
+	// library's output.
+	TableSectionStyle TableSectionStyle
+
+	// RowHeaderStyle selects the rendering of row headers: <th> cells with
+	// scope="row", or in a body row next to <td> cells. Empty means
+	// RowHeaderStylePlain.
+	RowHeaderStyle RowHeaderStyle
+
+	// TableCaptionStyle selects the rendering of table captions. Empty keeps
+	// the native library's output.
+	TableCaptionStyle TableCaptionStyle
+
+	// ListSpacing selects tight or loose lists. Empty keeps the native
+	// library's spacing, which is loose when an item contains a paragraph.
+	ListSpacing ListSpacing
+
+	// RespectOrderedListStart numbers ordered lists from their start attribute,
+	// counts down for reversed lists and honors value attributes on items.
+	// Lists with type a, A, i or I get alphabetic or roman markers such as
+	// "c." or "iv.", as in Pandoc's fancy lists; CommonMark renderers show
+	// those items as plain paragraphs.
+	RespectOrderedListStart bool
+
+	// DefinitionListStyle selects the rendering of definition lists. Empty
+	// keeps the native library's output.
+	DefinitionListStyle DefinitionListStyle
+
+	// SubSupStyle selects the rendering of subscripts and superscripts. Empty
+	// keeps the native library's output, which drops the markup.
+	SubSupStyle SubSupStyle
+
+	// EmojiShortcodes rewrites Unicode emoji and <img class="emoji"> images
+	// as GitHub shortcodes such as :smile:. Emoji without a known shortcode
+	// and text inside code are left unchanged.
+	EmojiShortcodes bool
+
+	// ChangeTrackingStyle selects the rendering of <ins> and <del>. Empty
+	// means ChangeTrackingStyleMarkdown.
+	ChangeTrackingStyle ChangeTrackingStyle
+
+	// HighlightStyle selects the rendering of <mark>. Empty keeps the native
+	// library's output.
+	HighlightStyle HighlightStyle
+
+	// TrailingNewline controls the newlines at the end of the output. Empty
+	// means TrailingNewlineSingle.
+	TrailingNewline TrailingNewline
+
+	// EscapeMode controls escaping of Markdown syntax characters in text.
+	// Empty keeps the native library's output, which escapes nothing, so that
+	// the zero value still matches Convert.
+	EscapeMode EscapeMode
+
+	// KeepNamedAnchors emits <a name="..."> anchors without an href as raw
+	// HTML so in-page links to them keep working.
+	KeepNamedAnchors bool
+
+	// FigureStyle selects the rendering of figures. Empty keeps the native
+	// library's output, which places the caption where it appears in the source.
+	FigureStyle FigureStyle
+
+	// AdmonitionClasses maps CSS classes to GFM alert keywords, rendering the
+	// elements carrying them as callouts:
+	//
+	//	AdmonitionClasses: map[string]string{"note": "NOTE", "warning": "WARNING"}
+	//
+	// turns <div class="warning">Careful</div> into "> [!WARNING]\n> Careful".
+	AdmonitionClasses map[string]string
+
+	// AddressStyle selects the rendering of <address>. Lines separated by
+	// <br> or block elements stay on separate lines, joined by hard breaks.
+	// Empty keeps the native library's output.
+	AddressStyle AddressStyle
+
+	// HorizontalRuleStyle is the marker emitted for <hr>, such as "***" or
+	// "___". It must be a CommonMark thematic break. Empty keeps the native
+	// library's "---".
+	HorizontalRuleStyle string
+
+	// CollapseHorizontalRules renders consecutive <hr> elements, with no
+	// content between them, as a single rule.
+	CollapseHorizontalRules bool
+
+	// TrimHorizontalRules drops the <hr> elements before the first content
+	// of the document and after the last.
+	TrimHorizontalRules bool
+
+	// SrcsetSelection selects the URL of images with a srcset attribute.
+	// Empty means SrcsetSelectionSrc.
+	SrcsetSelection SrcsetSelection
+
+	// LazyLoadAttrs lists the <img> attributes holding the real URL of
+	// lazy-loaded images, in order of preference. Their value replaces the
+	// placeholder src. Nil means data-src and data-original; an empty,
+	// non-nil slice disables the rewrite.
+	LazyLoadAttrs []string
+
+	// Footnotes converts footnote references such as <sup><a href="#fn1">
+	// into GFM [^1] references and the elements they point to into [^1]:
+	// definitions at the end of the document.
+	Footnotes bool
+
+	// LinkStyle selects the rendering of links. Empty means LinkStyleInline.
+	LinkStyle LinkStyle
+
+	// OmitInternalLinkURLs makes LinkStyleTextWithURL append only the URLs
+	// of external links; fragment and relative links render as plain text.
+	OmitInternalLinkURLs bool
+
+	// TimeStyle selects the rendering of <time> elements that carry a
+	// datetime attribute. Empty means TimeStyleText.
+	TimeStyle TimeStyle
+
+	// RubyStyle selects the rendering of <ruby> annotations. Empty keeps the
+	// native library's output.
+	RubyStyle RubyStyle
+
+	// ImageAltInText drops images and keeps their alt text as plain text,
+	// for text-only exports. Images without alt text are removed.
+	ImageAltInText bool
+
+	// MathStyle selects the rendering of MathML. Empty keeps the native
+	// library's output, which flattens the math to text.
+	MathStyle MathStyle
+
+	// MathClasses lists the classes of elements holding TeX source, such
+	// as <span class="math">\frac{a}{b}</span>, converted under MathStyle.
+	// Nil means "math"; an empty, non-nil slice disables the detection.
+	MathClasses []string
+
+	// MathScriptTypes lists the <script> types holding TeX source, as
+	// written by MathJax. Nil means "math/tex"; an empty, non-nil slice
+	// disables the detection.
+	MathScriptTypes []string
+
+	// SmartPunctuation controls quotes and dashes in text outside code.
+	// Empty means SmartPunctuationPreserve.
+	SmartPunctuation SmartPunctuation
+
+	// NormalizeNbsp replaces non-breaking spaces in text outside code with
+	// regular spaces.
+	NormalizeNbsp bool
+
+	// NormalizeUnicodeSpaces extends NormalizeNbsp to the other Unicode
+	// space separators, such as thin, en and em spaces.
+	NormalizeUnicodeSpaces bool
+
+	// TrimLinkText removes the whitespace at the edges of link content,
+	// including around inline markup such as <a> <em>text</em> </a>, and
+	// collapses line breaks and runs of whitespace in image alt text. It is
+	// off in the zero value so that the zero value still matches Convert.
+	TrimLinkText bool
+
+	// BlockInLinkMode selects the rendering of links wrapping block elements
+	// such as headings and paragraphs. Empty keeps the native library's output.
+	BlockInLinkMode BlockInLinkMode
+
+	// DropEmptyLinks removes links without text, image or title instead of
+	// rendering them as [](url).
+	DropEmptyLinks bool
+
+	// DropEmptyImages removes images without a src instead of rendering
+	// them as ![](). Images with a src and an empty alt are kept.
+	DropEmptyImages bool
+
+	// WbrStyle selects the rendering of <wbr> elements. Empty means
+	// WbrStyleRemove.
+	WbrStyle WbrStyle
+
+	// QuoteStyle selects the rendering of <q> quotations. Empty keeps the
+	// native library's output, which uses straight double quotes and escapes
+	// those of nested quotations.
+	QuoteStyle QuoteStyle
+
+	// CiteStyle selects the rendering of <cite>. Empty keeps the native
+	// library's output, which is emphasis.
+	CiteStyle InlineSemanticStyle
+
+	// DfnStyle selects the rendering of <dfn>. Empty keeps the native
+	// library's output, which is emphasis.
+	DfnStyle InlineSemanticStyle
+
+	// InferFormattingFromStyle renders elements styled with a bold
+	// font-weight, an italic font-style or a line-through text-decoration,
+	// as pasted from word processors, as **bold**, *italic* and ~~struck~~
+	// text.
+	InferFormattingFromStyle bool
+
+	// SkipHidden drops elements with a hidden attribute or a display:none
+	// style, along with their content.
+	SkipHidden bool
+
+	// SkipInvisible drops elements with a visibility:hidden style, along
+	// with their content.
+	SkipInvisible bool
+
+	// DropAriaHidden drops elements with aria-hidden="true", along with
+	// their content. Icons and decorative duplicates are often marked this way.
+	DropAriaHidden bool
+
+	// ElementOverrides maps lowercase tag names to the strategy converting
+	// those elements, as a declarative alternative to visitor callbacks:
+	//
+	//	ElementOverrides: map[string]ElementStrategy{"sub": "keep_html", "aside": "drop"}
+	//
+	// Overrides apply before the other options.
+	ElementOverrides map[string]ElementStrategy
+
+	// UnwrapRedundantContainers removes <div> and <span> wrappers without
+	// meaning, such as nested divs around a single paragraph, which would
+	// otherwise add blank lines.
+	UnwrapRedundantContainers bool
+
+	// MaxDepth limits the element nesting depth of the input. Deeper
+	// documents fail with a *ConversionError of kind
+	// ConversionErrorMaxDepthExceeded instead of being converted, which guards
+	// against hostile input such as thousands of nested divs. Zero means
+	// unlimited, though conversions that parse the document on the Go side,
+	// such as those setting any option, fail the same way beyond 512 levels.
+	MaxDepth int
+
+	// MaxInputBytes limits the size of the input. Larger inputs fail with a
+	// *ConversionError of kind ConversionErrorInputTooLarge before any
+	// parsing. Zero means unlimited.
+	MaxInputBytes int
+
+	// Timeout, when positive, bounds how long the call waits for the
+	// conversion. Once it has elapsed the call fails with a *ConversionError
+	// of kind ConversionErrorTimeout. This only abandons waiting: the native
+	// library cannot be interrupted, so the conversion keeps running in the
+	// background, holding its CPU and memory, until it completes and its
+	// result is discarded. Visitor callbacks may still be called after the
+	// call has returned. Use MaxInputBytes and MaxDepth to bound the work
+	// itself.
+	Timeout time.Duration
+
+	// HeadingStyle selects the heading syntax. Empty means HeadingStyleATX.
+	HeadingStyle HeadingStyle
+
+	// HeadingOffset shifts the level of every heading, so an offset of 1
+	// writes <h1> as ## when embedding a page in a larger document.
+	// Negative offsets promote headings. Levels are clamped to 1 through 6.
+	HeadingOffset int
+
+	// NormalizeHeadingHierarchy rewrites the heading levels into a single
+	// outline: the first heading is the only level 1 heading, and levels
+	// are never skipped, so h1, h1, h3 becomes h1, h2, h3. It applies after
+	// HeadingOffset. ConvertWithMetadataOptions reports the changed
+	// headings in ExtendedMetadata.HeadingChanges.
+	NormalizeHeadingHierarchy bool
+
+	// MergeAdjacentFormatting merges adjacent bold, italic or struck-through
+	// runs, so <strong>a</strong><strong>b</strong> renders as **ab**
+	// instead of **a****b**. Whitespace between the runs is kept inside.
+	MergeAdjacentFormatting bool
+
+	// CodeLanguageClasses lists the class prefixes marking the language of
+	// a code block, such as "language-" or "highlight-source-". When set,
+	// <pre> blocks render as fenced code blocks whose info string is the
+	// first matching class with its prefix stripped, or the lang attribute
+	// of the block. Empty keeps the native library's indented code blocks.
+	CodeLanguageClasses []string
+
+	// StripCodeLineNumbers removes the line numbers highlighters render
+	// beside code, either as a gutter column in a table or as line-number
+	// elements inside the <pre> block, so only the code is converted.
+	StripCodeLineNumbers bool
+
+	// PreserveWhitespaceInPre renders <pre> blocks as fenced code blocks
+	// holding their text byte for byte, tabs and indentation included,
+	// instead of the native library's dedented, indented code blocks.
+	PreserveWhitespaceInPre bool
+
+	// TextareaStyle selects the rendering of <textarea> content. Empty keeps
+	// the native library's output, which runs the content into the
+	// surrounding text.
+	TextareaStyle TextareaStyle
+
+	// FormStyle selects the rendering of <form> elements. Empty keeps the
+	// native library's output, which runs the text of the form's labels and
+	// buttons into the document.
+	FormStyle FormStyle
+
+	// SelectStyle selects the rendering of <select> elements. Selects in a
+	// form rendered with FormStyleList are described by its field list
+	// instead. Empty keeps the native library's output, which runs the text
+	// of every option together.
+	SelectStyle SelectStyle
+
+	// GenerateTOC inserts a table of contents: a nested list of links to
+	// the headings, using the anchors GitHub derives from their text, with
+	// repeated headings suffixed -1, -2 and so on. The list replaces the
+	// first <!--toc--> comment, or else starts the document.
+	GenerateTOC bool
+}
+
+// lazyLoadAttrs returns the configured lazy-load attributes or the defaults.
+func (o *ConversionOptions) lazyLoadAttrs() []string {
+	if o.LazyLoadAttrs == nil {
+		return defaultLazyLoadAttrs
+	}
+	return o.LazyLoadAttrs
+}
+
+// mathClasses returns the configured TeX element classes or the defaults.
+func (o *ConversionOptions) mathClasses() []string {
+	if o.MathClasses == nil {
+		return defaultMathClasses
+	}
+	return o.MathClasses
+}
+
+// mathScriptTypes returns the configured TeX script types or the defaults.
+func (o *ConversionOptions) mathScriptTypes() []string {
+	if o.MathScriptTypes == nil {
+		return defaultMathScriptTypes
+	}
+	return o.MathScriptTypes
+}
+
+// codeLanguageClasses returns the class prefixes marking code languages,
+// applying the default when none are configured.
+func (o *ConversionOptions) codeLanguageClasses() []string {
+	if len(o.CodeLanguageClasses) == 0 {
+		return defaultCodeLanguageClasses
+	}
+	return o.CodeLanguageClasses
+}
+
+// isZero reports whether o is the zero value, which converts like Convert.
+func (o *ConversionOptions) isZero() bool {
+	return reflect.ValueOf(*o).IsZero()
+}
+
+// validate reports an error for option values outside their documented sets.
+func (o *ConversionOptions) validate() error {
+	errs := []error{
+		checkOption("TableSpanMode", o.TableSpanMode, TableSpanModeIgnore, TableSpanModeExpand),
+		checkOption("TableFormat", o.TableFormat, TableFormatMarkdown, TableFormatCSV, TableFormatTSV),
+		checkOption("TableSectionStyle", o.TableSectionStyle, TableSectionStyleSource, TableSectionStyleFooterLast),
+		checkOption("RowHeaderStyle", o.RowHeaderStyle, RowHeaderStylePlain, RowHeaderStyleBold),
+		checkOption("TableCaptionStyle", o.TableCaptionStyle, TableCaptionStyleItalic, TableCaptionStyleBold),
+		checkOption("ListSpacing", o.ListSpacing, ListSpacingTight, ListSpacingLoose),
+		checkOption("DefinitionListStyle", o.DefinitionListStyle,
+			DefinitionListStyleHTML, DefinitionListStyleColon, DefinitionListStyleBold),
+		checkOption("SubSupStyle", o.SubSupStyle, SubSupStyleMarkdown, SubSupStyleHTML, SubSupStyleUnicode),
+		checkOption("HighlightStyle", o.HighlightStyle,
+			HighlightStyleDoubleEquals, HighlightStyleHTML, HighlightStyleNone),
+		checkOption("TrailingNewline", o.TrailingNewline,
+			TrailingNewlineSingle, TrailingNewlineNone, TrailingNewlinePreserve),
+		checkOption("EscapeMode", o.EscapeMode, EscapeModeSmart, EscapeModeAll, EscapeModeNone),
+		checkOption("ChangeTrackingStyle", o.ChangeTrackingStyle, ChangeTrackingStyleMarkdown,
+			ChangeTrackingStyleHTML, ChangeTrackingStyleAccept, ChangeTrackingStyleReject),
+		checkOption("FigureStyle", o.FigureStyle, FigureStyleCaptionBelow, FigureStyleHTML),
+		checkOption("AddressStyle", o.AddressStyle, AddressStyleItalic, AddressStyleBlockquote),
+		checkOption("SrcsetSelection", o.SrcsetSelection,
+			SrcsetSelectionSrc, SrcsetSelectionHighest, SrcsetSelectionLowest),
+		checkOption("LinkStyle", o.LinkStyle, LinkStyleInline, LinkStyleTextOnly, LinkStyleTextWithURL),
+		checkOption("TimeStyle", o.TimeStyle, TimeStyleText, TimeStyleISO, TimeStyleBoth),
+		checkOption("RubyStyle", o.RubyStyle, RubyStyleHTML, RubyStyleParentheses, RubyStyleBaseOnly),
+		checkOption("MathStyle", o.MathStyle, MathStyleDrop, MathStyleLaTeX, MathStyleHTML),
+		checkOption("SmartPunctuation", o.SmartPunctuation,
+			SmartPunctuationPreserve, SmartPunctuationAsciify, SmartPunctuationSmarten),
+		checkOption("BlockInLinkMode", o.BlockInLinkMode, BlockInLinkModeLift, BlockInLinkModeInlineText),
+		checkOption("WbrStyle", o.WbrStyle, WbrStyleRemove, WbrStyleZeroWidth),
+		checkOption("QuoteStyle", o.QuoteStyle, QuoteStyleStraight, QuoteStyleCurly, QuoteStyleNone),
+		checkOption("CiteStyle", o.CiteStyle,
+			InlineSemanticStyleEmphasis, InlineSemanticStyleBold, InlineSemanticStyleNone),
+		checkOption("DfnStyle", o.DfnStyle,
+			InlineSemanticStyleEmphasis, InlineSemanticStyleBold, InlineSemanticStyleNone),
+		checkOption("HeadingStyle", o.HeadingStyle, HeadingStyleATX, HeadingStyleSetext),
+		checkOption("TextareaStyle", o.TextareaStyle, TextareaStyleCode, TextareaStyleText, TextareaStyleDrop),
+		checkOption("FormStyle", o.FormStyle, FormStyleDrop, FormStyleList, FormStyleHTML),
+		checkOption("SelectStyle", o.SelectStyle, SelectStyleList, SelectStyleSelected, SelectStyleDrop),
+	}
+	for _, tag := range slices.Sorted(maps.Keys(o.ElementOverrides)) {
+		errs = append(errs, checkOption(fmt.Sprintf("ElementOverrides[%q]", tag), o.ElementOverrides[tag],
+			ElementStrategyKeepHTML, ElementStrategyDrop, ElementStrategyUnwrap, ElementStrategyCode))
+	}
+	if o.HorizontalRuleStyle != "" && !isThematicBreak(o.HorizontalRuleStyle) {
+		errs = append(errs, fmt.Errorf("invalid ConversionOptions.HorizontalRuleStyle %q: expected a thematic break such as \"---\"", o.HorizontalRuleStyle))
+	}
+	for _, class := range slices.Sorted(maps.Keys(o.AdmonitionClasses)) {
+		if keyword := o.AdmonitionClasses[class]; keyword == "" || strings.ContainsAny(keyword, " \t\n]") {
+			errs = append(errs, fmt.Errorf("invalid ConversionOptions.AdmonitionClasses[%q] %q: expected a keyword such as \"NOTE\"", class, keyword))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkOption accepts the empty value and the allowed values of a string option.
+func checkOption[T ~string](field string, value T, allowed ...T) error {
+	if value == "" || slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("invalid ConversionOptions.%s %q: expected one of %q", field, value, allowed)
+}
+
+// The kinds of ConversionError.
+const (
+	// ConversionErrorMaxDepthExceeded is reported when the input nests
+	// elements deeper than ConversionOptions.MaxDepth.
+	ConversionErrorMaxDepthExceeded = "max_depth_exceeded"
+
+	// ConversionErrorInputTooLarge is reported when the input is larger than
+	// ConversionOptions.MaxInputBytes.
+	ConversionErrorInputTooLarge = "input_too_large"
+
+	// ConversionErrorTimeout is reported when the call stops waiting for a
+	// conversion still running after ConversionOptions.Timeout.
+	ConversionErrorTimeout = "timeout"
+)
+
+// ConversionError is returned by ConvertWithOptions when the input is
+// rejected before conversion. Use errors.As to inspect its Kind.
+type ConversionError struct {
+	// Kind identifies the failure, such as ConversionErrorMaxDepthExceeded.
+	Kind string
+
+	// Message describes the failure.
+	Message string
+}
+
+func (e *ConversionError) Error() string {
+	return "html-to-markdown conversion failed (" + e.Kind + "): " + e.Message
+}
+
+// ConvertWithOptions converts HTML to Markdown using the given options.
+//
+// It returns an error if an option has an unsupported value or the conversion fails.
+//
+// Example:
+//
+//	markdown, err := htmltomarkdown.ConvertWithOptions(html, htmltomarkdown.ConversionOptions{
+//	    TableSpanMode: htmltomarkdown.TableSpanModeExpand,
+//	})
+func ConvertWithOptions(html string, opts ConversionOptions) (string, error) {
+	if html == "" {
+		return "", nil
+	}
+	if err := opts.checkInput(html); err != nil {
+		return "", err
+	}
+	if opts.Timeout > 0 {
+		return convertWithTimeout(opts.Timeout, func() (string, error) {
+			return convertDocument(html, &opts)
+		})
+	}
+	return convertDocument(html, &opts)
+}
+
+// checkInput validates o and the size of html against MaxInputBytes.
+func (o *ConversionOptions) checkInput(html string) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	if o.MaxInputBytes > 0 && len(html) > o.MaxInputBytes {
+		return &ConversionError{
+			Kind:    ConversionErrorInputTooLarge,
+			Message: fmt.Sprintf("input of %d bytes exceeds the limit of %d bytes", len(html), o.MaxInputBytes),
+		}
+	}
+	return nil
+}
+
+// checkDepth rejects root when it nests elements deeper than MaxDepth.
+func (o *ConversionOptions) checkDepth(root *htmlNode) error {
+	if o.MaxDepth > 0 && root.maxDepth(o.MaxDepth) > o.MaxDepth {
+		return &ConversionError{
+			Kind:    ConversionErrorMaxDepthExceeded,
+			Message: fmt.Sprintf("element nesting exceeds the maximum depth of %d", o.MaxDepth),
+		}
+	}
+	return nil
+}
+
+// convertWithTimeout runs convert in a goroutine and stops waiting for it
+// once timeout has elapsed. The goroutine is left to finish on its own.
+func convertWithTimeout(timeout time.Duration, convert func() (string, error)) (string, error) {
+	type result struct {
+		markdown string
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		markdown, err := convert()
+		done <- result{markdown, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.markdown, r.err
+	case <-timer.C:
+		return "", &ConversionError{
+			Kind:    ConversionErrorTimeout,
+			Message: fmt.Sprintf("stopped waiting for the conversion after %s", timeout),
+		}
+	}
+}
+
+// convertDocument converts html with opts, passing the source unchanged to
+// the native library when no option or default transform applies to the
+// parsed document.
+func convertDocument(html string, opts *ConversionOptions) (string, error) {
+	traceConversion()
+	done := tracePhase(phaseParse)
+	root, parseErr := parseDocument(html)
+	done()
+
+	done = tracePhase(phaseNormalize)
+	direct := opts.isZero() && (parseErr != nil || !needsDefaultTransforms(root))
+	fragments := &fragmentSet{}
+	var err error
+	if !direct {
+		if err = parseErr; err == nil {
+			err = opts.checkDepth(root)
+		}
+		if err == nil {
+			err = applyHTMLOptions(root, opts, fragments)
+		}
+	}
+	done()
+	if err != nil {
+		return "", err
+	}
+
+	if direct {
+		done := tracePhase(phaseRender)
+		markdown, err := convertFFI(html)
+		done()
+		if err != nil {
+			return "", err
+		}
+		return applyTrailingNewline(markdown, opts.TrailingNewline), nil
+	}
+	return renderTree(root, opts, fragments)
+}
+
+// needsDefaultTransforms reports whether a transform applied by the zero
+// ConversionOptions would change root, so that converting the source
+// directly would give a different result.
+func needsDefaultTransforms(root *htmlNode) bool {
+	return hasAlignedTables(root) || hasPicture(root) || hasLazyLoadImages(root, defaultLazyLoadAttrs)
+}
+
+// renderTree runs the native conversion of the normalized root and restores
+// the Go-side fragments.
+func renderTree(root *htmlNode, opts *ConversionOptions, fragments *fragmentSet) (string, error) {
+	done := tracePhase(phaseRender)
+	defer done()
+	markdown, err := convertFFI(root.render())
+	if err != nil {
+		return "", err
+	}
+	return finishMarkdown(markdown, opts, fragments), nil
+}
+
+// finishMarkdown restores the Go-side fragments and markers in the native
+// library's output.
+func finishMarkdown(markdown string, opts *ConversionOptions, fragments *fragmentSet) string {
+	markdown = applySetextHeadings(applyListMarkers(fragments.restore(markdown)))
+	return applyTrailingNewline(markdown, opts.TrailingNewline)
+}
+
+// ConvertWithMetadataOptions converts html like ConvertWithMetadata, applying
+// opts as ConvertWithOptions does. Metadata is extracted from the document as
+// transformed by the options, and ExtendedMetadata.HeadingChanges lists the
+// headings rewritten by NormalizeHeadingHierarchy.
+func ConvertWithMetadataOptions(html string, opts ConversionOptions) (MetadataExtraction, error) {
+	if html == "" {
+		return MetadataExtraction{}, nil
+	}
+	if err := opts.checkInput(html); err != nil {
+		return MetadataExtraction{}, err
+	}
+	if opts.Timeout > 0 {
+		var result MetadataExtraction
+		_, err := convertWithTimeout(opts.Timeout, func() (string, error) {
+			var err error
+			result, err = convertDocumentWithMetadata(html, &opts)
+			return "", err
+		})
+		if err != nil {
+			return MetadataExtraction{}, err
+		}
+		return result, nil
+	}
+	return convertDocumentWithMetadata(html, &opts)
+}
+
+func convertDocumentWithMetadata(html string, opts *ConversionOptions) (MetadataExtraction, error) {
+	traceConversion()
+	done := tracePhase(phaseParse)
+	root, err := parseDocument(html)
+	done()
+	if err != nil {
+		return MetadataExtraction{}, err
+	}
+
+	done = tracePhase(phaseNormalize)
+	fragments := &fragmentSet{}
+	if err = opts.checkDepth(root); err == nil {
+		err = applyHTMLOptions(root, opts, fragments)
+	}
+	done()
+	if err != nil {
+		return MetadataExtraction{}, err
+	}
+
+	done = tracePhase(phaseRender)
+	defer done()
+	result, err := ConvertWithMetadata(root.render())
+	if err != nil {
+		return MetadataExtraction{}, err
+	}
+	result.Markdown = finishMarkdown(result.Markdown, opts, fragments)
+	result.Metadata.HeadingChanges = fragments.headingChanges
+	return result, nil
+}
+
+// applyTrailingNewline normalizes the newlines ending markdown. Trailing
+// spaces on the last line are kept, since they may form a hard line break.
+func applyTrailingNewline(markdown string, mode TrailingNewline) string {
+	switch mode {
+	case TrailingNewlinePreserve:
+		return markdown
+	case TrailingNewlineNone:
+		return strings.TrimRight(markdown, "\r\n")
+	default:
+		trimmed := strings.TrimRight(markdown, "\r\n")
+		if trimmed == "" {
+			return ""
+		}
+		return trimmed + "\n"
+	}
+}
+
+// MustConvertWithOptions is like ConvertWithOptions but panics if an error occurs.
+func MustConvertWithOptions(html string, opts ConversionOptions) string {
+	markdown, err := ConvertWithOptions(html, opts)
+	if err != nil {
+		panic(err)
+	}
+	return markdown
+}
+
+// applyHTMLOptions rewrites the parsed tree for options that act on the HTML
+// before the native conversion.
+func applyHTMLOptions(root *htmlNode, opts *ConversionOptions, fragments *fragmentSet) error {
+	if len(opts.ElementOverrides) > 0 {
+		applyElementOverrides(root, opts.ElementOverrides, fragments)
+	}
+	if opts.SkipHidden || opts.SkipInvisible || opts.DropAriaHidden {
+		removeHiddenElements(root, opts.SkipHidden, opts.SkipInvisible, opts.DropAriaHidden)
+	}
+	if opts.ChangeTrackingStyle != "" && opts.ChangeTrackingStyle != ChangeTrackingStyleMarkdown {
+		renderEdits(root, opts.ChangeTrackingStyle, fragments)
+	}
+	if opts.UnwrapRedundantContainers {
+		unwrapRedundantContainers(root)
+	}
+	if opts.HeadingOffset != 0 {
+		shiftHeadings(root, opts.HeadingOffset)
+	}
+	if opts.NormalizeHeadingHierarchy {
+		fragments.headingChanges = normalizeHeadingHierarchy(root)
+	}
+	if opts.GenerateTOC {
+		insertTOC(root, fragments)
+	}
+	if opts.CollapseHorizontalRules || opts.TrimHorizontalRules {
+		removeRedundantRules(root, opts.CollapseHorizontalRules, opts.TrimHorizontalRules)
+	}
+	if opts.HorizontalRuleStyle != "" {
+		renderHorizontalRules(root, opts.HorizontalRuleStyle, fragments)
+	}
+	if opts.FormStyle != "" {
+		renderForms(root, opts.FormStyle, fragments)
+	}
+	if opts.SelectStyle != "" {
+		renderSelects(root, opts.SelectStyle)
+	}
+	if opts.TextareaStyle != "" {
+		renderTextareas(root, opts.TextareaStyle, fragments)
+	}
+	if opts.StripCodeLineNumbers {
+		stripCodeLineNumbers(root, opts.codeLanguageClasses(), fragments)
+	}
+	if len(opts.CodeLanguageClasses) > 0 || opts.PreserveWhitespaceInPre {
+		renderCodeBlocks(root, opts.codeLanguageClasses(), fragments)
+	}
+	applyLazyLoadAttrs(root, opts.lazyLoadAttrs())
+	normalizePictures(root)
+	if opts.SrcsetSelection == SrcsetSelectionHighest || opts.SrcsetSelection == SrcsetSelectionLowest {
+		selectImageSources(root, opts.SrcsetSelection)
+	}
+	if opts.WbrStyle != "" {
+		renderWordBreaks(root, opts.WbrStyle)
+	}
+	if opts.NormalizeNbsp || opts.NormalizeUnicodeSpaces {
+		normalizeSpaces(root, opts.NormalizeUnicodeSpaces)
+	}
+	if opts.SmartPunctuation == SmartPunctuationAsciify || opts.SmartPunctuation == SmartPunctuationSmarten {
+		normalizePunctuation(root, opts.SmartPunctuation)
+	}
+	if opts.EscapeMode == EscapeModeSmart || opts.EscapeMode == EscapeModeAll {
+		escapeMarkdownText(root, opts.EscapeMode)
+	}
+	if opts.Footnotes {
+		if err := convertFootnotes(root, fragments); err != nil {
+			return err
+		}
+	}
+	if opts.KeepNamedAnchors {
+		keepNamedAnchors(root, fragments)
+	}
+	if opts.BlockInLinkMode != "" {
+		liftBlockLinks(root, opts.BlockInLinkMode)
+	}
+	if opts.DropEmptyImages {
+		dropEmptyImages(root)
+	}
+	if opts.DropEmptyLinks {
+		dropEmptyLinks(root)
+	}
+	if opts.TrimLinkText {
+		trimLinkText(root)
+	}
+	if opts.LinkStyle == LinkStyleTextOnly || opts.LinkStyle == LinkStyleTextWithURL {
+		applyLinkStyle(root, opts.LinkStyle, opts.OmitInternalLinkURLs)
+	}
+	if opts.TimeStyle == TimeStyleISO || opts.TimeStyle == TimeStyleBoth {
+		renderTimes(root, opts.TimeStyle)
+	}
+	if opts.InferFormattingFromStyle {
+		inferStyleFormatting(root)
+	}
+	if opts.MergeAdjacentFormatting {
+		mergeAdjacentFormatting(root)
+	}
+	if opts.EmojiShortcodes {
+		replaceEmojiShortcodes(root)
+	}
+	if opts.ImageAltInText {
+		replaceImagesWithAlt(root)
+	}
+	if opts.ListSpacing != "" {
+		applyListSpacing(root, opts.ListSpacing)
+	}
+	if opts.RespectOrderedListStart {
+		markOrderedLists(root)
+	}
+	if opts.SubSupStyle != "" {
+		renderSubSup(root, opts.SubSupStyle, fragments)
+	}
+	if opts.MathStyle != "" {
+		renderTeXElements(root, opts.MathStyle, opts.mathClasses(), opts.mathScriptTypes(), fragments)
+		renderMath(root, opts.MathStyle, fragments)
+	}
+	if opts.RubyStyle != "" {
+		renderRuby(root, opts.RubyStyle, fragments)
+	}
+	if opts.QuoteStyle != "" {
+		renderQuotes(root, opts.QuoteStyle, fragments)
+	}
+	if opts.CiteStyle != "" {
+		renderInlineSemantics(root, "cite", opts.CiteStyle, fragments)
+	}
+	if opts.DfnStyle != "" {
+		renderInlineSemantics(root, "dfn", opts.DfnStyle, fragments)
+	}
+	if opts.HighlightStyle != "" {
+		renderHighlights(root, opts.HighlightStyle, fragments)
+	}
+	if opts.DefinitionListStyle != "" {
+		if err := renderDefinitionLists(root, opts.DefinitionListStyle, fragments); err != nil {
+			return err
+		}
+	}
+	if opts.FigureStyle != "" {
+		if err := renderFigures(root, opts.FigureStyle, fragments); err != nil {
+			return err
+		}
+	}
+	if opts.AddressStyle != "" {
+		if err := renderAddresses(root, opts.AddressStyle, fragments); err != nil {
+			return err
+		}
+	}
+	if len(opts.AdmonitionClasses) > 0 {
+		if err := renderAdmonitions(root, opts.AdmonitionClasses, fragments); err != nil {
+			return err
+		}
+	}
+	if opts.HeadingStyle == HeadingStyleSetext {
+		markSetextHeadings(root)
+	}
+	if opts.RowHeaderStyle == RowHeaderStyleBold {
+		boldRowHeaders(root)
+	}
+	if opts.TableSectionStyle != "" {
+		normalizeTableSections(root, opts.TableSectionStyle)
+	}
+	if opts.TableCaptionStyle != "" {
+		if err := renderTableCaptions(root, opts.TableCaptionStyle, fragments); err != nil {
+			return err
+		}
+	}
+	if opts.TableSpanMode != "" {
+		normalizeTableSpans(root, opts.TableSpanMode)
+	}
+	if opts.TableFormat == TableFormatCSV || opts.TableFormat == TableFormatTSV {
+		if err := renderDelimitedTables(root, opts.TableFormat, fragments); err != nil {
+			return err
+		}
+	}
+	return alignTables(root, fragments)
+}
@@ -0,0 +1,1906 @@
+//go:build cgo
+
+package htmltomarkdown
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConversionOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ConversionOptions
+		wantErr bool
+	}{
+		{name: "zero value", opts: ConversionOptions{}},
+		{name: "expand spans", opts: ConversionOptions{TableSpanMode: TableSpanModeExpand}},
+		{name: "ignore spans", opts: ConversionOptions{TableSpanMode: TableSpanModeIgnore}},
+		{name: "unknown span mode", opts: ConversionOptions{TableSpanMode: "merge"}, wantErr: true},
+		{name: "csv tables", opts: ConversionOptions{TableFormat: TableFormatCSV}},
+		{name: "unknown table format", opts: ConversionOptions{TableFormat: "xlsx"}, wantErr: true},
+		{name: "spaced rule", opts: ConversionOptions{HorizontalRuleStyle: "* * *"}},
+		{name: "short rule", opts: ConversionOptions{HorizontalRuleStyle: "--"}, wantErr: true},
+		{name: "mixed rule", opts: ConversionOptions{HorizontalRuleStyle: "-*-"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_InvalidOption(t *testing.T) {
+	_, err := ConvertWithOptions("<p>x</p>", ConversionOptions{TableSpanMode: "merge"})
+	if err == nil || !strings.Contains(err.Error(), "TableSpanMode") {
+		t.Errorf("ConvertWithOptions error = %v, expected invalid TableSpanMode error", err)
+	}
+}
+
+func TestConvertWithOptions_TableSpanMode(t *testing.T) {
+	html := `<table>
+<tr><th>A</th><th>B</th></tr>
+<tr><td colspan="2">Merged</td></tr>
+</table>`
+
+	tests := []struct {
+		name     string
+		mode     TableSpanMode
+		expected []string
+	}{
+		{
+			name:     "expand",
+			mode:     TableSpanModeExpand,
+			expected: []string{"| A | B |", "| --- | --- |", "| Merged | Merged |"},
+		},
+		{
+			name:     "ignore",
+			mode:     TableSpanModeIgnore,
+			expected: []string{"| A | B |", "| --- | --- |", "| Merged |  |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{TableSpanMode: tt.mode})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, line := range tt.expected {
+				if !strings.Contains(result, line+"\n") {
+					t.Errorf("Result = %q, expected line %q", result, line)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_TableFormat(t *testing.T) {
+	html := `<p>Prices:</p>
+<table>
+<tr><th>Item</th><th>Notes</th></tr>
+<tr><td>Apples, red</td><td>Say "crisp"</td></tr>
+<tr><td>Pears</td><td>Line one<br>Line two</td></tr>
+</table>`
+
+	tests := []struct {
+		name     string
+		format   TableFormat
+		expected string
+	}{
+		{
+			name:     "csv",
+			format:   TableFormatCSV,
+			expected: "```csv\nItem,Notes\n\"Apples, red\",\"Say \"\"crisp\"\"\"\nPears,\"Line one\nLine two\"\n```",
+		},
+		{
+			name:     "tsv",
+			format:   TableFormatTSV,
+			expected: "```tsv\nItem\tNotes\nApples, red\t\"Say \"\"crisp\"\"\"\nPears\t\"Line one\nLine two\"\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{TableFormat: tt.format})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+			if strings.Contains(result, "| --- |") {
+				t.Errorf("Result = %q, expected no pipe table", result)
+			}
+			if !strings.Contains(result, "Prices:") {
+				t.Errorf("Result = %q, expected surrounding content", result)
+			}
+		})
+	}
+
+	result, err := ConvertWithOptions(html, ConversionOptions{TableFormat: TableFormatMarkdown})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "| Item | Notes |") {
+		t.Errorf("Result = %q, expected a pipe table for TableFormatMarkdown", result)
+	}
+}
+
+func TestConvertWithOptions_ListSpacing(t *testing.T) {
+	simple := `<ul><li>Alpha</li><li>Beta</li></ul>`
+	paragraphs := `<ul><li><p>Alpha</p></li><li><p>Beta</p></li></ul>`
+	mixed := `<ul><li>Alpha</li><li><p>Beta</p><p>More beta</p></li></ul>`
+
+	tests := []struct {
+		name     string
+		html     string
+		spacing  ListSpacing
+		expected string
+	}{
+		{name: "tight simple list", html: simple, spacing: ListSpacingTight, expected: "- Alpha\n- Beta\n"},
+		{name: "loose simple list stays tight", html: simple, spacing: ListSpacingLoose, expected: "- Alpha\n- Beta\n"},
+		{name: "tight paragraph items", html: paragraphs, spacing: ListSpacingTight, expected: "- Alpha\n- Beta\n"},
+		{name: "loose paragraph items", html: paragraphs, spacing: ListSpacingLoose, expected: "- Alpha\n\n- Beta\n"},
+		{name: "loose mixed items", html: mixed, spacing: ListSpacingLoose, expected: "- Alpha\n\n- Beta\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{ListSpacing: tt.spacing})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_RespectOrderedListStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "start",
+			html:     `<ol start="3"><li>Three</li><li>Four</li></ol>`,
+			expected: "3. Three\n4. Four\n",
+		},
+		{
+			name:     "reversed",
+			html:     `<ol reversed><li>Three</li><li>Two</li><li>One</li></ol>`,
+			expected: "3. Three\n2. Two\n1. One\n",
+		},
+		{
+			name:     "lower alpha",
+			html:     `<ol type="a" start="2"><li>Bee</li><li>Sea</li></ol>`,
+			expected: "b. Bee\nc. Sea\n",
+		},
+		{
+			name:     "item value",
+			html:     `<ol><li>One</li><li value="7">Seven</li><li>Eight</li></ol>`,
+			expected: "1. One\n7. Seven\n8. Eight\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{RespectOrderedListStart: true})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_DefinitionListStyle(t *testing.T) {
+	html := `<dl>
+<dt>Apple</dt><dd>A red fruit.</dd>
+<dt>Orange</dt><dd>A citrus fruit.</dd><dd>A color.</dd>
+</dl>`
+
+	tests := []struct {
+		name     string
+		style    DefinitionListStyle
+		expected string
+	}{
+		{
+			name:     "colon",
+			style:    DefinitionListStyleColon,
+			expected: "Apple\n: A red fruit.\n\nOrange\n: A citrus fruit.\n: A color.\n",
+		},
+		{
+			name:     "bold",
+			style:    DefinitionListStyleBold,
+			expected: "**Apple**\nA red fruit.\n\n**Orange**\nA citrus fruit.\nA color.\n",
+		},
+		{
+			name:     "html",
+			style:    DefinitionListStyleHTML,
+			expected: "<dt>Orange</dt><dd>A citrus fruit.</dd><dd>A color.</dd>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{DefinitionListStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_SubSupStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    SubSupStyle
+		expected []string
+	}{
+		{name: "markdown", style: SubSupStyleMarkdown, expected: []string{"H~2~O", "x^2^"}},
+		{name: "html", style: SubSupStyleHTML, expected: []string{"H<sub>2</sub>O", "x<sup>2</sup>"}},
+		{name: "unicode", style: SubSupStyleUnicode, expected: []string{"H₂O", "x²", "<sup>TM</sup>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := `<p>H<sub>2</sub>O and x<sup>2</sup> by Acme<sup>TM</sup></p>`
+			result, err := ConvertWithOptions(html, ConversionOptions{SubSupStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("Result = %q, expected to contain %q", result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_EmojiShortcodes(t *testing.T) {
+	html := `<p>Great job <img class="emoji" alt="😄" src="smile.png"> 👍 <code>🚀</code></p>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{EmojiShortcodes: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	for _, want := range []string{"Great job :smile: :+1:", "`🚀`"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Result = %q, expected to contain %q", result, want)
+		}
+	}
+	if strings.Contains(result, "smile.png") {
+		t.Errorf("Result = %q, expected the emoji image to be replaced", result)
+	}
+}
+
+func TestConvertWithOptions_HighlightStyle(t *testing.T) {
+	html := `<p>Read <mark>this <strong>now</strong></mark> please.</p>`
+
+	tests := []struct {
+		name     string
+		style    HighlightStyle
+		expected string
+	}{
+		{name: "double equals", style: HighlightStyleDoubleEquals, expected: "Read ==this **now**== please."},
+		{name: "html", style: HighlightStyleHTML, expected: "Read <mark>this **now**</mark> please."},
+		{name: "none", style: HighlightStyleNone, expected: "Read this **now** please."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{HighlightStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		mode     TrailingNewline
+		want     string
+	}{
+		{name: "default adds newline", markdown: "Text", want: "Text\n"},
+		{name: "single collapses newlines", markdown: "Text\n\n\n", mode: TrailingNewlineSingle, want: "Text\n"},
+		{name: "single keeps empty output", markdown: "\n\n", mode: TrailingNewlineSingle, want: ""},
+		{name: "single keeps hard break spaces", markdown: "Text  \n\n", mode: TrailingNewlineSingle, want: "Text  \n"},
+		{name: "none strips newlines", markdown: "Text\r\n\n", mode: TrailingNewlineNone, want: "Text"},
+		{name: "preserve keeps newlines", markdown: "Text\n\n\n", mode: TrailingNewlinePreserve, want: "Text\n\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyTrailingNewline(tt.markdown, tt.mode); got != tt.want {
+				t.Errorf("applyTrailingNewline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_TrailingNewline(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		opts   ConversionOptions
+		suffix string
+	}{
+		{name: "paragraph", html: `<p>Hello</p>`, suffix: "Hello\n"},
+		{name: "paragraph without newline", html: `<p>Hello</p>`, opts: ConversionOptions{TrailingNewline: TrailingNewlineNone}, suffix: "Hello"},
+		{name: "table", html: `<table><tr><th>A</th></tr><tr><td>1</td></tr></table>`, suffix: "| 1 |\n"},
+		{name: "fenced block", html: `<table><tr><td>A</td></tr></table>`, opts: ConversionOptions{TableFormat: TableFormatCSV}, suffix: "A\n```\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.HasSuffix(result, tt.suffix) || strings.HasSuffix(result, tt.suffix+"\n") {
+				t.Errorf("Result = %q, expected to end with exactly %q", result, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_EscapeMode(t *testing.T) {
+	html := `<p>1. not a list with *stars* and snake_case</p><table><tr><th>Expr</th></tr><tr><td>a | b</td></tr></table>`
+
+	tests := []struct {
+		name     string
+		mode     EscapeMode
+		expected []string
+	}{
+		{name: "smart", mode: EscapeModeSmart, expected: []string{`1\. not a list with \*stars\* and snake_case`, `| a \| b |`}},
+		{name: "all", mode: EscapeModeAll, expected: []string{`1\. not a list with \*stars\* and snake\_case`, `| a \| b |`}},
+		{name: "none", mode: EscapeModeNone, expected: []string{"1. not a list with *stars* and snake_case"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{EscapeMode: tt.mode})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("Result = %q, expected to contain %q", result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_KeepNamedAnchors(t *testing.T) {
+	html := `<a name="top"></a><h1>Title</h1><p>Body <a name="note">text</a>.</p><p><a href="#top">Back</a></p>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{KeepNamedAnchors: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	anchor := strings.Index(result, `<a name="top"></a>`)
+	heading := strings.Index(result, "# Title")
+	if anchor < 0 || heading < 0 || anchor > heading {
+		t.Errorf("Result = %q, expected the top anchor before the heading", result)
+	}
+	for _, want := range []string{`Body <a name="note"></a>text.`, "[Back](#top)"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Result = %q, expected to contain %q", result, want)
+		}
+	}
+
+	result, err = ConvertWithOptions(html, ConversionOptions{})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if strings.Contains(result, `name="top"`) {
+		t.Errorf("Result = %q, expected named anchors to be dropped by default", result)
+	}
+}
+
+func TestConvertWithOptions_FigureStyle(t *testing.T) {
+	html := `<figure><figcaption>A scenic view</figcaption><img src="view.png" alt="View"></figure>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{FigureStyle: FigureStyleCaptionBelow})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "![View](view.png)\n\n*A scenic view*") {
+		t.Errorf("Result = %q, expected the caption on its own line under the image", result)
+	}
+
+	result, err = ConvertWithOptions(html, ConversionOptions{FigureStyle: FigureStyleHTML})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, `<figure><figcaption>A scenic view</figcaption><img alt="View" src="view.png"></figure>`) {
+		t.Errorf("Result = %q, expected the figure preserved as HTML", result)
+	}
+}
+
+func TestConvertWithOptions_AdmonitionClasses(t *testing.T) {
+	html := `<div class="callout warning"><p>Back up your data first.</p><p>This cannot be undone.</p></div>
+<div class="note">Requires version 2.</div>
+<div class="tip">Unmapped.</div>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{
+		AdmonitionClasses: map[string]string{"warning": "WARNING", "note": "NOTE"},
+	})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	for _, expected := range []string{
+		"> [!WARNING]\n> Back up your data first.\n>\n> This cannot be undone.",
+		"> [!NOTE]\n> Requires version 2.",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Result = %q, expected it to contain %q", result, expected)
+		}
+	}
+	if strings.Contains(result, "[!TIP]") {
+		t.Errorf("Result = %q, expected unmapped classes to render normally", result)
+	}
+
+	_, err = ConvertWithOptions(html, ConversionOptions{AdmonitionClasses: map[string]string{"note": ""}})
+	if err == nil || !strings.Contains(err.Error(), "AdmonitionClasses") {
+		t.Errorf("ConvertWithOptions error = %v, expected invalid AdmonitionClasses error", err)
+	}
+}
+
+func TestConvertWithOptions_AddressStyle(t *testing.T) {
+	html := `<address>Jane Doe<br>
+12 Main Street<br>
+Springfield<br><a href="mailto:jane@example.com">jane@example.com</a></address>`
+
+	tests := []struct {
+		name     string
+		style    AddressStyle
+		expected string
+	}{
+		{
+			name:     "italic",
+			style:    AddressStyleItalic,
+			expected: "*Jane Doe*  \n*12 Main Street*  \n*Springfield*  \n*[jane@example.com](mailto:jane@example.com)*",
+		},
+		{
+			name:     "blockquote",
+			style:    AddressStyleBlockquote,
+			expected: "> Jane Doe  \n> 12 Main Street  \n> Springfield  \n> [jane@example.com](mailto:jane@example.com)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{AddressStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_HorizontalRuleStyle(t *testing.T) {
+	html := `<p>Before</p><hr><p>Between</p><div>Inline<hr>text</div>`
+
+	for _, marker := range []string{"***", "___", "- - -"} {
+		t.Run(marker, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{HorizontalRuleStyle: marker})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, expected := range []string{
+				"Before\n\n" + marker + "\n\nBetween",
+				"Inline\n\n" + marker + "\n\ntext",
+			} {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Result = %q, expected it to contain %q", result, expected)
+				}
+			}
+			if strings.Contains(result, "---") {
+				t.Errorf("Result = %q, expected no native rule", result)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_RedundantHorizontalRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name:     "doubled rules",
+			html:     "<p>A</p><hr><hr>\n<section></section><hr><p>B</p>",
+			opts:     ConversionOptions{CollapseHorizontalRules: true},
+			expected: "A\n\n---\n\nB",
+		},
+		{
+			name:     "leading rule",
+			html:     `<hr><p>A</p><hr><p>B</p>`,
+			opts:     ConversionOptions{TrimHorizontalRules: true},
+			expected: "A\n\n---\n\nB",
+		},
+		{
+			name:     "trailing rules",
+			html:     `<p>A</p><hr><div><hr></div>`,
+			opts:     ConversionOptions{TrimHorizontalRules: true},
+			expected: "A",
+		},
+		{
+			name:     "image between rules",
+			html:     `<hr><img src="a.png" alt="A"><hr>`,
+			opts:     ConversionOptions{CollapseHorizontalRules: true},
+			expected: "---\n\n![A](a.png)\n\n---",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_ChangeTrackingStyle(t *testing.T) {
+	html := `<p>The meeting is on <del datetime="2024-05-01">Monday</del><ins>Tuesday</ins>.</p>`
+
+	tests := []struct {
+		style    ChangeTrackingStyle
+		expected string
+	}{
+		{ChangeTrackingStyleMarkdown, "The meeting is on ~~Monday~~"},
+		{ChangeTrackingStyleHTML, `The meeting is on <del datetime="2024-05-01">Monday</del><ins>Tuesday</ins>.`},
+		{ChangeTrackingStyleAccept, "The meeting is on Tuesday."},
+		{ChangeTrackingStyleReject, "The meeting is on Monday."},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{ChangeTrackingStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected it to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_TableCaptionStyle(t *testing.T) {
+	html := `<table>
+<tr><th>Name</th><th>Qty</th></tr>
+<caption>Monthly <a href="/stock">inventory</a></caption>
+<tr><td>Apples</td><td>3</td></tr>
+</table>`
+
+	tests := []struct {
+		style   TableCaptionStyle
+		caption string
+	}{
+		{TableCaptionStyleItalic, "*Monthly [inventory](/stock)*"},
+		{TableCaptionStyleBold, "**Monthly [inventory](/stock)**"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{TableCaptionStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			expected := tt.caption + "\n\n| Name | Qty |"
+			if !strings.HasPrefix(result, expected) {
+				t.Errorf("Result = %q, expected the caption before the header row: %q", result, expected)
+			}
+			if strings.Count(result, "Monthly") != 1 {
+				t.Errorf("Result = %q, expected the caption once", result)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_TableSectionStyle(t *testing.T) {
+	html := `<table>
+<thead>
+<tr><th colspan="2">Fruit</th><th>Stock</th></tr>
+<tr><th>Name</th><th>Origin</th><th>Qty</th></tr>
+</thead>
+<tfoot><tr><td>Total</td><td></td><td>5</td></tr></tfoot>
+<tbody>
+<tr><td>Apples</td><td>Spain</td><td>3</td></tr>
+<tr><td>Pears</td><td>Italy</td><td>2</td></tr>
+</tbody>
+</table>`
+
+	tests := []struct {
+		style    TableSectionStyle
+		expected []string
+	}{
+		{TableSectionStyleSource, []string{
+			"| Fruit Name | Origin | Stock Qty |",
+			"| Total | | 5 |",
+			"| Apples | Spain | 3 |",
+			"| Pears | Italy | 2 |",
+		}},
+		{TableSectionStyleFooterLast, []string{
+			"| Fruit Name | Origin | Stock Qty |",
+			"| Apples | Spain | 3 |",
+			"| Pears | Italy | 2 |",
+			"| Total | | 5 |",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{TableSectionStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			var rows []string
+			separators := 0
+			for _, line := range strings.Split(strings.TrimSpace(result), "\n") {
+				if delimiterRowPattern.MatchString(line) {
+					separators++
+					if len(rows) != 1 {
+						t.Errorf("Result = %q, expected the separator right after the header row", result)
+					}
+					continue
+				}
+				rows = append(rows, strings.Join(strings.Fields(line), " "))
+			}
+			if separators != 1 {
+				t.Errorf("Result = %q, expected exactly one separator row, got %d", result, separators)
+			}
+			if !slices.Equal(rows, tt.expected) {
+				t.Errorf("Rows = %q, expected %q", rows, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_RowHeaderStyle(t *testing.T) {
+	html := `<table>
+<tr><td></td><th scope="col">Q1</th><th scope="col">Q2</th></tr>
+<tr><th scope="row">North</th><td>10</td><td>12</td></tr>
+<tr><th scope="row">South</th><td>8</td><td>9</td></tr>
+</table>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{RowHeaderStyle: RowHeaderStyleBold})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	for _, expected := range []string{"| **North** | 10 | 12 |", "| **South** | 8 | 9 |"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Result = %q, expected it to contain %q", result, expected)
+		}
+	}
+	if strings.Contains(result, "**Q1**") {
+		t.Errorf("Result = %q, expected column headers unchanged", result)
+	}
+
+	result, err = ConvertWithOptions(html, ConversionOptions{RowHeaderStyle: RowHeaderStylePlain})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if strings.Contains(result, "**") {
+		t.Errorf("Result = %q, expected plain row headers", result)
+	}
+}
+
+func TestConvertWithOptions_Footnotes(t *testing.T) {
+	html := `<p>Water boils at 100 degrees<sup id="ref1"><a href="#fn1">[1]</a></sup> at sea level<sup id="ref2"><a href="#fn2">[2]</a></sup>.</p>
+<section class="footnotes">
+<ol>
+<li id="fn2">Air pressure of one atmosphere. <a href="#ref2">↩</a></li>
+<li id="fn1">Celsius scale. <a href="#ref1">↩</a></li>
+</ol>
+</section>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{Footnotes: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	for _, want := range []string{
+		"Water boils at 100 degrees[^1] at sea level[^2].",
+		"[^1]: Celsius scale.\n",
+		"[^2]: Air pressure of one atmosphere.\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Result = %q, expected to contain %q", result, want)
+		}
+	}
+	if strings.Contains(result, "↩") || strings.Contains(result, "1. ") {
+		t.Errorf("Result = %q, expected back links and the footnote list to be removed", result)
+	}
+}
+
+func TestConvertWithOptions_LinkStyleTextOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "text link",
+			html:     `<p>Read the <a href="https://example.com/guide" title="Guide">guide</a> first.</p>`,
+			expected: "Read the guide first.\n",
+		},
+		{
+			name:     "autolink",
+			html:     `<p>See <a href="https://example.com">https://example.com</a>.</p>`,
+			expected: "See https://example.com.\n",
+		},
+		{
+			name:     "image link",
+			html:     `<p><a href="https://example.com"><img src="logo.png" alt="Logo"></a></p>`,
+			expected: "![Logo](logo.png)\n",
+		},
+		{
+			name:     "empty link",
+			html:     `<p>Before<a href="https://example.com"></a> after</p>`,
+			expected: "Before after\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{LinkStyle: LinkStyleTextOnly})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_LinkStyleTextWithURL(t *testing.T) {
+	html := `<p>Visit <a href="https://example.com/docs">the docs</a>, see <a href="#install">installation</a> or the <a href="/faq">FAQ</a>.</p>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{LinkStyle: LinkStyleTextWithURL})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	expected := "Visit the docs (https://example.com/docs), see installation (#install) or the FAQ (/faq).\n"
+	if result != expected {
+		t.Errorf("Result = %q, expected %q", result, expected)
+	}
+
+	result, err = ConvertWithOptions(html, ConversionOptions{LinkStyle: LinkStyleTextWithURL, OmitInternalLinkURLs: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	expected = "Visit the docs (https://example.com/docs), see installation or the FAQ.\n"
+	if result != expected {
+		t.Errorf("Result = %q, expected %q", result, expected)
+	}
+}
+
+func TestApplyLinkStyle_ComparesDecodedTextOnce(t *testing.T) {
+	root := parseHTML(`<p><a href="/q?x=&lt;">/q?x=&amp;lt;</a></p>`)
+	applyLinkStyle(root, LinkStyleTextWithURL, false)
+
+	expected := `<p>/q?x=&amp;lt; (/q?x=&lt;)</p>`
+	if got := root.findAll("body")[0].renderChildren(); got != expected {
+		t.Errorf("applyLinkStyle() rendered %q, expected %q", got, expected)
+	}
+}
+
+func TestConvertWithOptions_TimeStyle(t *testing.T) {
+	html := `<p>Published <time datetime="2024-01-01">Jan 1</time>.</p>`
+
+	tests := []struct {
+		name     string
+		html     string
+		style    TimeStyle
+		expected string
+	}{
+		{name: "text", html: html, style: TimeStyleText, expected: "Published Jan 1."},
+		{name: "iso", html: html, style: TimeStyleISO, expected: "Published 2024-01-01."},
+		{name: "both", html: html, style: TimeStyleBoth, expected: "Published Jan 1 (2024-01-01)."},
+		{name: "no datetime", html: `<p>Published <time>Jan 1</time>.</p>`, style: TimeStyleBoth, expected: "Published Jan 1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{TimeStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_RubyStyle(t *testing.T) {
+	html := `<p><ruby>漢<rp>(</rp><rt>kan</rt><rp>)</rp>字<rp>(</rp><rt>ji</rt><rp>)</rp></ruby> and <ruby><rb>東</rb><rtc><rt>tō</rt></rtc></ruby></p>`
+
+	tests := []struct {
+		name     string
+		style    RubyStyle
+		expected string
+	}{
+		{name: "parentheses", style: RubyStyleParentheses, expected: "漢(kan)字(ji) and 東(tō)"},
+		{name: "base only", style: RubyStyleBaseOnly, expected: "漢字 and 東"},
+		{name: "html", style: RubyStyleHTML, expected: "<ruby>漢<rp>(</rp><rt>kan</rt><rp>)</rp>字<rp>(</rp><rt>ji</rt><rp>)</rp></ruby> and <ruby><rb>東</rb><rtc><rt>tō</rt></rtc></ruby>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{RubyStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+			if strings.Contains(result, "((") || strings.Contains(result, "))") {
+				t.Errorf("Result = %q, expected <rp> parentheses not to be doubled", result)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_ImageAltInText(t *testing.T) {
+	html := `<p>See <img src="diagram.png" alt="Diagram"> below<img src="spacer.gif">.</p>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{ImageAltInText: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if result != "See Diagram below.\n" {
+		t.Errorf("Result = %q, expected %q", result, "See Diagram below.\n")
+	}
+
+	result, err = ConvertWithOptions(html, ConversionOptions{})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "![Diagram](diagram.png)") {
+		t.Errorf("Result = %q, expected the image to be kept by default", result)
+	}
+}
+
+func TestConvertWithOptions_MathStyle(t *testing.T) {
+	html := `<p>Half is <math><mfrac><mn>1</mn><mn>2</mn></mfrac></math> of one.</p>
+<math display="block"><mrow><msup><mi>e</mi><mrow><mi>i</mi><mi>π</mi></mrow></msup><mo>+</mo><mn>1</mn><mo>=</mo><msqrt><mn>0</mn></msqrt></mrow></math>`
+
+	tests := []struct {
+		name     string
+		style    MathStyle
+		expected []string
+	}{
+		{
+			name:     "latex",
+			style:    MathStyleLaTeX,
+			expected: []string{"Half is $\\frac{1}{2}$ of one.", "$$\ne^{i \\pi} + 1 = \\sqrt{0}\n$$"},
+		},
+		{
+			name:     "html",
+			style:    MathStyleHTML,
+			expected: []string{"Half is <math><mfrac><mn>1</mn><mn>2</mn></mfrac></math> of one."},
+		},
+		{
+			name:     "drop",
+			style:    MathStyleDrop,
+			expected: []string{"Half is of one."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{MathStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("Result = %q, expected to contain %q", result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_MathTeXSources(t *testing.T) {
+	html := `<p>Ratio <span class="math">\frac{a}{b}</span> here.</p>
+<script type="math/tex; mode=display">E = mc^2</script>
+<p>Inline <script type="math/tex">x < y</script> too.</p>`
+
+	result, err := ConvertWithOptions(html, ConversionOptions{MathStyle: MathStyleLaTeX})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	for _, want := range []string{`Ratio $\frac{a}{b}$ here.`, "$$\nE = mc^2\n$$", "Inline $x < y$ too."} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Result = %q, expected to contain %q", result, want)
+		}
+	}
+
+	result, err = ConvertWithOptions(`<p><span class="tex">\(x^2\)</span> and <span class="math">y</span></p>`,
+		ConversionOptions{MathStyle: MathStyleLaTeX, MathClasses: []string{"tex"}, MathScriptTypes: []string{}})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "$x^2$ and y") {
+		t.Errorf("Result = %q, expected only the configured class to be converted", result)
+	}
+}
+
+func TestConvertWithOptions_SmartPunctuation(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		style    SmartPunctuation
+		expected string
+	}{
+		{
+			name:     "preserve",
+			html:     `<p>She said “it’s fine” — mostly.</p>`,
+			style:    SmartPunctuationPreserve,
+			expected: "She said “it’s fine” — mostly.",
+		},
+		{
+			name:     "asciify",
+			html:     `<p>She said “it’s fine” — pages 3–5…</p>`,
+			style:    SmartPunctuationAsciify,
+			expected: `She said "it's fine" -- pages 3-5...`,
+		},
+		{
+			name:     "smarten",
+			html:     `<p>She said "it's <em>fine</em>" -- mostly, 'really'. <code>"raw"</code></p>`,
+			style:    SmartPunctuationSmarten,
+			expected: "She said “it’s *fine*” — mostly, ‘really’. `\"raw\"`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{SmartPunctuation: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_NormalizeNbsp(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name:     "paragraph",
+			html:     `<p>a&nbsp;b</p>`,
+			opts:     ConversionOptions{NormalizeNbsp: true},
+			expected: "a b",
+		},
+		{
+			name:     "code is kept",
+			html:     `<p><code>a&nbsp;b</code></p>`,
+			opts:     ConversionOptions{NormalizeNbsp: true},
+			expected: "`a\u00a0b`",
+		},
+		{
+			name:     "thin space kept by default",
+			html:     "<p>10\u2009km</p>",
+			opts:     ConversionOptions{NormalizeNbsp: true},
+			expected: "10\u2009km",
+		},
+		{
+			name:     "unicode spaces",
+			html:     "<p>10\u2009km&nbsp;away\u2003now</p>",
+			opts:     ConversionOptions{NormalizeUnicodeSpaces: true},
+			expected: "10 km away now",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_TrimLinkText(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "link",
+			html:     "<p><a href=\"https://example.com\">\n   text \n</a></p>",
+			expected: "[text](https://example.com)",
+		},
+		{
+			name:     "link with inline markup",
+			html:     "<p><a href=\"https://example.com\">\n  <strong>\n    bold\n  </strong>\n</a></p>",
+			expected: "[**bold**](https://example.com)",
+		},
+		{
+			name:     "image alt",
+			html:     "<p><img src=\"cat.png\" alt=\"\n   A cat\n   asleep\n\"></p>",
+			expected: "![A cat asleep](cat.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{TrimLinkText: true})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_BlockInLinkMode(t *testing.T) {
+	input := `<a href="/posts/hello"><h3>Hello world</h3><p>A short summary.</p></a>`
+	tests := []struct {
+		mode     BlockInLinkMode
+		expected []string
+	}{
+		{
+			mode:     BlockInLinkModeLift,
+			expected: []string{"### [Hello world](/posts/hello)", "[A short summary.](/posts/hello)"},
+		},
+		{
+			mode:     BlockInLinkModeInlineText,
+			expected: []string{"[Hello world A short summary.](/posts/hello)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			result, err := ConvertWithOptions(input, ConversionOptions{BlockInLinkMode: tt.mode})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("Result = %q, expected to contain %q", result, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_DropEmptyLinksAndImages(t *testing.T) {
+	opts := ConversionOptions{DropEmptyLinks: true, DropEmptyImages: true}
+	tests := []struct {
+		name       string
+		html       string
+		expected   string
+		unexpected string
+	}{
+		{
+			name:       "empty anchor",
+			html:       `<p>Before <a href="https://example.com"></a>after</p>`,
+			expected:   "Before after",
+			unexpected: "[](https://example.com)",
+		},
+		{
+			name:     "image-only anchor",
+			html:     `<p><a href="https://example.com"><img src="logo.png" alt="Logo"></a></p>`,
+			expected: "[![Logo](logo.png)](https://example.com)",
+		},
+		{
+			name:     "empty alt with src",
+			html:     `<p><img src="photo.png" alt=""></p>`,
+			expected: "![](photo.png)",
+		},
+		{
+			name:       "image without src",
+			html:       `<p>Text<img src="" alt=""></p>`,
+			expected:   "Text",
+			unexpected: "![]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+			if tt.unexpected != "" && strings.Contains(result, tt.unexpected) {
+				t.Errorf("Result = %q, expected not to contain %q", result, tt.unexpected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_WbrStyle(t *testing.T) {
+	input := `<p>supercali<wbr>fragilistic</p>`
+	tests := []struct {
+		name     string
+		style    WbrStyle
+		expected string
+	}{
+		{name: "default", expected: "supercalifragilistic"},
+		{name: "remove", style: WbrStyleRemove, expected: "supercalifragilistic"},
+		{name: "zero_width", style: WbrStyleZeroWidth, expected: "supercali\u200bfragilistic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(input, ConversionOptions{WbrStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_InlineSemantics(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name:     "nested quotes",
+			html:     `<p><q>outer <q>inner</q> outer</q></p>`,
+			opts:     ConversionOptions{QuoteStyle: QuoteStyleStraight},
+			expected: `"outer 'inner' outer"`,
+		},
+		{
+			name:     "curly quotes",
+			html:     `<p><q>outer <q>inner</q> outer</q></p>`,
+			opts:     ConversionOptions{QuoteStyle: QuoteStyleCurly},
+			expected: "“outer ‘inner’ outer”",
+		},
+		{
+			name:     "cite as emphasis",
+			html:     `<p>As argued in <cite>The Art of Programming</cite>, simplicity wins.</p>`,
+			opts:     ConversionOptions{CiteStyle: InlineSemanticStyleEmphasis},
+			expected: "As argued in *The Art of Programming*, simplicity wins.",
+		},
+		{
+			name:     "dfn as bold",
+			html:     `<p>A <dfn>closure</dfn> captures its environment.</p>`,
+			opts:     ConversionOptions{DfnStyle: InlineSemanticStyleBold},
+			expected: "A **closure** captures its environment.",
+		},
+		{
+			name:     "cite as plain text",
+			html:     `<p>See <cite>RFC 9110</cite>.</p>`,
+			opts:     ConversionOptions{CiteStyle: InlineSemanticStyleNone},
+			expected: "See RFC 9110.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_EmphasisEdgeSpaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name:     "leading space",
+			html:     `<p>a<em> text</em>b</p>`,
+			expected: "a *text*b",
+		},
+		{
+			name:     "trailing space",
+			html:     `<p>a<strong>text </strong>b</p>`,
+			expected: "a**text** b",
+		},
+		{
+			name:     "all whitespace",
+			html:     `<p>a<em> </em>b</p>`,
+			expected: "a b",
+		},
+		{
+			name:     "leading and trailing space in cite",
+			html:     `<p>See<cite> The Book </cite>now.</p>`,
+			opts:     ConversionOptions{CiteStyle: InlineSemanticStyleEmphasis},
+			expected: "See *The Book* now.",
+		},
+		{
+			name:     "nested edge space in dfn",
+			html:     `<p>A<dfn><span> term</span></dfn>.</p>`,
+			opts:     ConversionOptions{DfnStyle: InlineSemanticStyleBold},
+			expected: "A **term**.",
+		},
+		{
+			name:     "all whitespace cite",
+			html:     `<p>a<cite>  </cite>b</p>`,
+			opts:     ConversionOptions{CiteStyle: InlineSemanticStyleEmphasis},
+			expected: "a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_EmptyAndNestedFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name:     "empty nested emphasis",
+			html:     `<p>a<strong><em></em></strong>b</p>`,
+			expected: "ab",
+		},
+		{
+			name:     "nested bold",
+			html:     `<p><b><b>text</b></b></p>`,
+			expected: "**text**",
+		},
+		{
+			name:     "dfn as bold inside strong",
+			html:     `<p><strong>A <dfn>term</dfn></strong></p>`,
+			opts:     ConversionOptions{DfnStyle: InlineSemanticStyleBold},
+			expected: "**A term**",
+		},
+		{
+			name:     "nested cite as emphasis",
+			html:     `<p><cite>A <cite>B</cite></cite></p>`,
+			opts:     ConversionOptions{CiteStyle: InlineSemanticStyleEmphasis},
+			expected: "*A B*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_CodeLanguageClasses(t *testing.T) {
+	opts := ConversionOptions{CodeLanguageClasses: []string{"language-", "highlight-source-"}}
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "language prefix",
+			html:     `<pre><code class="language-go">x := 1</code></pre>`,
+			expected: "```go\nx := 1\n```",
+		},
+		{
+			name:     "highlighter prefix",
+			html:     `<div><pre class="highlight highlight-source-js"><span class="k">let</span> a = 1;</pre></div>`,
+			expected: "```js\nlet a = 1;\n```",
+		},
+		{
+			name:     "bare lang attribute",
+			html:     `<pre lang="python">print("hi")</pre>`,
+			expected: "```python\nprint(\"hi\")\n```",
+		},
+		{
+			name:     "no language",
+			html:     `<pre><code class="sourceCode">if a &lt; b {}</code></pre>`,
+			expected: "```\nif a < b {}\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_StripCodeLineNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		opts     ConversionOptions
+		expected string
+	}{
+		{
+			name: "gutter table",
+			html: "<table class=\"highlighttable\"><tr>" +
+				"<td class=\"linenos\"><pre>1\n2</pre></td>" +
+				"<td class=\"code\"><pre class=\"language-go\">a := 1\nb := 2</pre></td>" +
+				"</tr></table>",
+			opts:     ConversionOptions{StripCodeLineNumbers: true},
+			expected: "```go\na := 1\nb := 2\n```",
+		},
+		{
+			name:     "unmarked gutter table",
+			html:     "<table><tr><td>1\n2</td><td><pre>x\ny</pre></td></tr></table>",
+			opts:     ConversionOptions{StripCodeLineNumbers: true},
+			expected: "```\nx\ny\n```",
+		},
+		{
+			name:     "line number spans",
+			html:     `<pre><code class="language-js"><span class="line-number">1</span>let a;</code></pre>`,
+			opts:     ConversionOptions{StripCodeLineNumbers: true, CodeLanguageClasses: []string{"language-"}},
+			expected: "```js\nlet a;\n```",
+		},
+		{
+			name:     "numbered data table",
+			html:     `<table><tr><td>1</td><td>Alice</td></tr></table>`,
+			opts:     ConversionOptions{StripCodeLineNumbers: true},
+			expected: "| 1 | Alice |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_PreserveWhitespaceInPre(t *testing.T) {
+	art := "  +-----+\t+-----+\n  | A   |\t| B   |\n  +-----+\t+-----+\n    name    size\n    a.txt     12"
+	result, err := ConvertWithOptions("<pre>\n"+art+"\n</pre>", ConversionOptions{PreserveWhitespaceInPre: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if expected := "```\n" + art + "\n```\n"; result != expected {
+		t.Errorf("Result = %q, expected %q", result, expected)
+	}
+}
+
+func TestConvertWithOptions_TextareaStyle(t *testing.T) {
+	html := "<p>Before</p><textarea>\nfunc main() {\n\tprintln(\"a < b\")\n}\n</textarea><p>After</p>"
+	tests := []struct {
+		style    TextareaStyle
+		expected string
+	}{
+		{TextareaStyleCode, "Before\n\n```\nfunc main() {\n\tprintln(\"a < b\")\n}\n```\n\nAfter"},
+		{TextareaStyleText, "Before\n\nfunc main() {  \nprintln(\"a < b\")  \n}\n\nAfter"},
+		{TextareaStyleDrop, "Before\n\nAfter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{TextareaStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_FormStyle(t *testing.T) {
+	html := `<p>Intro</p><form action="/signup" method="post">` +
+		`<label for="name">Name:</label><input id="name" type="text" name="name">` +
+		`<label>Email <input type="email" name="email" value="a@example.com"></label>` +
+		`<input type="hidden" name="token" value="x">` +
+		`<button type="submit">Sign up</button>` +
+		`</form>`
+	tests := []struct {
+		style    FormStyle
+		expected string
+	}{
+		{FormStyleDrop, "Intro"},
+		{FormStyleList, "Intro\n\n- Name (text)\n- Email (email): a@example.com\n- Sign up (button)"},
+		{FormStyleHTML, `<form action="/signup" method="post">`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{FormStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if tt.style == FormStyleHTML {
+				if !strings.Contains(result, tt.expected) {
+					t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+				}
+				return
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_SelectStyle(t *testing.T) {
+	html := `<p>Size:</p><select name="size"><option>Small</option><option selected>Large</option></select>`
+	tests := []struct {
+		style    SelectStyle
+		expected string
+	}{
+		{SelectStyleList, "Size:\n\n- [ ] Small\n- [x] Large"},
+		{SelectStyleSelected, "Size:\n\nLarge"},
+		{SelectStyleDrop, "Size:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{SelectStyle: tt.style})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_GenerateTOC(t *testing.T) {
+	html := `<h1>Guide</h1><h2>Setup</h2><h3>Notes</h3><h2>Usage</h2><h3>Notes</h3><h3>Notes</h3>`
+	result, err := ConvertWithOptions(html, ConversionOptions{GenerateTOC: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	toc := "- [Guide](#guide)\n" +
+		"  - [Setup](#setup)\n" +
+		"    - [Notes](#notes)\n" +
+		"  - [Usage](#usage)\n" +
+		"    - [Notes](#notes-1)\n" +
+		"    - [Notes](#notes-2)\n\n# Guide"
+	if !strings.HasPrefix(result, toc) {
+		t.Errorf("Result = %q, expected to start with %q", result, toc)
+	}
+
+	result, err = ConvertWithOptions(`<p>Intro</p><!-- TOC --><h2>A &amp; [B]</h2>`, ConversionOptions{GenerateTOC: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if expected := "Intro\n\n- [A & \\[B\\]](#a--b)\n\n## A"; !strings.HasPrefix(result, expected) {
+		t.Errorf("Result = %q, expected to start with %q", result, expected)
+	}
+}
+
+func TestConvertWithOptions_HeadingOffset(t *testing.T) {
+	html := `<h1>One</h1><h2>Two</h2><h3>Three</h3><h6>Six</h6>`
+	tests := []struct {
+		name     string
+		offset   int
+		expected string
+	}{
+		{"demote", 1, "## One\n\n### Two\n\n#### Three\n\n###### Six"},
+		{"promote", -2, "# One\n\n# Two\n\n# Three\n\n#### Six"},
+		{"clamp", 7, "###### One\n\n###### Two\n\n###### Three\n\n###### Six"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(html, ConversionOptions{HeadingOffset: tt.offset})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_NormalizeHeadingHierarchy(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{"repeated h1", `<h1>A</h1><h1>B</h1><h3>C</h3>`, "# A\n\n## B\n\n### C"},
+		{"skipped level", `<h1>A</h1><h3>B</h3><h4>C</h4><h2>D</h2>`, "# A\n\n## B\n\n### C\n\n## D"},
+		{"starts below h1", `<h2>A</h2><h3>B</h3>`, "# A\n\n## B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{NormalizeHeadingHierarchy: true})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if strings.TrimSpace(result) != tt.expected {
+				t.Errorf("Result = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+
+	result, err := ConvertWithMetadataOptions(`<h1>A</h1><h1>B</h1><h3>C</h3>`, ConversionOptions{NormalizeHeadingHierarchy: true})
+	if err != nil {
+		t.Fatalf("ConvertWithMetadataOptions failed: %v", err)
+	}
+	expected := []HeadingChange{{Text: "B", From: 1, To: 2}}
+	if !slices.Equal(result.Metadata.HeadingChanges, expected) {
+		t.Errorf("HeadingChanges = %+v, expected %+v", result.Metadata.HeadingChanges, expected)
+	}
+	if !strings.Contains(result.Markdown, "## B") {
+		t.Errorf("Markdown = %q, expected the demoted heading", result.Markdown)
+	}
+}
+
+func TestConvertWithOptions_InferFormattingFromStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "bold",
+			html:     `<p>A <span style="font-weight: bold">bold</span> word</p>`,
+			expected: "A **bold** word",
+		},
+		{
+			name:     "numeric weight",
+			html:     `<p>A <span style="font-weight:700">heavy</span> word</p>`,
+			expected: "A **heavy** word",
+		},
+		{
+			name:     "bold and italic",
+			html:     `<p><span style="font-style:italic; font-weight:bold">both</span></p>`,
+			expected: "***both***",
+		},
+		{
+			name:     "line-through",
+			html:     `<p><span style="text-decoration: line-through">gone</span></p>`,
+			expected: "~~gone~~",
+		},
+		{
+			name:     "normal weight",
+			html:     `<p><span style="font-weight:400">plain</span></p>`,
+			expected: "plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{InferFormattingFromStyle: true})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_SkipHidden(t *testing.T) {
+	input := `<p>Visible</p>` +
+		`<p hidden>Hidden paragraph</p>` +
+		`<div style="color: red; display: none">Undisplayed div</div>` +
+		`<p style="visibility:hidden">Invisible paragraph</p>` +
+		`<p>Icon <span aria-hidden="true">*</span>label</p>`
+
+	tests := []struct {
+		name     string
+		opts     ConversionOptions
+		dropped  []string
+		retained []string
+	}{
+		{
+			name:     "default keeps everything",
+			retained: []string{"Hidden paragraph", "Undisplayed div", "Invisible paragraph"},
+		},
+		{
+			name:     "hidden",
+			opts:     ConversionOptions{SkipHidden: true},
+			dropped:  []string{"Hidden paragraph", "Undisplayed div"},
+			retained: []string{"Visible", "Invisible paragraph"},
+		},
+		{
+			name:     "invisible",
+			opts:     ConversionOptions{SkipInvisible: true},
+			dropped:  []string{"Invisible paragraph"},
+			retained: []string{"Hidden paragraph", "Undisplayed div"},
+		},
+		{
+			name:     "aria-hidden",
+			opts:     ConversionOptions{DropAriaHidden: true},
+			retained: []string{"Icon label"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(input, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			for _, text := range tt.dropped {
+				if strings.Contains(result, text) {
+					t.Errorf("Result = %q, expected %q to be dropped", result, text)
+				}
+			}
+			for _, text := range tt.retained {
+				if !strings.Contains(result, text) {
+					t.Errorf("Result = %q, expected to contain %q", result, text)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithOptions_DropAriaHidden(t *testing.T) {
+	input := `<p>Download<span class="icon" aria-hidden="true">&#xe001;</span> now ` +
+		`<span aria-label="label" aria-hidden="false">kept</span></p>`
+
+	result, err := ConvertWithOptions(input, ConversionOptions{DropAriaHidden: true})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "Download now kept") {
+		t.Errorf("Result = %q, expected to contain %q", result, "Download now kept")
+	}
+	if strings.Contains(result, "\ue001") {
+		t.Errorf("Result = %q, expected the icon glyph to be dropped", result)
+	}
+}
+
+func TestConvertWithOptions_ElementOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		overrides  map[string]ElementStrategy
+		expected   string
+		unexpected string
+	}{
+		{
+			name:      "keep_html",
+			html:      `<p>H<sub>2</sub>O</p>`,
+			overrides: map[string]ElementStrategy{"sub": ElementStrategyKeepHTML},
+			expected:  "H<sub>2</sub>O",
+		},
+		{
+			name:       "drop",
+			html:       `<p>Article body</p><aside>Related posts</aside>`,
+			overrides:  map[string]ElementStrategy{"aside": ElementStrategyDrop},
+			expected:   "Article body",
+			unexpected: "Related posts",
+		},
+		{
+			name:       "unwrap",
+			html:       `<p>A <strong>plain</strong> word</p>`,
+			overrides:  map[string]ElementStrategy{"strong": ElementStrategyUnwrap},
+			expected:   "A plain word",
+			unexpected: "**",
+		},
+		{
+			name:      "code inline",
+			html:      `<p>Press <span class="key">Ctrl+C</span> to copy</p>`,
+			overrides: map[string]ElementStrategy{"SPAN": ElementStrategyCode},
+			expected:  "Press `Ctrl+C` to copy",
+		},
+		{
+			name:      "code block",
+			html:      `<div class="output">$ make build</div>`,
+			overrides: map[string]ElementStrategy{"div": ElementStrategyCode},
+			expected:  "```\n$ make build\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{ElementOverrides: tt.overrides})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+			if tt.unexpected != "" && strings.Contains(result, tt.unexpected) {
+				t.Errorf("Result = %q, expected not to contain %q", result, tt.unexpected)
+			}
+		})
+	}
+
+	_, err := ConvertWithOptions("<p>x</p>", ConversionOptions{ElementOverrides: map[string]ElementStrategy{"p": "bogus"}})
+	if err == nil || !strings.Contains(err.Error(), `ElementOverrides["p"]`) {
+		t.Errorf("expected an ElementOverrides validation error, got %v", err)
+	}
+}
+
+func TestConvertWithOptions_UnwrapRedundantContainers(t *testing.T) {
+	input := "<div>\n  <div>\n    <div><p>Only <span>one</span> paragraph.</p></div>\n  </div>\n</div>"
+
+	result, err := ConvertWithOptions(input, ConversionOptions{
+		UnwrapRedundantContainers: true,
+		TrailingNewline:           TrailingNewlineNone,
+	})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if result != "Only one paragraph." {
+		t.Errorf("Result = %q, expected %q", result, "Only one paragraph.")
+	}
+}
+
+func TestConvertWithOptions_MaxDepth(t *testing.T) {
+	const depth = 20000
+	input := strings.Repeat("<div>", depth) + "deep" + strings.Repeat("</div>", depth)
+
+	_, err := ConvertWithOptions(input, ConversionOptions{MaxDepth: 512})
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("expected a *ConversionError, got %v", err)
+	}
+	if convErr.Kind != "max_depth_exceeded" {
+		t.Errorf("Kind = %q, expected %q", convErr.Kind, "max_depth_exceeded")
+	}
+
+	result, err := ConvertWithOptions("<div><div><p>shallow</p></div></div>", ConversionOptions{MaxDepth: 512})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "shallow") {
+		t.Errorf("Result = %q, expected to contain %q", result, "shallow")
+	}
+}
+
+func TestConvertWithOptions_MaxInputBytes(t *testing.T) {
+	const limit = 1 << 20
+	opts := ConversionOptions{MaxInputBytes: limit}
+
+	large := strings.Repeat("<p>x</p>", 10*limit/len("<p>x</p>"))
+	_, err := ConvertWithOptions(large, opts)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("expected a *ConversionError, got %v", err)
+	}
+	if convErr.Kind != "input_too_large" {
+		t.Errorf("Kind = %q, expected %q", convErr.Kind, "input_too_large")
+	}
+
+	exact := "<p>" + strings.Repeat("x", limit-len("<p></p>")) + "</p>"
+	if len(exact) != limit {
+		t.Fatalf("test input is %d bytes, expected %d", len(exact), limit)
+	}
+	if _, err := ConvertWithOptions(exact, opts); err != nil {
+		t.Errorf("input at the limit failed: %v", err)
+	}
+}
+
+func TestConvertWithOptions_Timeout(t *testing.T) {
+	large := strings.Repeat("<p>Some <strong>bold</strong> and <em>emphasized</em> text.</p>", 100000)
+
+	_, err := ConvertWithOptions(large, ConversionOptions{Timeout: time.Nanosecond})
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("expected a *ConversionError, got %v", err)
+	}
+	if convErr.Kind != "timeout" {
+		t.Errorf("Kind = %q, expected %q", convErr.Kind, "timeout")
+	}
+
+	result, err := ConvertWithOptions("<p>quick</p>", ConversionOptions{Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("ConvertWithOptions failed: %v", err)
+	}
+	if !strings.Contains(result, "quick") {
+		t.Errorf("Result = %q, expected to contain %q", result, "quick")
+	}
+}
+
+func TestConvertWithOptions_MergeAdjacentFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "adjacent strong",
+			html:     `<p><strong>a</strong><strong>b</strong></p>`,
+			expected: "**ab**",
+		},
+		{
+			name:     "adjacent em",
+			html:     `<p><em>a</em><i>b</i></p>`,
+			expected: "*ab*",
+		},
+		{
+			name:     "strong separated by a space",
+			html:     `<p><strong>a</strong> <strong>b</strong></p>`,
+			expected: "**a b**",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertWithOptions(tt.html, ConversionOptions{MergeAdjacentFormatting: true})
+			if err != nil {
+				t.Fatalf("ConvertWithOptions failed: %v", err)
+			}
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("Result = %q, expected to contain %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package htmltomarkdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// List marker sentinels carry the marker chosen on the Go side for an ordered
+// list item through the native conversion, which always numbers items itself.
+const (
+	listMarkerOpen  = "\uE002"
+	listMarkerClose = "\uE003"
+)
+
+// markOrderedLists prefixes every item of an ordered list that declares a
+// start, reversed, type or value attribute with a sentinel holding its marker.
+func markOrderedLists(root *htmlNode) {
+	for _, list := range root.findAll("ol") {
+		if !hasCustomNumbering(list) {
+			continue
+		}
+		items := listItems(list)
+		numbers := listNumbers(list, items)
+		style := list.attrOr("type", "1")
+		for i, li := range items {
+			itemStyle := li.attrOr("type", style)
+			insertListMarker(li, listMarkerOpen+formatListNumber(numbers[i], itemStyle)+".")
+		}
+	}
+}
+
+// listNumbers returns the numbers of the items of an ordered list, counting
+// down for a reversed list and continuing from the value of an item that
+// declares one.
+func listNumbers(list *htmlNode, items []*htmlNode) []int {
+	step := 1
+	number := 1
+	if _, reversed := list.attr("reversed"); reversed {
+		step = -1
+		number = len(items)
+	}
+	if start, err := strconv.Atoi(strings.TrimSpace(list.attrOr("start", ""))); err == nil {
+		number = start
+	}
+	numbers := make([]int, len(items))
+	for i, li := range items {
+		if value, err := strconv.Atoi(strings.TrimSpace(li.attrOr("value", ""))); err == nil {
+			number = value
+		}
+		numbers[i] = number
+		number += step
+	}
+	return numbers
+}
+
+func hasCustomNumbering(list *htmlNode) bool {
+	for _, key := range []string{"start", "reversed", "type"} {
+		if _, ok := list.attr(key); ok {
+			return true
+		}
+	}
+	for _, li := range listItems(list) {
+		if _, ok := li.attr("value"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// insertListMarker places the sentinel at the start of the item's first line
+// of text, descending into a leading paragraph or division.
+func insertListMarker(li *htmlNode, marker string) {
+	target := li
+	for {
+		var first *htmlNode
+		for _, c := range target.children {
+			if c.typ == htmlTextNode && strings.TrimSpace(c.data) == "" {
+				continue
+			}
+			if c.typ == htmlElementNode || c.typ == htmlTextNode {
+				first = c
+				break
+			}
+		}
+		if first == nil || first.typ != htmlElementNode || (first.tag != "p" && first.tag != "div") {
+			break
+		}
+		target = first
+	}
+	sentinel := &htmlNode{typ: htmlTextNode, data: marker + listMarkerClose, parent: target}
+	target.children = append([]*htmlNode{sentinel}, target.children...)
+}
+
+// formatListNumber renders n in the numbering style of an HTML type attribute.
+// Alphabetic and roman styles fall back to decimal for numbers below 1.
+func formatListNumber(n int, style string) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	switch style {
+	case "a":
+		return alphabeticNumber(n)
+	case "A":
+		return strings.ToUpper(alphabeticNumber(n))
+	case "i":
+		return romanNumber(n)
+	case "I":
+		return strings.ToUpper(romanNumber(n))
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+func alphabeticNumber(n int) string {
+	var b []byte
+	for n > 0 {
+		n--
+		b = append([]byte{byte('a' + n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+	{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+}
+
+func romanNumber(n int) string {
+	if n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			b.WriteString(r.symbol)
+			n -= r.value
+		}
+	}
+	return b.String()
+}
+
+var (
+	listMarkerLinePattern = regexp.MustCompile(`^([ >]*)(\d+[.)] )` + listMarkerOpen + `([^` + listMarkerClose + `]*)` + listMarkerClose)
+	listMarkerPattern     = regexp.MustCompile(listMarkerOpen + `[^` + listMarkerClose + `]*` + listMarkerClose)
+)
+
+// listMarkerShift records how far the continuation lines of a renumbered item
+// move once its native marker is replaced by a wider or narrower one.
+type listMarkerShift struct {
+	content int // column at which the item's content starts in the native output
+	delta   int
+}
+
+// applyListMarkers replaces the native markers of sentinel-marked items and
+// re-indents their continuation lines so nested content stays attached.
+func applyListMarkers(markdown string) string {
+	if !strings.Contains(markdown, listMarkerOpen) {
+		return markdown
+	}
+	lines := strings.Split(markdown, "\n")
+	var shifts []listMarkerShift
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lead := len(line) - len(strings.TrimLeft(line, " >"))
+		for len(shifts) > 0 && lead < shifts[len(shifts)-1].content {
+			shifts = shifts[:len(shifts)-1]
+		}
+		m := listMarkerLinePattern.FindStringSubmatchIndex(line)
+
+		shifted := line
+		for j := len(shifts) - 1; j >= 0; j-- {
+			shifted = shiftColumn(shifted, shifts[j])
+		}
+		if m != nil {
+			native := line[m[4]:m[5]]
+			marker := line[m[6]:m[7]] + " "
+			offset := len(shifted) - len(line)
+			shifted = shifted[:m[4]+offset] + marker + shifted[m[1]+offset:]
+			shifts = append(shifts, listMarkerShift{content: m[4] + len(native), delta: len(marker) - len(native)})
+		}
+		lines[i] = listMarkerPattern.ReplaceAllString(shifted, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shiftColumn inserts or removes spaces just before the content column of s.
+func shiftColumn(s string, shift listMarkerShift) string {
+	switch {
+	case shift.delta > 0:
+		return s[:shift.content] + strings.Repeat(" ", shift.delta) + s[shift.content:]
+	case shift.delta < 0:
+		return s[:shift.content+shift.delta] + s[shift.content:]
+	default:
+		return s
+	}
+}
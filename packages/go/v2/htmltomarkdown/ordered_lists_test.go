@@ -0,0 +1,44 @@
+package htmltomarkdown
+
+import "testing"
+
+func TestFormatListNumber(t *testing.T) {
+	tests := []struct {
+		n     int
+		style string
+		want  string
+	}{
+		{n: 3, style: "1", want: "3"},
+		{n: 3, style: "a", want: "c"},
+		{n: 28, style: "a", want: "ab"},
+		{n: 2, style: "A", want: "B"},
+		{n: 4, style: "i", want: "iv"},
+		{n: 1994, style: "I", want: "MCMXCIV"},
+		{n: 0, style: "i", want: "0"},
+	}
+
+	for _, tt := range tests {
+		if got := formatListNumber(tt.n, tt.style); got != tt.want {
+			t.Errorf("formatListNumber(%d, %q) = %q, want %q", tt.n, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestApplyListMarkers(t *testing.T) {
+	markdown := "1. " + listMarkerOpen + "10." + listMarkerClose + "Ten\n" +
+		"   continued\n" +
+		"   - nested\n" +
+		"2. " + listMarkerOpen + "9." + listMarkerClose + "Nine\n" +
+		"\n" +
+		"After\n"
+	want := "10. Ten\n" +
+		"    continued\n" +
+		"    - nested\n" +
+		"9. Nine\n" +
+		"\n" +
+		"After\n"
+
+	if got := applyListMarkers(markdown); got != want {
+		t.Errorf("applyListMarkers() = %q, want %q", got, want)
+	}
+}
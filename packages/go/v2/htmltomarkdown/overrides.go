@@ -0,0 +1,81 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// applyElementOverrides converts the elements named in overrides with their
+// strategy. An element handled by an override is not examined further, so
+// the override of an outer element wins over those of its descendants.
+func applyElementOverrides(root *htmlNode, overrides map[string]ElementStrategy, fragments *fragmentSet) {
+	strategies := make(map[string]ElementStrategy, len(overrides))
+	for tag, strategy := range overrides {
+		strategies[strings.ToLower(tag)] = strategy
+	}
+
+	type override struct {
+		n        *htmlNode
+		strategy ElementStrategy
+	}
+	var matched []override
+	root.walk(func(n *htmlNode) bool {
+		if n.typ == htmlDocumentNode {
+			return true
+		}
+		if n.typ != htmlElementNode {
+			return false
+		}
+		if strategy := strategies[n.tag]; strategy != "" {
+			matched = append(matched, override{n, strategy})
+			return false
+		}
+		return true
+	})
+
+	for _, m := range matched {
+		n := m.n
+		inline := isInlineTag(n.tag)
+		switch m.strategy {
+		case ElementStrategyKeepHTML:
+			n.replaceWith(fragments.node(fragments.restore(n.render()), inline))
+		case ElementStrategyDrop:
+			n.remove()
+		case ElementStrategyUnwrap:
+			n.replaceWith(n.children...)
+		case ElementStrategyCode:
+			text := fragments.restore(html.UnescapeString(n.text()))
+			if inline {
+				n.replaceWith(fragments.node(inlineCode(text), true))
+			} else {
+				n.replaceWith(fragments.node(codeFence(strings.Trim(text, "\n"), ""), false))
+			}
+		}
+	}
+}
+
+// inlineCode returns code as a Markdown code span, with whitespace collapsed
+// and a fence longer than any backtick run in code.
+func inlineCode(code string) string {
+	code = strings.Join(strings.Fields(code), " ")
+	if code == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// codeFence returns code as a fenced code block tagged with lang.
+func codeFence(code, lang string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
@@ -0,0 +1,143 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// ExtractText returns the readable text of html, similar to a browser's
+// innerText: markup is stripped, whitespace is collapsed, block elements
+// start new lines and paragraphs are separated by a blank line. Script,
+// style and other non-rendered content is excluded.
+//
+// Example:
+//
+//	text, err := htmltomarkdown.ExtractText("<h1>Title</h1><p>Some <b>bold</b> text.</p>")
+//	// text == "Title\n\nSome bold text."
+func ExtractText(html string) (string, error) {
+	var e textExtractor
+	e.walk(parseHTML(html))
+	return strings.TrimRight(string(e.buf), " \n"), nil
+}
+
+// textExtractor accumulates collapsed text and the line breaks requested
+// by block boundaries, which are only written before the next text.
+type textExtractor struct {
+	buf    []byte
+	breaks int
+	space  bool
+}
+
+// blankLineElements are separated from their surroundings by a blank line.
+var blankLineElements = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "table": true, "ul": true, "ol": true, "dl": true,
+	"figure": true, "hr": true,
+}
+
+func (e *textExtractor) walk(n *htmlNode) {
+	switch n.typ {
+	case htmlTextNode:
+		if n.hasAncestor("pre") {
+			e.writePreformatted(html.UnescapeString(n.data))
+		} else {
+			e.writeText(html.UnescapeString(n.data))
+		}
+		return
+	case htmlDocumentNode:
+	case htmlElementNode:
+		if fallbackSkipped[n.tag] {
+			return
+		}
+	default:
+		return
+	}
+
+	switch {
+	case n.tag == "br":
+		e.buf = append(trimTrailingSpaces(e.buf), '\n')
+		e.space = false
+		return
+	case n.tag == "img":
+		return
+	case n.tag == "td" || n.tag == "th":
+		if n.elementIndex() > 0 {
+			e.buf = append(trimTrailingSpaces(e.buf), '\t')
+			e.space = false
+		}
+	}
+
+	breaks := 0
+	switch {
+	case n.typ != htmlElementNode, n.tag == "td", n.tag == "th":
+	case blankLineElements[n.tag] && !((n.tag == "ul" || n.tag == "ol") && n.hasAncestor("li")):
+		breaks = 2
+	case fallbackIsBlock(n.tag):
+		breaks = 1
+	}
+	e.requestBreaks(breaks)
+	for _, c := range n.children {
+		e.walk(c)
+	}
+	e.requestBreaks(breaks)
+}
+
+func (e *textExtractor) requestBreaks(n int) {
+	if n > e.breaks {
+		e.breaks = n
+	}
+}
+
+// flushBreaks ends the current line with the pending line breaks, counting
+// those already written by <br>, unless nothing was written yet.
+func (e *textExtractor) flushBreaks() {
+	if e.breaks == 0 {
+		return
+	}
+	if len(e.buf) > 0 {
+		e.buf = trimTrailingSpaces(e.buf)
+		written := len(e.buf) - len(strings.TrimRight(string(e.buf), "\n"))
+		for i := written; i < e.breaks; i++ {
+			e.buf = append(e.buf, '\n')
+		}
+	}
+	e.breaks = 0
+	e.space = false
+}
+
+func (e *textExtractor) writeText(text string) {
+	if text != "" && isCollapsibleSpace(rune(text[0])) {
+		e.space = true
+	}
+	for i, word := range strings.FieldsFunc(text, isCollapsibleSpace) {
+		e.flushBreaks()
+		e.space = e.space || i > 0
+		if last := len(e.buf) - 1; e.space && last >= 0 && e.buf[last] != '\n' && e.buf[last] != '\t' {
+			e.buf = append(e.buf, ' ')
+		}
+		e.buf = append(e.buf, word...)
+		e.space = false
+	}
+	if text != "" && isCollapsibleSpace(rune(text[len(text)-1])) {
+		e.space = true
+	}
+}
+
+func (e *textExtractor) writePreformatted(text string) {
+	if text == "" {
+		return
+	}
+	e.flushBreaks()
+	e.buf = append(e.buf, strings.Trim(text, "\n")...)
+}
+
+func isCollapsibleSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+func trimTrailingSpaces(buf []byte) []byte {
+	for len(buf) > 0 && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+	return buf
+}
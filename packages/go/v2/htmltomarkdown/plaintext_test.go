@@ -0,0 +1,62 @@
+package htmltomarkdown
+
+import "testing"
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name: "document",
+			html: `<html><head><title>Page</title><style>h1 { color: red; }</style></head>
+<body>
+  <h1>Main   Title</h1>
+  <p>Some <b>bold</b> and <a href="/x">linked</a>
+     text.</p>
+  <ul>
+    <li>First</li>
+    <li>Second
+      <ul><li>Nested</li></ul>
+    </li>
+  </ul>
+  <script>console.log("hidden");</script>
+  <p>Line one<br>Line two</p>
+</body></html>`,
+			expected: "Main Title\n\nSome bold and linked text.\n\nFirst\nSecond\nNested\n\nLine one\nLine two",
+		},
+		{
+			name:     "entities and inline spacing",
+			html:     `<div>Fish &amp; <em>chips</em></div><div>Next</div>`,
+			expected: "Fish & chips\nNext",
+		},
+		{
+			name:     "table cells",
+			html:     `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>`,
+			expected: "A\tB\n1\t2",
+		},
+		{
+			name:     "preformatted",
+			html:     "<p>Code:</p><pre>if x {\n    y()\n}\n</pre>",
+			expected: "Code:\n\nif x {\n    y()\n}",
+		},
+		{
+			name:     "empty",
+			html:     "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractText(tt.html)
+			if err != nil {
+				t.Fatalf("ExtractText failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ExtractText() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
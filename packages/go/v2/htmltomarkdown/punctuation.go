@@ -0,0 +1,76 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+var asciiPunctuation = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"—", "--", "–", "-", "…", "...",
+)
+
+// normalizePunctuation rewrites quotes and dashes in the text nodes of root,
+// leaving code untouched. The preceding character is tracked across nodes so
+// that quotes next to inline markup get the right direction.
+func normalizePunctuation(root *htmlNode, style SmartPunctuation) {
+	prev := ' '
+	root.walk(func(n *htmlNode) bool {
+		switch n.typ {
+		case htmlDocumentNode:
+			return true
+		case htmlElementNode:
+			if containsString(literalTextElements, n.tag) || rawTextElements[n.tag] {
+				prev = 'x'
+				return false
+			}
+			if !isInlineTag(n.tag) {
+				prev = ' '
+			}
+			return true
+		case htmlTextNode:
+			text := html.UnescapeString(n.data)
+			var converted string
+			if style == SmartPunctuationAsciify {
+				converted = asciiPunctuation.Replace(text)
+			} else {
+				converted = smartenPunctuation(text, prev)
+			}
+			if converted != text {
+				n.data = textEscaper.Replace(converted)
+			}
+			if text != "" {
+				runes := []rune(text)
+				prev = runes[len(runes)-1]
+			}
+		}
+		return false
+	})
+}
+
+// smartenPunctuation converts straight quotes to curly quotes, "--" to an em
+// dash and "..." to an ellipsis. A quote opens after whitespace or an opening bracket and closes
+// otherwise, so apostrophes inside words become right single quotes.
+func smartenPunctuation(text string, prev rune) string {
+	text = strings.NewReplacer("--", "—", "...", "…").Replace(text)
+	var b strings.Builder
+	for _, r := range text {
+		opening := unicode.IsSpace(prev) || strings.ContainsRune("([{“‘—", prev)
+		switch {
+		case r == '"' && opening:
+			b.WriteRune('“')
+		case r == '"':
+			b.WriteRune('”')
+		case r == '\'' && opening:
+			b.WriteRune('‘')
+		case r == '\'':
+			b.WriteRune('’')
+		default:
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
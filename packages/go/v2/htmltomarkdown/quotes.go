@@ -0,0 +1,59 @@
+package htmltomarkdown
+
+// quoteMarks holds the opening and closing marks of each QuoteStyle for
+// outer and nested quotations.
+var quoteMarks = map[QuoteStyle][2][2]string{
+	QuoteStyleStraight: {{`"`, `"`}, {"'", "'"}},
+	QuoteStyleCurly:    {{"“", "”"}, {"‘", "’"}},
+}
+
+// renderQuotes rewrites every <q> in the requested style. Nested quotations
+// alternate between double and single marks.
+func renderQuotes(root *htmlNode, style QuoteStyle, fragments *fragmentSet) {
+	quotes := root.findAll("q")
+	for i := len(quotes) - 1; i >= 0; i-- {
+		n := quotes[i]
+		if n.hasAncestor("code", "pre") {
+			continue
+		}
+		if style == QuoteStyleNone {
+			n.replaceWith(n.children...)
+			continue
+		}
+		depth := 0
+		for p := n.parent; p != nil; p = p.parent {
+			if p.typ == htmlElementNode && p.tag == "q" {
+				depth++
+			}
+		}
+		marks := quoteMarks[style][depth%2]
+		fragments.unwrap(n, marks[0], marks[1])
+	}
+}
+
+// renderInlineSemantics rewrites every element with tag, such as <cite> or
+// <dfn>, in the requested style. Elements already inside the same formatting
+// lose their delimiters, which would otherwise double up.
+func renderInlineSemantics(root *htmlNode, tag string, style InlineSemanticStyle, fragments *fragmentSet) {
+	elements := root.findAll(tag)
+	for i := len(elements) - 1; i >= 0; i-- {
+		n := elements[i]
+		if n.hasAncestor("code", "pre") {
+			continue
+		}
+		switch style {
+		case InlineSemanticStyleEmphasis, InlineSemanticStyleBold:
+			delimiter, family := "*", []string{tag, "em", "i"}
+			if style == InlineSemanticStyleBold {
+				delimiter, family = "**", []string{tag, "strong", "b"}
+			}
+			if n.hasAncestor(family...) {
+				n.replaceWith(n.children...)
+			} else {
+				fragments.unwrap(n, delimiter, delimiter)
+			}
+		case InlineSemanticStyleNone:
+			n.replaceWith(n.children...)
+		}
+	}
+}
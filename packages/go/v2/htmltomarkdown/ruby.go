@@ -0,0 +1,45 @@
+package htmltomarkdown
+
+// renderRuby rewrites every <ruby> annotation in the requested style. The
+// <rp> fallback parentheses are always dropped: parentheses mode adds its
+// own around each <rt>, and the other modes have no use for them.
+func renderRuby(root *htmlNode, style RubyStyle, fragments *fragmentSet) {
+	rubies := root.findAll("ruby")
+	for i := len(rubies) - 1; i >= 0; i-- {
+		ruby := rubies[i]
+		if style == RubyStyleHTML {
+			if !ruby.hasAncestor("ruby") {
+				ruby.replaceWith(fragments.node(fragments.restore(ruby.render()), true))
+			}
+			continue
+		}
+		ruby.replaceWith(rubyContent(ruby.children, style)...)
+	}
+}
+
+// rubyContent returns the nodes replacing the children of a <ruby> or <rtc>.
+func rubyContent(children []*htmlNode, style RubyStyle) []*htmlNode {
+	var nodes []*htmlNode
+	for _, c := range children {
+		if c.typ != htmlElementNode {
+			nodes = append(nodes, c)
+			continue
+		}
+		switch c.tag {
+		case "rp":
+		case "rt":
+			if style == RubyStyleParentheses {
+				nodes = append(nodes, &htmlNode{typ: htmlTextNode, data: "("})
+				nodes = append(nodes, c.children...)
+				nodes = append(nodes, &htmlNode{typ: htmlTextNode, data: ")"})
+			}
+		case "rtc":
+			nodes = append(nodes, rubyContent(c.children, style)...)
+		case "rb":
+			nodes = append(nodes, c.children...)
+		default:
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,89 @@
+package htmltomarkdown
+
+import (
+	"slices"
+	"strings"
+)
+
+// renderHorizontalRules replaces every <hr> with a block fragment holding
+// marker, which separates it from the surrounding content by blank lines.
+func renderHorizontalRules(root *htmlNode, marker string, fragments *fragmentSet) {
+	for _, hr := range root.findAll("hr") {
+		if !hr.hasAncestor("pre", "code") {
+			hr.replaceWith(fragments.node(strings.TrimSpace(marker), false))
+		}
+	}
+}
+
+// isThematicBreak reports whether s is a CommonMark thematic break: three or
+// more of the same '-', '*' or '_' character, optionally separated by spaces
+// or tabs, after at most three spaces of indentation.
+func isThematicBreak(s string) bool {
+	indent := len(s) - len(strings.TrimLeft(s, " "))
+	if indent > 3 {
+		return false
+	}
+	var marker rune
+	count := 0
+	for _, r := range s[indent:] {
+		switch {
+		case r == ' ' || r == '\t':
+		case marker == 0 && (r == '-' || r == '*' || r == '_'):
+			marker = r
+			count++
+		case r == marker:
+			count++
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// ruleContentElements are the elements that render as content even when
+// they hold no text, so a rule before or after them is not redundant.
+var ruleContentElements = map[string]bool{
+	"img": true, "input": true, "embed": true, "iframe": true, "video": true,
+	"audio": true, "object": true, "canvas": true, "svg": true,
+}
+
+// removeRedundantRules removes the <hr> elements that would render as a
+// rule next to another: with collapse, every rule following another with no
+// content in between; with trim, the rules before the first content and
+// after the last.
+func removeRedundantRules(root *htmlNode, collapse, trim bool) {
+	// Rules are recorded in document order, with nil marking content.
+	var sequence []*htmlNode
+	root.walk(func(n *htmlNode) bool {
+		switch {
+		case n.typ == htmlTextNode:
+			if strings.TrimSpace(n.data) != "" {
+				sequence = append(sequence, nil)
+			}
+		case n.typ != htmlElementNode:
+		case fallbackSkipped[n.tag]:
+			return false
+		case n.tag == "hr":
+			sequence = append(sequence, n)
+		case ruleContentElements[n.tag]:
+			sequence = append(sequence, nil)
+			return false
+		}
+		return true
+	})
+
+	first := slices.Index(sequence, nil)
+	last := len(sequence) - 1
+	for last >= 0 && sequence[last] != nil {
+		last--
+	}
+	for i, hr := range sequence {
+		switch {
+		case hr == nil:
+		case trim && (first < 0 || i < first || i > last):
+			hr.remove()
+		case collapse && i > 0 && sequence[i-1] != nil:
+			hr.remove()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// normalizeSpaces replaces non-breaking spaces, including the narrow no-break
+// space, in the text nodes of root with regular spaces, leaving code
+// untouched. With allUnicode set, the other Unicode space separators such as
+// thin and em spaces are replaced too.
+func normalizeSpaces(root *htmlNode, allUnicode bool) {
+	root.walk(func(n *htmlNode) bool {
+		switch n.typ {
+		case htmlDocumentNode:
+			return true
+		case htmlElementNode:
+			return !containsString(literalTextElements, n.tag) && !rawTextElements[n.tag]
+		case htmlTextNode:
+			text := html.UnescapeString(n.data)
+			normalized := strings.Map(func(r rune) rune {
+				if r == '\u00a0' || r == '\u202f' || (allUnicode && r != ' ' && unicode.Is(unicode.Zs, r)) {
+					return ' '
+				}
+				return r
+			}, text)
+			if normalized != text {
+				n.data = textEscaper.Replace(normalized)
+			}
+		}
+		return false
+	})
+}
+
+// renderWordBreaks rewrites every <wbr> in the requested style, so the text
+// on both sides joins without a space.
+func renderWordBreaks(root *htmlNode, style WbrStyle) {
+	for _, wbr := range root.findAll("wbr") {
+		if style == WbrStyleZeroWidth {
+			wbr.replaceWith(&htmlNode{typ: htmlTextNode, data: "\u200b"})
+		} else {
+			wbr.remove()
+		}
+	}
+}
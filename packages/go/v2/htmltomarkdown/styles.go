@@ -0,0 +1,96 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// inferStyleFormatting wraps the content of elements styled as bold, italic
+// or struck through, such as <span style="font-weight:bold">, in the
+// matching <strong>, <em> and <del> elements. Combined styles nest with bold
+// outermost.
+func inferStyleFormatting(root *htmlNode) {
+	root.walk(func(n *htmlNode) bool {
+		if n.typ == htmlDocumentNode {
+			return true
+		}
+		if n.typ != htmlElementNode || containsString(literalTextElements, n.tag) || rawTextElements[n.tag] {
+			return false
+		}
+		if _, ok := n.attr("style"); !ok {
+			return true
+		}
+		var wrappers []string
+		if isBoldWeight(n.styleProperty("font-weight")) && n.tag != "strong" && n.tag != "b" {
+			wrappers = append(wrappers, "strong")
+		}
+		if style := n.styleProperty("font-style"); (style == "italic" || style == "oblique") && n.tag != "em" && n.tag != "i" {
+			wrappers = append(wrappers, "em")
+		}
+		decoration := n.styleProperty("text-decoration-line")
+		if decoration == "" {
+			decoration = n.styleProperty("text-decoration")
+		}
+		if strings.Contains(decoration, "line-through") && n.tag != "del" && n.tag != "s" {
+			wrappers = append(wrappers, "del")
+		}
+		if len(wrappers) > 0 && hasInlineContent(n.children) {
+			wrapChildren(n, wrappers)
+		}
+		return true
+	})
+}
+
+// isBoldWeight reports whether a font-weight value renders as bold.
+func isBoldWeight(weight string) bool {
+	switch weight {
+	case "bold", "bolder":
+		return true
+	}
+	w, err := strconv.Atoi(weight)
+	return err == nil && w >= 700
+}
+
+// wrapChildren moves the children of n into nested elements with the given
+// tags, the first outermost.
+func wrapChildren(n *htmlNode, tags []string) {
+	children := n.children
+	n.children = nil
+	parent := n
+	for _, tag := range tags {
+		wrapper := newElementNode(tag)
+		parent.appendChild(wrapper)
+		parent = wrapper
+	}
+	for _, c := range children {
+		parent.appendChild(c)
+	}
+}
+
+// removeHiddenElements removes the elements readers cannot see: those with
+// a hidden attribute or display:none when hidden is set, those with
+// visibility:hidden when invisible is set and those with aria-hidden="true"
+// when ariaHidden is set.
+func removeHiddenElements(root *htmlNode, hidden, invisible, ariaHidden bool) {
+	var removed []*htmlNode
+	root.walk(func(n *htmlNode) bool {
+		if n.typ == htmlDocumentNode {
+			return true
+		}
+		if n.typ != htmlElementNode {
+			return false
+		}
+		_, hasHidden := n.attr("hidden")
+		switch {
+		case hidden && (hasHidden || n.styleProperty("display") == "none"),
+			invisible && n.styleProperty("visibility") == "hidden",
+			ariaHidden && strings.EqualFold(n.attrOr("aria-hidden", ""), "true"):
+			removed = append(removed, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range removed {
+		n.remove()
+	}
+}
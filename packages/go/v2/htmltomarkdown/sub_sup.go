@@ -0,0 +1,73 @@
+package htmltomarkdown
+
+import "strings"
+
+var superscriptRunes = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+	'+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾',
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ',
+	'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ',
+	't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ',
+}
+
+var subscriptRunes = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+	'+': '₊', '-': '₋', '=': '₌', '(': '₍', ')': '₎',
+	'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ',
+	'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+}
+
+// renderSubSup rewrites every <sub> and <sup> outside code in the requested
+// style.
+func renderSubSup(root *htmlNode, style SubSupStyle, fragments *fragmentSet) {
+	elements := root.findAll("sub", "sup")
+	for i := len(elements) - 1; i >= 0; i-- {
+		n := elements[i]
+		if n.hasAncestor("code", "pre") {
+			continue
+		}
+		switch style {
+		case SubSupStyleMarkdown:
+			delimiter := "^"
+			if n.tag == "sub" {
+				delimiter = "~"
+			}
+			fragments.unwrap(n, delimiter, delimiter)
+		case SubSupStyleHTML:
+			fragments.unwrap(n, "<"+n.tag+">", "</"+n.tag+">")
+		case SubSupStyleUnicode:
+			runes := superscriptRunes
+			if n.tag == "sub" {
+				runes = subscriptRunes
+			}
+			if mapped, ok := mapRunes(n, runes); ok {
+				n.replaceWith(fragments.node(mapped, true))
+			} else {
+				fragments.unwrap(n, "<"+n.tag+">", "</"+n.tag+">")
+			}
+		}
+	}
+}
+
+// mapRunes maps the text of an element without child elements through runes,
+// failing if any character has no mapping.
+func mapRunes(n *htmlNode, runes map[rune]rune) (string, bool) {
+	for _, c := range n.children {
+		if c.typ == htmlElementNode {
+			return "", false
+		}
+	}
+	text := strings.TrimSpace(n.text())
+	if text == "" {
+		return "", false
+	}
+	var b strings.Builder
+	for _, r := range text {
+		mapped, ok := runes[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(mapped)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,514 @@
+package htmltomarkdown
+
+import (
+	"encoding/csv"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tableCell is a <td> or <th> element placed on its table's grid.
+type tableCell struct {
+	node     *htmlNode
+	row      int
+	col      int
+	colspan  int
+	rowspan  int
+	isHeader bool
+}
+
+// tableRows returns the <tr> elements that belong to table, skipping rows of
+// nested tables.
+func tableRows(table *htmlNode) []*htmlNode {
+	var rows []*htmlNode
+	for _, c := range table.children {
+		if c.typ != htmlElementNode {
+			continue
+		}
+		switch c.tag {
+		case "tr":
+			rows = append(rows, c)
+		case "thead", "tbody", "tfoot":
+			for _, r := range c.children {
+				if r.typ == htmlElementNode && r.tag == "tr" {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// rowCells returns the <td> and <th> children of a row.
+func rowCells(row *htmlNode) []*htmlNode {
+	var cells []*htmlNode
+	for _, c := range row.children {
+		if c.typ == htmlElementNode && (c.tag == "td" || c.tag == "th") {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// layoutTable places every cell of table on a grid, resolving the column each
+// cell starts at once earlier colspan and rowspan cells are accounted for.
+func layoutTable(table *htmlNode) [][]tableCell {
+	rows := tableRows(table)
+	layout := make([][]tableCell, len(rows))
+	occupied := map[[2]int]bool{}
+	for r, row := range rows {
+		col := 0
+		isHeaderRow := row.parent != nil && row.parent.tag == "thead"
+		for _, cell := range rowCells(row) {
+			for occupied[[2]int{r, col}] {
+				col++
+			}
+			placed := tableCell{
+				node:     cell,
+				row:      r,
+				col:      col,
+				colspan:  spanAttr(cell, "colspan"),
+				rowspan:  spanAttr(cell, "rowspan"),
+				isHeader: isHeaderRow || cell.tag == "th",
+			}
+			if placed.rowspan > len(rows)-r {
+				placed.rowspan = len(rows) - r
+			}
+			for dr := 0; dr < placed.rowspan; dr++ {
+				for dc := 0; dc < placed.colspan; dc++ {
+					occupied[[2]int{r + dr, col + dc}] = true
+				}
+			}
+			layout[r] = append(layout[r], placed)
+			col += placed.colspan
+		}
+	}
+	return layout
+}
+
+// maxTableSpan bounds colspan and rowspan values, matching the HTML limits.
+const maxTableSpan = 1000
+
+func spanAttr(cell *htmlNode, key string) int {
+	span, err := strconv.Atoi(cell.attrOr(key, "1"))
+	if err != nil || span < 1 {
+		return 1
+	}
+	if span > maxTableSpan {
+		return maxTableSpan
+	}
+	return span
+}
+
+// normalizeTableSpans rewrites every table so that no cell spans several
+// positions: each covered position receives its own cell, either a copy of
+// the spanning cell (TableSpanModeExpand) or an empty one (TableSpanModeIgnore).
+func normalizeTableSpans(root *htmlNode, mode TableSpanMode) {
+	tables := root.findAll("table")
+	// Inner tables first, so copies of outer cells carry normalized tables.
+	for i := len(tables) - 1; i >= 0; i-- {
+		normalizeTable(tables[i], mode)
+	}
+}
+
+func normalizeTable(table *htmlNode, mode TableSpanMode) {
+	rows := tableRows(table)
+	layout := layoutTable(table)
+
+	grid := make([]map[int]*htmlNode, len(rows))
+	for r := range grid {
+		grid[r] = map[int]*htmlNode{}
+	}
+	for _, row := range layout {
+		for _, cell := range row {
+			cell.node.removeAttr("colspan")
+			cell.node.removeAttr("rowspan")
+			for dr := 0; dr < cell.rowspan; dr++ {
+				for dc := 0; dc < cell.colspan; dc++ {
+					if dr == 0 && dc == 0 {
+						grid[cell.row][cell.col] = cell.node
+						continue
+					}
+					grid[cell.row+dr][cell.col+dc] = spanFiller(cell.node, mode)
+				}
+			}
+		}
+	}
+
+	for r, row := range rows {
+		width := 0
+		for col := range grid[r] {
+			if col+1 > width {
+				width = col + 1
+			}
+		}
+		children := make([]*htmlNode, 0, width)
+		for _, c := range row.children {
+			if c.typ != htmlElementNode || (c.tag != "td" && c.tag != "th") {
+				children = append(children, c)
+			}
+		}
+		row.children = children
+		for col := 0; col < width; col++ {
+			if cell, ok := grid[r][col]; ok {
+				row.appendChild(cell)
+			}
+		}
+	}
+}
+
+// boldRowHeaders wraps the content of every row header cell in <strong>. Row
+// headers are <th> cells with scope="row" or "rowgroup", and <th> cells of
+// body rows that also hold <td> cells.
+func boldRowHeaders(root *htmlNode) {
+	for _, table := range root.findAll("table") {
+		for r, row := range tableRows(table) {
+			cells := rowCells(row)
+			mixed := r > 0 && row.parent.tag != "thead" && slices.ContainsFunc(cells, func(c *htmlNode) bool {
+				return c.tag == "td"
+			})
+			for _, cell := range cells {
+				scope := strings.ToLower(cell.attrOr("scope", ""))
+				if cell.tag != "th" || !mixed && scope != "row" && scope != "rowgroup" {
+					continue
+				}
+				if !hasInlineContent(cell.children) {
+					continue
+				}
+				strong := newElementNode("strong")
+				for _, c := range cell.children {
+					strong.appendChild(c)
+				}
+				cell.children = nil
+				cell.appendChild(strong)
+			}
+		}
+	}
+}
+
+// normalizeTableSections lays out every table with <thead>, <tbody> or
+// <tfoot> sections as a single header row followed by the body rows. Several
+// <thead> rows merge into one header row, since Markdown tables have only
+// one; <tfoot> rows become body rows, moved after the others with
+// TableSectionStyleFooterLast.
+func normalizeTableSections(root *htmlNode, style TableSectionStyle) {
+	tables := root.findAll("table")
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		if !slices.ContainsFunc(table.children, isTableSection) {
+			continue
+		}
+		var head, body, foot []*htmlNode
+		for _, row := range tableRows(table) {
+			switch {
+			case row.parent.tag == "thead":
+				head = append(head, row)
+			case row.parent.tag == "tfoot" && style == TableSectionStyleFooterLast:
+				foot = append(foot, row)
+			default:
+				body = append(body, row)
+			}
+		}
+
+		var header *htmlNode
+		if len(head) > 0 {
+			header = mergeHeaderRows(table, head)
+		}
+		children := make([]*htmlNode, 0, len(table.children))
+		for _, c := range table.children {
+			if c.typ != htmlElementNode || c.tag != "tr" && !isTableSection(c) {
+				children = append(children, c)
+			}
+		}
+		table.children = children
+		if header != nil {
+			thead := newElementNode("thead")
+			thead.appendChild(header)
+			table.appendChild(thead)
+		}
+		tbody := newElementNode("tbody")
+		for _, row := range append(body, foot...) {
+			tbody.appendChild(row)
+		}
+		table.appendChild(tbody)
+	}
+}
+
+func isTableSection(n *htmlNode) bool {
+	return n.typ == htmlElementNode && (n.tag == "thead" || n.tag == "tbody" || n.tag == "tfoot")
+}
+
+// mergeHeaderRows returns a single row of <th> cells holding the content of
+// the head rows of table. Each column gathers the cells covering it, top to
+// bottom; a cell spanning several columns contributes to the first only.
+func mergeHeaderRows(table *htmlNode, head []*htmlNode) *htmlNode {
+	if len(head) == 1 {
+		for _, cell := range rowCells(head[0]) {
+			cell.tag = "th"
+		}
+		return head[0]
+	}
+
+	columns := map[int][]*htmlNode{}
+	width := 0
+	for _, row := range layoutTable(table) {
+		for _, cell := range row {
+			if !slices.Contains(head, cell.node.parent) {
+				continue
+			}
+			columns[cell.col] = append(columns[cell.col], cell.node)
+			width = max(width, cell.col+cell.colspan)
+		}
+	}
+	tr := newElementNode("tr")
+	for col := 0; col < width; col++ {
+		th := newElementNode("th")
+		for _, cell := range columns[col] {
+			if len(th.children) > 0 && len(cell.children) > 0 {
+				th.appendChild(&htmlNode{typ: htmlTextNode, data: " "})
+			}
+			for _, c := range cell.children {
+				th.appendChild(c)
+			}
+		}
+		tr.appendChild(th)
+	}
+	return tr
+}
+
+// spanFiller returns the cell placed in a position covered by cell's span.
+func spanFiller(cell *htmlNode, mode TableSpanMode) *htmlNode {
+	if mode == TableSpanModeExpand {
+		return cell.clone()
+	}
+	return newElementNode(cell.tag, append([]htmlAttr(nil), cell.attrs...)...)
+}
+
+// columnAlignment is the alignment of a Markdown table column.
+type columnAlignment int
+
+const (
+	alignNone columnAlignment = iota
+	alignLeft
+	alignCenter
+	alignRight
+)
+
+var textAlignPattern = regexp.MustCompile(`(?i)text-align\s*:\s*([a-z-]+)`)
+
+// cellAlignment reads a cell's alignment from its align attribute or a
+// text-align declaration in its style attribute.
+func cellAlignment(cell *htmlNode) columnAlignment {
+	value := cell.attrOr("align", "")
+	if m := textAlignPattern.FindStringSubmatch(cell.attrOr("style", "")); m != nil {
+		value = m[1]
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "left", "start":
+		return alignLeft
+	case "center":
+		return alignCenter
+	case "right", "end":
+		return alignRight
+	default:
+		return alignNone
+	}
+}
+
+// hasAlignedTables reports whether root holds a table with aligned columns.
+func hasAlignedTables(root *htmlNode) bool {
+	for _, table := range root.findAll("table") {
+		if tableAlignments(table) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// tableAlignments returns the alignment of each column of table. A column
+// takes the alignment of its header cell, or of its first aligned cell when
+// the header does not declare one.
+func tableAlignments(table *htmlNode) []columnAlignment {
+	var aligns []columnAlignment
+	for _, row := range layoutTable(table) {
+		for _, cell := range row {
+			for len(aligns) < cell.col+cell.colspan {
+				aligns = append(aligns, alignNone)
+			}
+			if aligns[cell.col] == alignNone {
+				aligns[cell.col] = cellAlignment(cell.node)
+			}
+		}
+	}
+	for _, a := range aligns {
+		if a != alignNone {
+			return aligns
+		}
+	}
+	return nil
+}
+
+// renderTableCaptions moves the <caption> of every table to a line above it,
+// in italics or bold, so it precedes the header row.
+func renderTableCaptions(root *htmlNode, style TableCaptionStyle, fragments *fragmentSet) error {
+	for _, table := range root.findAll("table") {
+		var caption *htmlNode
+		for _, c := range table.children {
+			if c.typ == htmlElementNode && c.tag == "caption" {
+				caption = c
+				break
+			}
+		}
+		if caption == nil {
+			continue
+		}
+		caption.remove()
+		markdown, err := convertFFI(caption.renderChildren())
+		if err != nil {
+			return err
+		}
+		text := strings.Join(strings.Fields(fragments.restore(markdown)), " ")
+		if text == "" {
+			continue
+		}
+		delimiter := "*"
+		if style == TableCaptionStyleBold {
+			delimiter = "**"
+		}
+		wrapper := newElementNode("div")
+		table.replaceWith(wrapper)
+		wrapper.appendChild(fragments.node(delimiter+text+delimiter, false))
+		wrapper.appendChild(table)
+	}
+	return nil
+}
+
+// alignTables converts every table with aligned columns on its own and
+// replaces it with a fragment whose delimiter row carries the alignment.
+func alignTables(root *htmlNode, fragments *fragmentSet) error {
+	tables := root.findAll("table")
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		aligns := tableAlignments(table)
+		if aligns == nil {
+			continue
+		}
+		markdown, err := convertFFI(table.render())
+		if err != nil {
+			return err
+		}
+		markdown = alignDelimiterRow(fragments.restore(markdown), aligns)
+		table.replaceWith(fragments.node(markdown, false))
+	}
+	return nil
+}
+
+var delimiterRowPattern = regexp.MustCompile(`^\s*\|(\s*:?-+:?\s*\|)+\s*$`)
+
+// alignDelimiterRow rewrites the first delimiter row of a Markdown table.
+func alignDelimiterRow(markdown string, aligns []columnAlignment) string {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		if !delimiterRowPattern.MatchString(line) {
+			continue
+		}
+		indent := line[:strings.Index(line, "|")]
+		cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+		for col := range cells {
+			align := alignNone
+			if col < len(aligns) {
+				align = aligns[col]
+			}
+			cells[col] = " " + delimiterCell(align) + " "
+		}
+		lines[i] = indent + "|" + strings.Join(cells, "|") + "|"
+		break
+	}
+	return strings.Join(lines, "\n")
+}
+
+func delimiterCell(align columnAlignment) string {
+	switch align {
+	case alignLeft:
+		return ":---"
+	case alignCenter:
+		return ":---:"
+	case alignRight:
+		return "---:"
+	default:
+		return "---"
+	}
+}
+
+// renderDelimitedTables replaces every table with a fenced code block holding
+// its rows as RFC 4180 CSV, or tab-separated values for TableFormatTSV.
+func renderDelimitedTables(root *htmlNode, format TableFormat, fragments *fragmentSet) error {
+	tables := root.findAll("table")
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+		if hasSpans(table) {
+			normalizeTable(table, TableSpanModeIgnore)
+		}
+
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		if format == TableFormatTSV {
+			w.Comma = '\t'
+		}
+		for _, row := range tableRows(table) {
+			cells := rowCells(row)
+			record := make([]string, len(cells))
+			for j, cell := range cells {
+				record[j] = fragments.restore(cellText(cell))
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		block := "```" + string(format) + "\n" + b.String() + "```"
+		table.replaceWith(fragments.node(block, false))
+	}
+	return nil
+}
+
+// hasSpans reports whether any cell of table spans several rows or columns.
+func hasSpans(table *htmlNode) bool {
+	for _, row := range layoutTable(table) {
+		for _, cell := range row {
+			if cell.colspan > 1 || cell.rowspan > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cellText returns the plain text of a cell with whitespace collapsed within
+// each line; line breaks come from <br> elements only.
+func cellText(cell *htmlNode) string {
+	var b strings.Builder
+	cell.walk(func(n *htmlNode) bool {
+		switch {
+		case n.typ == htmlTextNode:
+			b.WriteString(strings.NewReplacer("\n", " ", "\r", " ").Replace(html.UnescapeString(n.data)))
+		case n.tag == "br":
+			b.WriteByte('\n')
+		case rawTextElements[n.tag]:
+			return false
+		}
+		return true
+	})
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,155 @@
+//go:build cgo
+
+package htmltomarkdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLayoutTable(t *testing.T) {
+	root := parseHTML(`<table>
+<tr><th colspan="2">A</th><th rowspan="2">B</th></tr>
+<tr><td>1</td><td>2</td></tr>
+<tr><td colspan="x">3</td><td rowspan="9">4</td></tr>
+</table>`)
+	layout := layoutTable(root.findAll("table")[0])
+	if len(layout) != 3 {
+		t.Fatalf("layoutTable returned %d rows, expected 3", len(layout))
+	}
+
+	tests := []struct {
+		name                       string
+		cell                       tableCell
+		row, col, colspan, rowspan int
+	}{
+		{name: "colspan header", cell: layout[0][0], row: 0, col: 0, colspan: 2, rowspan: 1},
+		{name: "rowspan header", cell: layout[0][1], row: 0, col: 2, colspan: 1, rowspan: 2},
+		{name: "second row", cell: layout[1][1], row: 1, col: 1, colspan: 1, rowspan: 1},
+		{name: "invalid colspan", cell: layout[2][0], row: 2, col: 0, colspan: 1, rowspan: 1},
+		{name: "rowspan clamped to table", cell: layout[2][1], row: 2, col: 1, colspan: 1, rowspan: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.cell
+			if c.row != tt.row || c.col != tt.col || c.colspan != tt.colspan || c.rowspan != tt.rowspan {
+				t.Errorf("cell = row %d col %d span %dx%d, expected row %d col %d span %dx%d",
+					c.row, c.col, c.colspan, c.rowspan, tt.row, tt.col, tt.colspan, tt.rowspan)
+			}
+		})
+	}
+}
+
+func TestNormalizeTableSpans(t *testing.T) {
+	html := `<table><tr><td rowspan="2">R</td><td colspan="2">C</td></tr><tr><td>x</td><td>y</td></tr></table>`
+
+	tests := []struct {
+		name     string
+		mode     TableSpanMode
+		expected string
+	}{
+		{
+			name:     "expand",
+			mode:     TableSpanModeExpand,
+			expected: `<table><tbody><tr><td>R</td><td>C</td><td>C</td></tr><tr><td>R</td><td>x</td><td>y</td></tr></tbody></table>`,
+		},
+		{
+			name:     "ignore",
+			mode:     TableSpanModeIgnore,
+			expected: `<table><tbody><tr><td>R</td><td>C</td><td></td></tr><tr><td></td><td>x</td><td>y</td></tr></tbody></table>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseHTML(html)
+			normalizeTableSpans(root, tt.mode)
+			if got := root.findAll("body")[0].renderChildren(); got != tt.expected {
+				t.Errorf("normalizeTableSpans() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableAlignments(t *testing.T) {
+	root := parseHTML(`<table>
+<tr><th align="left">L</th><th align="RIGHT">R</th><th style="color: red; text-align: center">C</th><th>U</th><th>B</th></tr>
+<tr><td>1</td><td>2</td><td>3</td><td>4</td><td style="text-align:right">5</td></tr>
+</table>`)
+	expected := []columnAlignment{alignLeft, alignRight, alignCenter, alignNone, alignRight}
+
+	aligns := tableAlignments(root.findAll("table")[0])
+	if len(aligns) != len(expected) {
+		t.Fatalf("tableAlignments() = %v, expected %v", aligns, expected)
+	}
+	for i := range expected {
+		if aligns[i] != expected[i] {
+			t.Errorf("column %d alignment = %v, expected %v", i, aligns[i], expected[i])
+		}
+	}
+
+	if aligns := tableAlignments(parseHTML(`<table><tr><td>x</td></tr></table>`).findAll("table")[0]); aligns != nil {
+		t.Errorf("tableAlignments() = %v, expected nil for unaligned table", aligns)
+	}
+}
+
+func TestAlignDelimiterRow(t *testing.T) {
+	markdown := "| L | R | C | U |\n| --- | --- | --- | --- |\n| 1 | 2 | 3 | 4 |"
+	aligns := []columnAlignment{alignLeft, alignRight, alignCenter, alignNone}
+	expected := "| L | R | C | U |\n| :--- | ---: | :---: | --- |\n| 1 | 2 | 3 | 4 |"
+
+	if got := alignDelimiterRow(markdown, aligns); got != expected {
+		t.Errorf("alignDelimiterRow() = %q, expected %q", got, expected)
+	}
+}
+
+func TestConvert_TableAlignment(t *testing.T) {
+	html := `<table>
+<thead><tr><th align="left">Left</th><th align="right">Right</th><th style="text-align:center">Center</th><th>Unset</th></tr></thead>
+<tbody><tr><td>a</td><td>b</td><td>c</td><td>d</td></tr></tbody>
+</table>`
+
+	result, err := Convert(html)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if !strings.Contains(result, "| :--- | ---: | :---: | --- |") {
+		t.Errorf("Result = %q, expected aligned delimiter row", result)
+	}
+	if !strings.Contains(result, "| a | b | c | d |") {
+		t.Errorf("Result = %q, expected body row", result)
+	}
+}
+
+func TestHasAlignedTables(t *testing.T) {
+	tests := []struct {
+		html     string
+		expected bool
+	}{
+		{`<table><tr><td align="right">1</td></tr></table>`, true},
+		{`<table><tr><th style="text-align: center">A</th></tr></table>`, true},
+		{`<table><tr><td>1</td></tr></table>`, false},
+		{`<div style="text-align:center" class="align-left"><p>Align the text</p></div>`, false},
+	}
+	for _, tt := range tests {
+		if got := hasAlignedTables(parseHTML(tt.html)); got != tt.expected {
+			t.Errorf("hasAlignedTables(%q) = %v, expected %v", tt.html, got, tt.expected)
+		}
+	}
+}
+
+func TestConvert_AlignOutsideTablesStaysNative(t *testing.T) {
+	html := `<div style="text-align:center"><p>Align a<b and c>d</p></div>`
+	expected, err := convertFFI(html)
+	if err != nil {
+		t.Fatalf("convertFFI failed: %v", err)
+	}
+	result, err := Convert(html)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("Convert() = %q, expected the native output %q", result, expected)
+	}
+}
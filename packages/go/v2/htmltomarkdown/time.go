@@ -0,0 +1,25 @@
+package htmltomarkdown
+
+import (
+	"html"
+	"strings"
+)
+
+// renderTimes rewrites every <time> with a datetime attribute in the
+// requested style. Elements without a datetime keep their text.
+func renderTimes(root *htmlNode, style TimeStyle) {
+	for _, n := range root.findAll("time") {
+		datetime := strings.TrimSpace(n.attrOr("datetime", ""))
+		if datetime == "" {
+			continue
+		}
+		value := &htmlNode{typ: htmlTextNode, data: html.EscapeString(datetime)}
+		switch {
+		case style == TimeStyleISO || strings.TrimSpace(n.text()) == "":
+			n.replaceWith(value)
+		case style == TimeStyleBoth:
+			value.data = " (" + value.data + ")"
+			n.replaceWith(append(n.children, value)...)
+		}
+	}
+}
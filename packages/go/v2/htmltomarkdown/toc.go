@@ -0,0 +1,108 @@
+package htmltomarkdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tocMarker is the comment marking where GenerateTOC inserts the table of
+// contents.
+const tocMarker = "toc"
+
+var linkLabelEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// insertTOC adds a nested list linking to every heading of root, at the
+// first <!--toc--> comment or else at the start of the body. Links target
+// the anchors GitHub derives from the heading text.
+func insertTOC(root *htmlNode, fragments *fragmentSet) {
+	headings := root.findAll("h1", "h2", "h3", "h4", "h5", "h6")
+	slugs := newSlugger()
+	var lines []string
+	minLevel, depth := 7, -1
+	for _, h := range headings {
+		minLevel = min(minLevel, int(h.tag[1]-'0'))
+	}
+	for _, h := range headings {
+		text := h.normalizedText()
+		if text == "" {
+			continue
+		}
+		depth = min(int(h.tag[1]-'0')-minLevel, depth+1)
+		lines = append(lines, strings.Repeat("  ", depth)+"- ["+linkLabelEscaper.Replace(text)+"](#"+slugs.unique(text)+")")
+	}
+
+	var marker *htmlNode
+	root.walk(func(n *htmlNode) bool {
+		if marker == nil && n.typ == htmlCommentNode && isTOCMarker(n.data) {
+			marker = n
+		}
+		return marker == nil
+	})
+	if len(lines) == 0 {
+		if marker != nil {
+			marker.remove()
+		}
+		return
+	}
+	toc := fragments.node(strings.Join(lines, "\n"), false)
+	if marker != nil {
+		marker.replaceWith(toc)
+		return
+	}
+	parent := root
+	if bodies := root.findAll("body"); len(bodies) > 0 {
+		parent = bodies[0]
+	}
+	toc.parent = parent
+	parent.children = append([]*htmlNode{toc}, parent.children...)
+}
+
+func isTOCMarker(comment string) bool {
+	inner := strings.TrimSuffix(strings.TrimPrefix(comment, "<!--"), "-->")
+	return strings.EqualFold(strings.TrimSpace(inner), tocMarker)
+}
+
+// Slugify returns the anchor GitHub generates for a heading with text, as
+// used by the GenerateTOC option: lowercased, with punctuation removed and
+// spaces turned into hyphens. Letters, marks and digits of any script are
+// kept. Repeated headings get a -1, -2, ... suffix on top of the slug.
+//
+// Example:
+//
+//	slug := htmltomarkdown.Slugify("What's new in v2.0?")
+//	// slug == "whats-new-in-v20"
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-', unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.Pc):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// slugger hands out unique slugs, suffixing repeated ones with -1, -2 and
+// so on, as GitHub does for headings with the same text.
+type slugger struct {
+	seen   map[string]bool
+	counts map[string]int
+}
+
+func newSlugger() *slugger {
+	return &slugger{seen: map[string]bool{}, counts: map[string]int{}}
+}
+
+func (s *slugger) unique(text string) string {
+	base := Slugify(text)
+	slug := base
+	for s.seen[slug] {
+		s.counts[base]++
+		slug = base + "-" + strconv.Itoa(s.counts[base])
+	}
+	s.seen[slug] = true
+	return slug
+}
@@ -0,0 +1,37 @@
+package htmltomarkdown
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Getting Started", "getting-started"},
+		{"What's new in v2.0?", "whats-new-in-v20"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"A & B", "a--b"},
+		{"snake_case-and-kebab", "snake_case-and-kebab"},
+		{"Café Über", "café-über"},
+		{"日本語 見出し", "日本語-見出し"},
+		{"Привет, мир!", "привет-мир"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.text); got != tt.expected {
+			t.Errorf("Slugify(%q) = %q, expected %q", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestSluggerUnique(t *testing.T) {
+	s := newSlugger()
+	for _, expected := range []string{"notes", "notes-1", "notes-2"} {
+		if got := s.unique("Notes"); got != expected {
+			t.Errorf("unique(%q) = %q, expected %q", "Notes", got, expected)
+		}
+	}
+	if got := s.unique("Notes 1"); got != "notes-1-1" {
+		t.Errorf("unique(%q) = %q, expected %q", "Notes 1", got, "notes-1-1")
+	}
+}
@@ -0,0 +1,139 @@
+package htmltomarkdown
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// The conversion phases recorded by StartTracing. They are phases of the Go
+// bindings around the native conversion, not of the native core, which is
+// timed as a whole by the render phase.
+const (
+	phaseParse     = "parse"
+	phaseNormalize = "normalize"
+	phaseRender    = "render"
+)
+
+var tracePhases = []string{phaseParse, phaseNormalize, phaseRender}
+
+// traceState accumulates the phase timings of the conversions run while
+// tracing is active.
+type traceState struct {
+	outputPath  string
+	conversions int
+	durations   map[string]time.Duration
+	calls       map[string]int
+}
+
+var (
+	traceMutex sync.Mutex
+	trace      *traceState
+)
+
+// TracePhase is the JSON record of one conversion phase written by
+// StopTracing.
+type TracePhase struct {
+	// Name is the phase: "parse" for reading the HTML into a tree,
+	// "normalize" for choosing and applying the Go-side option transforms,
+	// and for dispatching the callbacks of ConvertWithVisitor, and "render"
+	// for the native conversion, with the metadata extraction of
+	// ConvertWithMetadataOptions, and the restoration of Go-side output.
+	Name string `json:"name"`
+
+	// Calls is the number of times the phase ran.
+	Calls int `json:"calls"`
+
+	// DurationNS is the total time spent in the phase, in nanoseconds.
+	DurationNS int64 `json:"duration_ns"`
+}
+
+// TraceReport is the JSON document written by StopTracing.
+type TraceReport struct {
+	// Conversions is the number of conversions traced.
+	Conversions int `json:"conversions"`
+
+	// Phases lists the parse, normalize and render phases in that order.
+	Phases []TracePhase `json:"phases"`
+}
+
+// StartTracing begins recording the exact time spent in each conversion
+// phase, without the sampling of StartProfiling, so that CI can compare runs.
+// The timings of every conversion until StopTracing are summed and written to
+// outputPath as a TraceReport. The phases are those of the Go bindings; the
+// native library's own parsing and rendering are both part of the render
+// phase, so use StartProfiling to break down the time spent in the core.
+func StartTracing(outputPath string) error {
+	if outputPath == "" {
+		return errors.New("output path is required")
+	}
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	if trace != nil {
+		return errors.New("tracing is already active")
+	}
+	trace = &traceState{
+		outputPath: outputPath,
+		durations:  make(map[string]time.Duration),
+		calls:      make(map[string]int),
+	}
+	return nil
+}
+
+// StopTracing stops the tracing begun by StartTracing and writes the
+// TraceReport to its output path.
+func StopTracing() error {
+	traceMutex.Lock()
+	state := trace
+	trace = nil
+	traceMutex.Unlock()
+	if state == nil {
+		return errors.New("tracing is not active")
+	}
+
+	report := TraceReport{Conversions: state.conversions, Phases: make([]TracePhase, 0, len(tracePhases))}
+	for _, name := range tracePhases {
+		report.Phases = append(report.Phases, TracePhase{
+			Name:       name,
+			Calls:      state.calls[name],
+			DurationNS: state.durations[name].Nanoseconds(),
+		})
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(state.outputPath, append(data, '\n'), 0o644)
+}
+
+// tracePhase returns a function to call at the end of the named phase,
+// which records its duration while tracing is active.
+func tracePhase(name string) func() {
+	traceMutex.Lock()
+	active := trace != nil
+	traceMutex.Unlock()
+	if !active {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		traceMutex.Lock()
+		defer traceMutex.Unlock()
+		if trace != nil {
+			trace.durations[name] += elapsed
+			trace.calls[name]++
+		}
+	}
+}
+
+// traceConversion counts a conversion while tracing is active.
+func traceConversion() {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	if trace != nil {
+		trace.conversions++
+	}
+}
@@ -0,0 +1,103 @@
+package htmltomarkdown
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartTracing_RecordsPhases(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "trace.json")
+	if err := StartTracing(output); err != nil {
+		t.Fatalf("StartTracing failed: %v", err)
+	}
+	if err := StartTracing(output); err == nil {
+		t.Error("expected an error when tracing is already active")
+	}
+
+	html := strings.Repeat(`<h2>Section</h2><p>Some <em>text</em> with <a href="/x">a link</a>.</p>`, 200)
+	for range 3 {
+		if _, err := ConvertWithOptions(html, ConversionOptions{NormalizeNbsp: true}); err != nil {
+			t.Fatalf("ConvertWithOptions failed: %v", err)
+		}
+	}
+	if err := StopTracing(); err != nil {
+		t.Fatalf("StopTracing failed: %v", err)
+	}
+	if err := StopTracing(); err == nil {
+		t.Error("expected an error when tracing is not active")
+	}
+	checkTraceReport(t, output, 3)
+}
+
+func TestStartTracing_RecordsPhasesOfConvert(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "trace.json")
+	if err := StartTracing(output); err != nil {
+		t.Fatalf("StartTracing failed: %v", err)
+	}
+	html := strings.Repeat(`<h2>Section</h2><p>Some <em>text</em> with <a href="/x">a link</a>.</p>`, 200)
+	for range 2 {
+		if _, err := Convert(html); err != nil {
+			t.Fatalf("Convert failed: %v", err)
+		}
+	}
+	if _, err := Convert(html + "<pre>code</pre>"); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if err := StopTracing(); err != nil {
+		t.Fatalf("StopTracing failed: %v", err)
+	}
+	checkTraceReport(t, output, 3)
+}
+
+func TestStartTracing_RecordsPhasesOfVisitorAndMetadata(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "trace.json")
+	if err := StartTracing(output); err != nil {
+		t.Fatalf("StartTracing failed: %v", err)
+	}
+	html := strings.Repeat(`<h2>Section</h2><p>Some <em>text</em> with <a href="/x">a link</a>.</p>`, 200)
+	visitor := &Visitor{OnText: func(ctx *NodeContext, text string) *VisitResult { return nil }}
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	// Without cgo the metadata extraction fails in the render phase, after
+	// the other phases ran.
+	if _, err := ConvertWithMetadataOptions(html, ConversionOptions{NormalizeNbsp: true}); err != nil && !errors.Is(err, ErrNativeUnavailable) {
+		t.Fatalf("ConvertWithMetadataOptions failed: %v", err)
+	}
+	if err := StopTracing(); err != nil {
+		t.Fatalf("StopTracing failed: %v", err)
+	}
+	checkTraceReport(t, output, 2)
+}
+
+// checkTraceReport checks that the report at path counts the given number of
+// conversions, each of which ran every phase once.
+func checkTraceReport(t *testing.T, path string, conversions int) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading trace: %v", err)
+	}
+	var report TraceReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("trace is not valid JSON: %v", err)
+	}
+	if report.Conversions != conversions {
+		t.Errorf("Conversions = %d, expected %d", report.Conversions, conversions)
+	}
+	var names []string
+	for _, phase := range report.Phases {
+		names = append(names, phase.Name)
+		if phase.Calls != conversions || phase.DurationNS <= 0 {
+			t.Errorf("phase %s: calls = %d, duration = %dns; expected %d calls and a positive duration",
+				phase.Name, phase.Calls, phase.DurationNS, conversions)
+		}
+	}
+	if got := strings.Join(names, ","); got != "parse,normalize,render" {
+		t.Errorf("phases = %s, expected parse,normalize,render", got)
+	}
+}
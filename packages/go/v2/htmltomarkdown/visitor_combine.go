@@ -0,0 +1,116 @@
+package htmltomarkdown
+
+import "reflect"
+
+// CombineVisitors returns a visitor running each of visitors in order for
+// every callback. The first result other than VisitContinue wins and the
+// remaining visitors are not called for that node; nil results count as
+// VisitContinue. A RewriteURL returned with VisitContinue from OnLink or
+// OnImage is passed on: later visitors receive the rewritten URL, and the
+// last rewrite applies unless a later visitor takes over the node.
+//
+// Whitespace-only text nodes are delivered to the OnText of the visitors
+// that do not set SkipWhitespaceTextNodes, and ElementHandlers for the same
+// tag are combined like the other callbacks. Nil visitors are ignored.
+//
+// Example:
+//
+//	visitor := htmltomarkdown.CombineVisitors(analytics, linkRewriter, sanitizer)
+//	markdown, err := htmltomarkdown.ConvertWithVisitor(html, visitor)
+func CombineVisitors(visitors ...*Visitor) *Visitor {
+	var active []*Visitor
+	for _, v := range visitors {
+		if v != nil {
+			active = append(active, v)
+		}
+	}
+	combined := &Visitor{SkipWhitespaceTextNodes: true}
+	for _, v := range active {
+		if v.OnText != nil && !v.SkipWhitespaceTextNodes {
+			combined.SkipWhitespaceTextNodes = false
+		}
+	}
+
+	out := reflect.ValueOf(combined).Elem()
+	resultType := reflect.TypeFor[*VisitResult]()
+	for i := range out.NumField() {
+		field := out.Type().Field(i)
+		if field.Type.Kind() != reflect.Func || field.Type.NumOut() != 1 || field.Type.Out(0) != resultType {
+			continue
+		}
+		var fns []reflect.Value
+		for _, v := range active {
+			fn := reflect.ValueOf(v).Elem().Field(i)
+			if fn.IsNil() {
+				continue
+			}
+			if field.Name == "OnText" && v.SkipWhitespaceTextNodes && !combined.SkipWhitespaceTextNodes {
+				fn = reflect.ValueOf(skipWhitespaceText(v.OnText))
+			}
+			fns = append(fns, fn)
+		}
+		rewrites := field.Name == "OnLink" || field.Name == "OnImage"
+		if f := combineCallbacks(field.Type, fns, rewrites); f.IsValid() {
+			out.Field(i).Set(f)
+		}
+	}
+
+	for _, v := range active {
+		for tag := range v.ElementHandlers {
+			if combined.ElementHandlers == nil {
+				combined.ElementHandlers = make(map[string]func(ctx *NodeContext, html string) *VisitResult)
+			}
+			if _, done := combined.ElementHandlers[tag]; done {
+				continue
+			}
+			var fns []reflect.Value
+			for _, w := range active {
+				if fn := w.ElementHandlers[tag]; fn != nil {
+					fns = append(fns, reflect.ValueOf(fn))
+				}
+			}
+			if handler := combineCallbacks(reflect.TypeOf(v.ElementHandlers[tag]), fns, false); handler.IsValid() {
+				combined.ElementHandlers[tag] = handler.Interface().(func(ctx *NodeContext, html string) *VisitResult)
+			}
+		}
+	}
+	return combined
+}
+
+// combineCallbacks returns a callback of type typ calling fns in order until
+// one returns a result other than VisitContinue. With rewrites set, the
+// second argument is the URL that a VisitContinue result may rewrite. It
+// returns the zero Value when fns is empty.
+func combineCallbacks(typ reflect.Type, fns []reflect.Value, rewrites bool) reflect.Value {
+	switch len(fns) {
+	case 0:
+		return reflect.Value{}
+	case 1:
+		return fns[0]
+	}
+	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
+		args = append([]reflect.Value(nil), args...)
+		var rewritten *VisitResult
+		for _, fn := range fns {
+			vr, _ := fn.Call(args)[0].Interface().(*VisitResult)
+			if vr != nil && vr.ResultType != VisitContinue {
+				return []reflect.Value{reflect.ValueOf(vr)}
+			}
+			if rewrites && vr != nil && vr.RewriteURL != "" {
+				rewritten = vr
+				args[1] = reflect.ValueOf(vr.RewriteURL)
+			}
+		}
+		return []reflect.Value{reflect.ValueOf(rewritten)}
+	})
+}
+
+// skipWhitespaceText wraps fn so that it ignores whitespace-only text nodes.
+func skipWhitespaceText(fn func(ctx *NodeContext, text string) *VisitResult) func(ctx *NodeContext, text string) *VisitResult {
+	return func(ctx *NodeContext, text string) *VisitResult {
+		if ctx.IsWhitespaceOnly {
+			return nil
+		}
+		return fn(ctx, text)
+	}
+}
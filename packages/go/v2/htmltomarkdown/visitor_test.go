@@ -1,6 +1,10 @@
+//go:build cgo
+
 package htmltomarkdown
 
 import (
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -362,6 +366,387 @@ func TestConvertWithVisitor_TextVisitor(t *testing.T) {
 	}
 }
 
+func TestConvertWithVisitor_SkipWhitespaceTextNodes(t *testing.T) {
+	html := "<div>\n  <p>First</p>\n  <p>Second</p>\n</div>"
+
+	var texts []string
+	whitespaceFlagged := 0
+	visitor := &Visitor{
+		OnText: func(ctx *NodeContext, text string) *VisitResult {
+			texts = append(texts, text)
+			if ctx.IsWhitespaceOnly {
+				whitespaceFlagged++
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(texts) != 5 || whitespaceFlagged != 3 {
+		t.Errorf("OnText calls = %q with %d flagged as whitespace, expected 5 calls with 3 flagged", texts, whitespaceFlagged)
+	}
+
+	texts = nil
+	visitor.SkipWhitespaceTextNodes = true
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(texts) != 2 || texts[0] != "First" || texts[1] != "Second" {
+		t.Errorf("OnText calls = %q, expected only the paragraph text", texts)
+	}
+}
+
+func TestConvertWithVisitor_SiblingCount(t *testing.T) {
+	html := "<ul>\n<li>One</li>\n<li>Two</li>\n<li>Three</li>\n</ul>"
+
+	var items []NodeContext
+	visitor := &Visitor{
+		OnListItem: func(ctx *NodeContext, ordered bool, marker, text string) *VisitResult {
+			items = append(items, *ctx)
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("OnListItem called %d times, expected 3", len(items))
+	}
+	last := items[2]
+	if last.IndexInParent != 2 || last.SiblingCount != 3 {
+		t.Errorf("third item IndexInParent = %d, SiblingCount = %d, expected 2 and 3", last.IndexInParent, last.SiblingCount)
+	}
+}
+
+func TestConvertWithVisitor_ListItemMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []string
+	}{
+		{name: "start", html: `<ol start="4"><li>a</li><li>b</li></ol>`, expected: []string{"4.", "5."}},
+		{name: "reversed", html: `<ol reversed><li>a</li><li>b</li><li>c</li></ol>`, expected: []string{"3.", "2.", "1."}},
+		{name: "value", html: `<ol><li>a</li><li value="7">b</li><li>c</li></ol>`, expected: []string{"1.", "7.", "8."}},
+		{name: "reversed with value", html: `<ol reversed><li>a</li><li value="10">b</li><li>c</li></ol>`, expected: []string{"3.", "10.", "9."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var markers []string
+			visitor := &Visitor{
+				OnListItem: func(ctx *NodeContext, ordered bool, marker, text string) *VisitResult {
+					markers = append(markers, marker)
+					return nil
+				},
+			}
+			if _, err := ConvertWithVisitor(tt.html, visitor); err != nil {
+				t.Fatalf("ConvertWithVisitor failed: %v", err)
+			}
+			if !slices.Equal(markers, tt.expected) {
+				t.Errorf("OnListItem markers = %v, expected %v", markers, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWithVisitor_HTMLOffset(t *testing.T) {
+	html := "<h1>Title</h1>\n<p>First</p>\n<blockquote>Quote</blockquote>\n<p>Second</p>"
+
+	var offsets []uint32
+	visitor := &Visitor{
+		OnElementStart: func(ctx *NodeContext) *VisitResult {
+			offsets = append(offsets, ctx.HTMLOffset)
+			if want := strings.Index(html[ctx.HTMLOffset:], "<"+ctx.TagName); want != 0 {
+				t.Errorf("HTMLOffset %d of <%s> does not point at its start tag", ctx.HTMLOffset, ctx.TagName)
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(offsets) != 4 {
+		t.Fatalf("OnElementStart called %d times, expected 4", len(offsets))
+	}
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] < offsets[i-1] {
+			t.Errorf("offsets %v are not monotonically non-decreasing", offsets)
+		}
+	}
+}
+
+func TestConvertWithMetadataAndVisitor(t *testing.T) {
+	html := `<html>
+<head><title>Gallery</title></head>
+<body>
+<p>Photos below.</p>
+<img src="https://example.com/one.jpg" alt="One">
+<img src="https://example.com/two.jpg" alt="Two">
+</body>
+</html>`
+
+	visitor := &Visitor{
+		OnImage: func(ctx *NodeContext, src, alt, title string) *VisitResult {
+			return &VisitResult{ResultType: VisitSkip}
+		},
+	}
+
+	result, err := ConvertWithMetadataAndVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithMetadataAndVisitor failed: %v", err)
+	}
+	if strings.Contains(result.Markdown, "one.jpg") || !strings.Contains(result.Markdown, "Photos below.") {
+		t.Errorf("Markdown = %q, expected images skipped and text kept", result.Markdown)
+	}
+	if len(result.Metadata.Images) != 2 {
+		t.Errorf("Metadata.Images has %d entries, expected both images of the original document", len(result.Metadata.Images))
+	}
+	if result.Metadata.Document.Title == nil || *result.Metadata.Document.Title != "Gallery" {
+		t.Errorf("Metadata.Document.Title = %v, expected 'Gallery'", result.Metadata.Document.Title)
+	}
+}
+
+func TestConvertWithVisitor_ElementText(t *testing.T) {
+	html := `<div>Hello <span>big</span> <em>world</em></div>`
+
+	texts := map[string]string{}
+	visitor := &Visitor{
+		OnElementText: func(ctx *NodeContext, text string) *VisitResult {
+			texts[ctx.TagName] = text
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if texts["div"] != "Hello big world" {
+		t.Errorf("OnElementText for div = %q, expected %q", texts["div"], "Hello big world")
+	}
+	if texts["em"] != "world" {
+		t.Errorf("OnElementText for em = %q, expected %q", texts["em"], "world")
+	}
+}
+
+func TestConvertWithVisitor_ElementTextNested(t *testing.T) {
+	html := `<article><section><p>Keep <b>secret</b> here</p></section><p>End</p></article>`
+
+	texts := map[string]string{}
+	visitor := &Visitor{
+		OnText: func(ctx *NodeContext, text string) *VisitResult {
+			if text == "secret" {
+				return &VisitResult{ResultType: VisitCustom, CustomOutput: "[redacted]"}
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+		OnElementText: func(ctx *NodeContext, text string) *VisitResult {
+			texts[ctx.TagName] = text
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	expected := map[string]string{
+		"b":       "[redacted]",
+		"section": "Keep [redacted] here",
+		"article": "Keep [redacted] hereEnd",
+	}
+	for tag, want := range expected {
+		if texts[tag] != want {
+			t.Errorf("OnElementText for %s = %q, expected %q", tag, texts[tag], want)
+		}
+	}
+}
+
+func TestVisitorWalker_ReusesNestedMarkdown(t *testing.T) {
+	root := parseHTML(`<div><blockquote><p>Deep <a href="/a">link</a></p></blockquote><p>Tail</p></div>`)
+	outer := root.findAll("div")[0]
+	w := newVisitorWalker(&Visitor{})
+
+	output, err := w.markdownOf(outer, false)
+	if err != nil {
+		t.Fatalf("markdownOf failed: %v", err)
+	}
+	expected, err := convertFFI(outer.render())
+	if err != nil {
+		t.Fatalf("convertFFI failed: %v", err)
+	}
+	if output != strings.Trim(expected, "\n") {
+		t.Errorf("markdownOf() = %q, expected %q", output, strings.Trim(expected, "\n"))
+	}
+	for _, n := range outer.findAll("blockquote", "p") {
+		if _, ok := w.outputs[n]; !ok {
+			t.Errorf("markdown of <%s> was not cached for reuse", n.tag)
+		}
+	}
+
+	link := outer.findAll("a")[0]
+	w.invalidate(link)
+	link.setAttr("href", "/b")
+	if _, ok := w.outputs[outer]; ok {
+		t.Error("changing a descendant should invalidate the cached markdown of its ancestors")
+	}
+	if output, _ := w.markdownOf(outer, false); !strings.Contains(output, "/b") {
+		t.Errorf("markdownOf() after the change = %q, expected the new link", output)
+	}
+}
+
+func TestConvertWithVisitor_ElementEndSeesRewrittenDescendants(t *testing.T) {
+	html := `<section><div><p>See <a href="/old">docs</a></p></div></section>`
+
+	outputs := map[string]string{}
+	visitor := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			return &VisitResult{ResultType: VisitContinue, RewriteURL: "/new"}
+		},
+		OnElementEnd: func(ctx *NodeContext, output string) *VisitResult {
+			outputs[ctx.TagName] = output
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	for _, tag := range []string{"p", "div", "section"} {
+		if !strings.Contains(outputs[tag], "(/new)") {
+			t.Errorf("OnElementEnd output for %s = %q, expected the rewritten link", tag, outputs[tag])
+		}
+	}
+}
+
+func TestConvertWithVisitor_ElementHandlers(t *testing.T) {
+	html := `<x-widget id="w">Widget</x-widget><x-chart data="1,2">Chart</x-chart><x-other>Other</x-other>`
+
+	customCalls := 0
+	visitor := &Visitor{
+		ElementHandlers: map[string]func(ctx *NodeContext, html string) *VisitResult{
+			"x-widget": func(ctx *NodeContext, html string) *VisitResult {
+				if ctx.TagName != "x-widget" || !strings.Contains(html, `id="w"`) {
+					t.Errorf("x-widget handler got tag %q, html %q", ctx.TagName, html)
+				}
+				return &VisitResult{ResultType: VisitCustom, CustomOutput: "[widget]"}
+			},
+			"x-chart": func(ctx *NodeContext, html string) *VisitResult {
+				if ctx.TagName != "x-chart" || !strings.Contains(html, `data="1,2"`) {
+					t.Errorf("x-chart handler got tag %q, html %q", ctx.TagName, html)
+				}
+				return &VisitResult{ResultType: VisitCustom, CustomOutput: "[chart]"}
+			},
+		},
+		OnCustomElement: func(ctx *NodeContext, tagName, html string) *VisitResult {
+			customCalls++
+			if tagName != "x-other" {
+				t.Errorf("OnCustomElement called for %q, expected only x-other", tagName)
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "[widget]") || !strings.Contains(result, "[chart]") {
+		t.Errorf("Expected output from both registered handlers, got: %q", result)
+	}
+	if !strings.Contains(result, "Other") {
+		t.Errorf("Expected unregistered element content to be kept, got: %q", result)
+	}
+	if customCalls != 1 {
+		t.Errorf("OnCustomElement called %d times, expected 1", customCalls)
+	}
+}
+
+func TestConvertWithVisitor_RewriteImageURL(t *testing.T) {
+	html := `<p><img src="http://x/y.jpg" alt="A photo" title="Caption"></p>`
+
+	visitor := &Visitor{
+		OnImage: func(ctx *NodeContext, src, alt, title string) *VisitResult {
+			return &VisitResult{
+				ResultType: VisitContinue,
+				RewriteURL: strings.Replace(src, "http://x/", "https://cdn/", 1),
+			}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "https://cdn/y.jpg") {
+		t.Errorf("Expected rewritten image URL, got: %q", result)
+	}
+	if strings.Contains(result, "http://x/y.jpg") {
+		t.Errorf("Expected original image URL to be replaced, got: %q", result)
+	}
+	if !strings.Contains(result, "![A photo]") {
+		t.Errorf("Expected alt text to survive the rewrite, got: %q", result)
+	}
+}
+
+func TestConvertWithVisitor_RewriteLinkURL(t *testing.T) {
+	html := `<p><a href="https://example.com/page" title="External">External</a> and <a href="/docs">Internal</a></p>`
+
+	visitor := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			if !strings.HasPrefix(href, "http") {
+				return &VisitResult{ResultType: VisitContinue}
+			}
+			return &VisitResult{ResultType: VisitContinue, RewriteURL: href + "?utm=x"}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "[External](https://example.com/page?utm=x") {
+		t.Errorf("Expected external link to carry the UTM parameter, got: %q", result)
+	}
+	if !strings.Contains(result, `"External"`) {
+		t.Errorf("Expected link title to survive the rewrite, got: %q", result)
+	}
+	if !strings.Contains(result, "[Internal](/docs)") {
+		t.Errorf("Expected internal link to be left unchanged, got: %q", result)
+	}
+}
+
+func TestConvertWithVisitor_PreserveHTMLSortsAttributes(t *testing.T) {
+	html := `<p><span title="t" id="s" data-z="z" class="c" aria-label="l">kept</span></p>`
+
+	visitor := &Visitor{
+		OnElementStart: func(ctx *NodeContext) *VisitResult {
+			if ctx.TagName == "span" {
+				return &VisitResult{ResultType: VisitPreserveHTML}
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	first, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	second, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if first != second {
+		t.Errorf("Repeated conversions differ: %q vs %q", first, second)
+	}
+	expected := `<span aria-label="l" class="c" data-z="z" id="s" title="t">kept</span>`
+	if !strings.Contains(first, expected) {
+		t.Errorf("Result = %q, expected attributes in sorted order: %q", first, expected)
+	}
+}
+
 func TestConvertWithVisitor_ElementVisitors(t *testing.T) {
 	html := `<div><p>Content</p></div>`
 
@@ -709,3 +1094,455 @@ func TestConvertWithVisitor_DetailsVisitor(t *testing.T) {
 		t.Error("Result should not be empty")
 	}
 }
+
+func TestConvertWithVisitor_DocumentHooks(t *testing.T) {
+	html := `<p>See <a href="https://example.com">the docs</a>.</p>`
+
+	var events []string
+	var links []string
+	visitor := &Visitor{
+		OnDocumentStart: func(ctx *NodeContext) *VisitResult {
+			events = append(events, "start")
+			links = nil
+			return &VisitResult{ResultType: VisitContinue}
+		},
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			events = append(events, "link")
+			links = append(links, href)
+			return &VisitResult{ResultType: VisitContinue}
+		},
+		OnDocumentEnd: func(ctx *NodeContext, output string) *VisitResult {
+			events = append(events, "end")
+			if !strings.Contains(output, "the docs") {
+				t.Errorf("OnDocumentEnd output = %s, expected to contain 'the docs'", output)
+			}
+			footer := "\n\n---\nLinks: " + strings.Join(links, ", ") + "\n"
+			return &VisitResult{
+				ResultType:   VisitCustom,
+				CustomOutput: strings.TrimRight(output, "\n") + footer,
+			}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(events) < 3 || events[0] != "start" || events[len(events)-1] != "end" {
+		t.Errorf("callback order = %v, expected start first and end last", events)
+	}
+	if !strings.HasSuffix(result, "Links: https://example.com\n") {
+		t.Errorf("Result = %q, expected footer appended by OnDocumentEnd", result)
+	}
+	if !strings.Contains(result, "the docs") {
+		t.Errorf("Result = %q, expected original content to be kept", result)
+	}
+}
+
+func TestConvertWithVisitor_VisitErrorAborts(t *testing.T) {
+	html := `<p>Read <a href="https://example.com/docs">the docs</a> or <a href="http://intranet.local/wiki">the wiki</a>.</p>`
+
+	linksSeen := 0
+	visitor := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			linksSeen++
+			if strings.Contains(href, "intranet.local") {
+				return &VisitResult{
+					ResultType:   VisitError,
+					ErrorMessage: "link to internal host: " + href,
+				}
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err == nil {
+		t.Fatalf("ConvertWithVisitor should fail for a banned host, got %q", result)
+	}
+	if result != "" {
+		t.Errorf("Result = %q, expected empty output on error", result)
+	}
+
+	var visitorErr *VisitorError
+	if !errors.As(err, &visitorErr) {
+		t.Fatalf("error = %T, expected *VisitorError", err)
+	}
+	if visitorErr.TagName != "a" {
+		t.Errorf("VisitorError.TagName = %s, expected 'a'", visitorErr.TagName)
+	}
+	if !strings.Contains(err.Error(), "intranet.local") {
+		t.Errorf("error = %v, expected to contain the callback message", err)
+	}
+	if linksSeen != 2 {
+		t.Errorf("OnLink called %d times, expected 2", linksSeen)
+	}
+}
+
+func TestConvertWithVisitor_VisitErrorFromDocumentEnd(t *testing.T) {
+	visitor := &Visitor{
+		OnDocumentEnd: func(ctx *NodeContext, output string) *VisitResult {
+			return &VisitResult{ResultType: VisitError, ErrorMessage: "rejected"}
+		},
+	}
+
+	_, err := ConvertWithVisitor("<p>Text</p>", visitor)
+	var visitorErr *VisitorError
+	if !errors.As(err, &visitorErr) {
+		t.Fatalf("error = %v, expected *VisitorError", err)
+	}
+	if visitorErr.Message != "rejected" {
+		t.Errorf("VisitorError.Message = %s, expected 'rejected'", visitorErr.Message)
+	}
+}
+
+func TestConvertWithVisitor_CustomAndSkip(t *testing.T) {
+	html := `<p>Keep <strong>bold</strong> <em>drop me</em></p>`
+
+	visitor := &Visitor{
+		OnStrong: func(ctx *NodeContext, text string) *VisitResult {
+			return &VisitResult{ResultType: VisitCustom, CustomOutput: "__" + strings.ToUpper(text) + "__"}
+		},
+		OnEmphasis: func(ctx *NodeContext, text string) *VisitResult {
+			return &VisitResult{ResultType: VisitSkip}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "__BOLD__") {
+		t.Errorf("Result = %q, expected custom output for <strong>", result)
+	}
+	if strings.Contains(result, "drop me") {
+		t.Errorf("Result = %q, expected <em> to be skipped", result)
+	}
+}
+
+func TestConvertWithVisitor_MetaVisitor(t *testing.T) {
+	html := `<html><head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta property="og:title" content="Shared Title">
+</head><body><p>Private page</p></body></html>`
+
+	metas := map[string]string{}
+	visitor := &Visitor{
+		OnMeta: func(ctx *NodeContext, name, property, content string) *VisitResult {
+			if ctx.TagName != "meta" {
+				t.Errorf("OnMeta ctx.TagName = %s, expected 'meta'", ctx.TagName)
+			}
+			switch {
+			case name != "":
+				metas[name] = content
+			case property != "":
+				metas[property] = content
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if metas["robots"] != "noindex" {
+		t.Errorf("OnMeta robots content = %q, expected 'noindex'", metas["robots"])
+	}
+	if metas["og:title"] != "Shared Title" {
+		t.Errorf("OnMeta og:title content = %q, expected 'Shared Title'", metas["og:title"])
+	}
+	if !strings.Contains(result, "Private page") {
+		t.Errorf("Result = %s, expected to contain 'Private page'", result)
+	}
+
+	abort := &Visitor{
+		OnMeta: func(ctx *NodeContext, name, property, content string) *VisitResult {
+			if name == "robots" && strings.Contains(content, "noindex") {
+				return &VisitResult{ResultType: VisitError, ErrorMessage: "page is marked noindex"}
+			}
+			return nil
+		},
+	}
+	if _, err := ConvertWithVisitor(html, abort); err == nil || !strings.Contains(err.Error(), "noindex") {
+		t.Errorf("ConvertWithVisitor error = %v, expected noindex abort", err)
+	}
+}
+
+func TestConvertWithVisitor_ScriptAndStyleVisitors(t *testing.T) {
+	html := `<style>p { color: red; }</style>
+<script type="application/json">{"a":1}</script>
+<script>alert("hi")</script>
+<p>Body text</p>`
+
+	var payload, css string
+	scriptTypes := []string{}
+	visitor := &Visitor{
+		OnScript: func(ctx *NodeContext, scriptType, content string) *VisitResult {
+			scriptTypes = append(scriptTypes, scriptType)
+			if scriptType == "application/json" {
+				payload = content
+				return &VisitResult{ResultType: VisitCustom, CustomOutput: "```json\n" + content + "\n```"}
+			}
+			return &VisitResult{ResultType: VisitContinue}
+		},
+		OnStyle: func(ctx *NodeContext, content string) *VisitResult {
+			css = content
+			return nil
+		},
+	}
+
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if payload != `{"a":1}` {
+		t.Errorf("OnScript content = %q, expected '{\"a\":1}'", payload)
+	}
+	if len(scriptTypes) != 2 || scriptTypes[1] != "" {
+		t.Errorf("OnScript types = %v, expected [application/json \"\"]", scriptTypes)
+	}
+	if css != "p { color: red; }" {
+		t.Errorf("OnStyle content = %q, expected 'p { color: red; }'", css)
+	}
+	if !strings.Contains(result, "```json\n{\"a\":1}\n```") {
+		t.Errorf("Result = %q, expected injected JSON block", result)
+	}
+	if strings.Contains(result, "alert") || strings.Contains(result, "color: red") {
+		t.Errorf("Result = %q, expected other script and style content to be dropped", result)
+	}
+	if !strings.Contains(result, "Body text") {
+		t.Errorf("Result = %q, expected to contain 'Body text'", result)
+	}
+}
+
+func TestConvertWithVisitor_TableCellVisitor(t *testing.T) {
+	html := `<table>
+<thead><tr><th colspan="2">Name</th><th>Age</th></tr></thead>
+<tbody>
+<tr><td>Ada</td><td>Lovelace</td><td>36</td></tr>
+<tr><td rowspan="2">Alan</td><td>Turing</td><td>41</td></tr>
+<tr><td>M.</td><td>?</td></tr>
+</tbody>
+</table>`
+
+	type cellInfo struct {
+		row, col, colspan, rowspan int
+		content                    string
+		isHeader                   bool
+	}
+	var cells []cellInfo
+	visitor := &Visitor{
+		OnTableCell: func(ctx *NodeContext, row, col int, colspan, rowspan int, content string, isHeader bool) *VisitResult {
+			cells = append(cells, cellInfo{row, col, colspan, rowspan, content, isHeader})
+			return &VisitResult{ResultType: VisitContinue}
+		},
+	}
+
+	if _, err := ConvertWithVisitor(html, visitor); err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if len(cells) != 10 {
+		t.Fatalf("OnTableCell called %d times, expected 10", len(cells))
+	}
+
+	header := cells[0]
+	if header.content != "Name" || header.colspan != 2 || header.rowspan != 1 || !header.isHeader {
+		t.Errorf("header cell = %+v, expected Name with colspan 2 reported as header", header)
+	}
+	if age := cells[1]; age.col != 2 || age.colspan != 1 {
+		t.Errorf("Age cell col = %d colspan = %d, expected col 2 colspan 1", age.col, age.colspan)
+	}
+	if alan := cells[5]; alan.content != "Alan" || alan.rowspan != 2 || alan.isHeader {
+		t.Errorf("Alan cell = %+v, expected rowspan 2 body cell", alan)
+	}
+	if shifted := cells[8]; shifted.content != "M." || shifted.row != 3 || shifted.col != 1 {
+		t.Errorf("cell under rowspan = %+v, expected M. at row 3 col 1", shifted)
+	}
+}
+
+func TestConvertWithVisitorOptions_SetextHeadingsAndSkippedLinks(t *testing.T) {
+	visitor := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			return &VisitResult{ResultType: VisitSkip}
+		},
+	}
+	html := `<h1>Guide</h1><p>Read <a href="/docs">the docs</a> first.</p><h2>Setup</h2><h3>Details</h3>`
+
+	result, err := ConvertWithVisitorOptions(html, visitor, ConversionOptions{HeadingStyle: HeadingStyleSetext})
+	if err != nil {
+		t.Fatalf("ConvertWithVisitorOptions failed: %v", err)
+	}
+	for _, want := range []string{"Guide\n=====", "Setup\n-----", "### Details"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Result = %q, expected to contain %q", result, want)
+		}
+	}
+	if strings.Contains(result, "the docs") || strings.Contains(result, "/docs") {
+		t.Errorf("Result = %q, expected the link to be skipped", result)
+	}
+	if strings.Contains(result, "# Guide") {
+		t.Errorf("Result = %q, expected no ATX heading for level 1", result)
+	}
+}
+
+func TestCombineVisitors(t *testing.T) {
+	var seenLinks []string
+	analytics := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			seenLinks = append(seenLinks, href)
+			return nil
+		},
+	}
+	skipImages := &Visitor{
+		OnImage: func(ctx *NodeContext, src, alt, title string) *VisitResult {
+			return &VisitResult{ResultType: VisitSkip}
+		},
+	}
+	rewriteLinks := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			return &VisitResult{ResultType: VisitContinue, RewriteURL: "https://example.com" + href}
+		},
+	}
+	html := `<p><img src="banner.png" alt="Banner"> See <a href="/docs">the docs</a>.</p>`
+
+	result, err := ConvertWithVisitor(html, CombineVisitors(analytics, skipImages, nil, rewriteLinks))
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if strings.Contains(result, "banner.png") {
+		t.Errorf("Result = %q, expected the image to be skipped", result)
+	}
+	if !strings.Contains(result, "[the docs](https://example.com/docs)") {
+		t.Errorf("Result = %q, expected the rewritten link", result)
+	}
+	if len(seenLinks) != 1 || seenLinks[0] != "/docs" {
+		t.Errorf("analytics saw %v, expected [/docs]", seenLinks)
+	}
+}
+
+func TestCombineVisitors_FirstResultWins(t *testing.T) {
+	custom := func(output string) *Visitor {
+		return &Visitor{
+			OnStrong: func(ctx *NodeContext, text string) *VisitResult {
+				return &VisitResult{ResultType: VisitCustom, CustomOutput: output}
+			},
+		}
+	}
+
+	result, err := ConvertWithVisitor(`<p><strong>bold</strong></p>`, CombineVisitors(custom("first"), custom("second")))
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "first") || strings.Contains(result, "second") {
+		t.Errorf("Result = %q, expected the first visitor's output only", result)
+	}
+}
+
+func TestConvertWithVisitor_Ancestors(t *testing.T) {
+	var ancestors []string
+	visitor := &Visitor{
+		OnLink: func(ctx *NodeContext, href, text, title string) *VisitResult {
+			ancestors = ctx.Ancestors
+			return nil
+		},
+	}
+
+	_, err := ConvertWithVisitor(`<table><tr><td><a href="/x">x</a></td></tr></table>`, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if got := strings.Join(ancestors, ","); got != "td,tr,table" {
+		t.Errorf("Ancestors = %v, expected [td tr table]", ancestors)
+	}
+}
+
+func TestConvertWithVisitor_InputLabel(t *testing.T) {
+	labels := map[string]string{}
+	visitor := &Visitor{
+		OnInput: func(ctx *NodeContext, inputType, name, value, label string) *VisitResult {
+			labels[name] = label
+			return &VisitResult{ResultType: VisitCustom, CustomOutput: label + ": ____"}
+		},
+	}
+
+	html := `<form><label for="e">Email</label><input id="e" type="email" name="email">` +
+		`<label>Phone <input type="tel" name="phone"></label>` +
+		`<input type="text" name="city" placeholder="City"></form>`
+	result, err := ConvertWithVisitor(html, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	expected := map[string]string{"email": "Email", "phone": "Phone", "city": "City"}
+	for name, label := range expected {
+		if labels[name] != label {
+			t.Errorf("label of %q = %q, expected %q", name, labels[name], label)
+		}
+	}
+	if !strings.Contains(result, "Email: ____") {
+		t.Errorf("Result = %q, expected to contain the custom input output", result)
+	}
+}
+
+func TestConvertWithVisitor_OnSelect(t *testing.T) {
+	visitor := &Visitor{
+		OnSelect: func(ctx *NodeContext, name string, options []string, selected []int) *VisitResult {
+			var b strings.Builder
+			b.WriteString(name + ":")
+			for i, option := range options {
+				if slices.Contains(selected, i) {
+					b.WriteString(" [" + option + "]")
+				} else {
+					b.WriteString(" " + option)
+				}
+			}
+			return &VisitResult{ResultType: VisitCustom, CustomOutput: b.String()}
+		},
+	}
+
+	result, err := ConvertWithVisitor(`<p><select name="size"><option>A</option><option selected>B</option></select></p>`, visitor)
+	if err != nil {
+		t.Fatalf("ConvertWithVisitor failed: %v", err)
+	}
+	if !strings.Contains(result, "size: A [B]") {
+		t.Errorf("Result = %q, expected the selected option to be marked", result)
+	}
+}
+
+func benchmarkVisitorDocument() string {
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		b.WriteString(`<section><h2>Section</h2><p>Text with <a href="/x">a link</a> and <b>bold</b>.</p>`)
+		b.WriteString(`<ul><li>One</li><li>Two</li></ul><blockquote><p>Quoted</p></blockquote></section>`)
+	}
+	return b.String()
+}
+
+func BenchmarkConvertWithVisitor_OnText(b *testing.B) {
+	html := benchmarkVisitorDocument()
+	visitor := &Visitor{
+		OnText: func(ctx *NodeContext, text string) *VisitResult { return nil },
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ConvertWithVisitor(html, visitor); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConvertWithVisitor_OnElementEnd(b *testing.B) {
+	html := benchmarkVisitorDocument()
+	visitor := &Visitor{
+		OnElementEnd: func(ctx *NodeContext, output string) *VisitResult { return nil },
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ConvertWithVisitor(html, visitor); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,673 @@
+package htmltomarkdown
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Node types reported in NodeContext.NodeType. The values match the first
+// entries of the native html_to_markdown_node_type_t enumeration.
+const (
+	NodeTypeText    uint32 = 0
+	NodeTypeElement uint32 = 1
+)
+
+// VisitorError is returned by ConvertWithVisitor when a callback returns VisitError.
+type VisitorError struct {
+	// Message is the ErrorMessage returned by the callback.
+	Message string
+
+	// TagName is the tag of the element being visited, empty for text and document callbacks.
+	TagName string
+}
+
+func (e *VisitorError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = "visitor returned VisitError"
+	}
+	if e.TagName != "" {
+		return fmt.Sprintf("conversion aborted by visitor at <%s>: %s", e.TagName, message)
+	}
+	return "conversion aborted by visitor: " + message
+}
+
+// visitorWalker dispatches visitor callbacks over a parsed HTML tree and
+// applies their results to the tree before the native conversion runs.
+//
+// The Markdown and text passed to callbacks are cached per element and
+// reused for its ancestors until a callback changes the element's subtree,
+// so each element is converted once rather than once per ancestor.
+type visitorWalker struct {
+	visitor   *Visitor
+	fragments *fragmentSet
+	cells     map[*htmlNode]tableCell
+	labels    map[string]*htmlNode
+	outputs   map[*htmlNode]string
+	texts     map[*htmlNode]string
+}
+
+func newVisitorWalker(visitor *Visitor) *visitorWalker {
+	return &visitorWalker{
+		visitor:   visitor,
+		fragments: &fragmentSet{},
+		cells:     map[*htmlNode]tableCell{},
+		outputs:   map[*htmlNode]string{},
+		texts:     map[*htmlNode]string{},
+	}
+}
+
+// enterHandler invokes the tag-specific callback fired when an element is entered.
+type enterHandler func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error)
+
+// textCallback and contextCallback are the shapes shared by most Visitor fields.
+type (
+	textCallback    = func(ctx *NodeContext, text string) *VisitResult
+	contextCallback = func(ctx *NodeContext) *VisitResult
+)
+
+// exitHandler invokes the tag-specific callback fired after an element's children.
+type exitHandler func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error)
+
+var enterHandlers = map[string]enterHandler{
+	"a":          visitLink,
+	"img":        visitImage,
+	"h1":         visitHeading,
+	"h2":         visitHeading,
+	"h3":         visitHeading,
+	"h4":         visitHeading,
+	"h5":         visitHeading,
+	"h6":         visitHeading,
+	"pre":        visitCodeBlock,
+	"code":       visitCodeInline,
+	"ul":         visitListStart,
+	"ol":         visitListStart,
+	"li":         visitListItem,
+	"table":      visitTableStart,
+	"tr":         visitTableRow,
+	"td":         visitTableCell,
+	"th":         visitTableCell,
+	"blockquote": visitBlockquote,
+	"strong":     visitTextCallback(func(v *Visitor) textCallback { return v.OnStrong }),
+	"b":          visitTextCallback(func(v *Visitor) textCallback { return v.OnStrong }),
+	"em":         visitTextCallback(func(v *Visitor) textCallback { return v.OnEmphasis }),
+	"i":          visitTextCallback(func(v *Visitor) textCallback { return v.OnEmphasis }),
+	"s":          visitTextCallback(func(v *Visitor) textCallback { return v.OnStrikethrough }),
+	"del":        visitTextCallback(func(v *Visitor) textCallback { return v.OnStrikethrough }),
+	"strike":     visitTextCallback(func(v *Visitor) textCallback { return v.OnStrikethrough }),
+	"u":          visitTextCallback(func(v *Visitor) textCallback { return v.OnUnderline }),
+	"ins":        visitTextCallback(func(v *Visitor) textCallback { return v.OnUnderline }),
+	"sub":        visitTextCallback(func(v *Visitor) textCallback { return v.OnSubscript }),
+	"sup":        visitTextCallback(func(v *Visitor) textCallback { return v.OnSuperscript }),
+	"mark":       visitTextCallback(func(v *Visitor) textCallback { return v.OnMark }),
+	"dt":         visitTextCallback(func(v *Visitor) textCallback { return v.OnDefinitionTerm }),
+	"dd":         visitTextCallback(func(v *Visitor) textCallback { return v.OnDefinitionDescription }),
+	"button":     visitTextCallback(func(v *Visitor) textCallback { return v.OnButton }),
+	"summary":    visitTextCallback(func(v *Visitor) textCallback { return v.OnSummary }),
+	"figcaption": visitTextCallback(func(v *Visitor) textCallback { return v.OnFigcaption }),
+	"br":         visitSimple(func(v *Visitor) contextCallback { return v.OnLineBreak }),
+	"hr":         visitSimple(func(v *Visitor) contextCallback { return v.OnHorizontalRule }),
+	"dl":         visitSimple(func(v *Visitor) contextCallback { return v.OnDefinitionListStart }),
+	"figure":     visitSimple(func(v *Visitor) contextCallback { return v.OnFigureStart }),
+	"form":       visitForm,
+	"input":      visitInput,
+	"select":     visitSelect,
+	"audio":      visitMedia(func(v *Visitor) textCallback { return v.OnAudio }),
+	"video":      visitMedia(func(v *Visitor) textCallback { return v.OnVideo }),
+	"iframe":     visitMedia(func(v *Visitor) textCallback { return v.OnIframe }),
+	"details":    visitDetails,
+	"meta":       visitMeta,
+	"script":     visitScript,
+	"style":      visitStyle,
+}
+
+var exitHandlers = map[string]exitHandler{
+	"ul":     visitListEnd,
+	"ol":     visitListEnd,
+	"table":  visitOutputCallback(func(v *Visitor) textCallback { return v.OnTableEnd }),
+	"dl":     visitOutputCallback(func(v *Visitor) textCallback { return v.OnDefinitionListEnd }),
+	"figure": visitOutputCallback(func(v *Visitor) textCallback { return v.OnFigureEnd }),
+}
+
+// newElementContext builds the NodeContext passed to callbacks for n.
+func newElementContext(n *htmlNode) *NodeContext {
+	ctx := &NodeContext{
+		NodeType:      NodeTypeElement,
+		TagName:       n.tag,
+		ParentTag:     n.parentTag(),
+		Ancestors:     n.ancestorTags(),
+		Depth:         uint64(n.elementDepth()),
+		IndexInParent: uint64(n.elementIndex()),
+		SiblingCount:  uint64(n.elementSiblingCount()),
+		IsInline:      isInlineTag(n.tag),
+		HTMLOffset:    uint32(n.offset),
+	}
+	if n.typ == htmlTextNode {
+		ctx.NodeType = NodeTypeText
+		ctx.TagName = ""
+		ctx.IndexInParent = uint64(n.index())
+		ctx.SiblingCount = uint64(len(n.parent.children))
+		ctx.IsInline = true
+	}
+	return ctx
+}
+
+// walkChildren visits the children of n in document order.
+func (w *visitorWalker) walkChildren(n *htmlNode) error {
+	children := append([]*htmlNode(nil), n.children...)
+	for _, child := range children {
+		var err error
+		switch child.typ {
+		case htmlElementNode:
+			err = w.visitElement(child)
+		case htmlTextNode:
+			err = w.visitText(child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *visitorWalker) visitText(n *htmlNode) error {
+	if w.visitor.OnText == nil || rawTextElements[n.parentTag()] {
+		return nil
+	}
+	text := n.text()
+	whitespaceOnly := strings.TrimSpace(text) == ""
+	if whitespaceOnly && w.visitor.SkipWhitespaceTextNodes {
+		return nil
+	}
+	ctx := newElementContext(n)
+	ctx.IsWhitespaceOnly = whitespaceOnly
+	_, err := w.apply(n, ctx, w.visitor.OnText(ctx, text))
+	return err
+}
+
+func (w *visitorWalker) visitElement(n *htmlNode) error {
+	if n.implied {
+		return w.walkChildren(n)
+	}
+	ctx := newElementContext(n)
+
+	if w.visitor.OnElementStart != nil {
+		if done, err := w.apply(n, ctx, w.visitor.OnElementStart(ctx)); done || err != nil {
+			return err
+		}
+	}
+
+	handler := enterHandlers[n.tag]
+	if fn := w.visitor.ElementHandlers[n.tag]; fn != nil {
+		handler = visitRegisteredElement(fn)
+	} else if handler == nil && strings.Contains(n.tag, "-") {
+		handler = visitCustomElement
+	}
+	if handler != nil {
+		vr, err := handler(w, n, ctx)
+		if err != nil {
+			return err
+		}
+		if done, err := w.apply(n, ctx, vr); done || err != nil {
+			return err
+		}
+	}
+
+	if !rawTextElements[n.tag] {
+		if err := w.walkChildren(n); err != nil {
+			return err
+		}
+	}
+
+	if exit := exitHandlers[n.tag]; exit != nil {
+		vr, err := exit(w, n, ctx)
+		if err != nil {
+			return err
+		}
+		if done, err := w.apply(n, ctx, vr); done || err != nil {
+			return err
+		}
+	}
+
+	if w.visitor.OnElementText != nil {
+		if done, err := w.apply(n, ctx, w.visitor.OnElementText(ctx, w.textOf(n))); done || err != nil {
+			return err
+		}
+	}
+
+	if w.visitor.OnElementEnd != nil {
+		output, err := w.markdownOf(n, false)
+		if err != nil {
+			return err
+		}
+		if _, err := w.apply(n, ctx, w.visitor.OnElementEnd(ctx, output)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply performs the action requested by a callback result on n. It reports
+// whether n was replaced or removed, in which case its children are not visited.
+func (w *visitorWalker) apply(n *htmlNode, ctx *NodeContext, vr *VisitResult) (bool, error) {
+	if vr == nil {
+		return false, nil
+	}
+	switch vr.ResultType {
+	case VisitCustom, VisitSkip, VisitPreserveHTML:
+		w.invalidate(n)
+	}
+	switch vr.ResultType {
+	case VisitCustom:
+		n.replaceWith(w.fragments.node(vr.CustomOutput, ctx.IsInline))
+		return true, nil
+	case VisitSkip:
+		n.remove()
+		return true, nil
+	case VisitPreserveHTML:
+		n.replaceWith(w.fragments.node(w.fragments.restore(n.render()), ctx.IsInline))
+		return true, nil
+	case VisitError:
+		return true, &VisitorError{Message: vr.ErrorMessage, TagName: ctx.TagName}
+	default:
+		return false, nil
+	}
+}
+
+// convert runs the native conversion on src and restores Go-side fragments.
+func (w *visitorWalker) convert(src string) (string, error) {
+	markdown, err := convertFFI(src)
+	if err != nil {
+		return "", err
+	}
+	return w.fragments.restore(markdown), nil
+}
+
+// markdownOf returns the trimmed Markdown of n, or of its children only, for
+// callbacks that receive already converted content.
+func (w *visitorWalker) markdownOf(n *htmlNode, childrenOnly bool) (string, error) {
+	return w.subtreeMarkdown(n, childrenOnly, n.tag == "pre" || n.hasAncestor("pre"))
+}
+
+// subtreeMarkdown converts n, or its children only, reusing the cached
+// Markdown of block children as fragments. Children of inline elements and
+// content inside <pre> are always converted in place, since their Markdown
+// depends on the surrounding markup.
+func (w *visitorWalker) subtreeMarkdown(n *htmlNode, childrenOnly, inPre bool) (string, error) {
+	if markdown, ok := w.outputs[n]; ok && !childrenOnly {
+		return markdown, nil
+	}
+	var b strings.Builder
+	if !childrenOnly {
+		n.writeStartTag(&b)
+	}
+	reuse := !inPre && !isInlineTag(n.tag)
+	for _, c := range n.children {
+		if !reuse || c.typ != htmlElementNode || !paragraphClosers[c.tag] {
+			c.writeHTML(&b)
+			continue
+		}
+		markdown, err := w.subtreeMarkdown(c, false, c.tag == "pre")
+		if err != nil {
+			return "", err
+		}
+		if markdown != "" {
+			w.fragments.node(markdown, false).writeHTML(&b)
+		}
+	}
+	if !childrenOnly && !voidElements[n.tag] {
+		n.writeEndTag(&b)
+	}
+
+	markdown := ""
+	if src := b.String(); strings.TrimSpace(src) != "" {
+		converted, err := w.convert(src)
+		if err != nil {
+			return "", err
+		}
+		markdown = strings.Trim(converted, "\n")
+	}
+	if !childrenOnly {
+		w.outputs[n] = markdown
+	}
+	return markdown, nil
+}
+
+// textOf returns the text content of n with fragments restored, reusing the
+// cached text of its element children.
+func (w *visitorWalker) textOf(n *htmlNode) string {
+	if text, ok := w.texts[n]; ok {
+		return text
+	}
+	if n.tag == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		if c.typ == htmlElementNode {
+			b.WriteString(w.textOf(c))
+		} else {
+			b.WriteString(w.fragments.restore(c.text()))
+		}
+	}
+	w.texts[n] = b.String()
+	return w.texts[n]
+}
+
+// invalidate drops the cached Markdown and text of n and its ancestors
+// before a callback changes n.
+func (w *visitorWalker) invalidate(n *htmlNode) {
+	for p := n; p != nil; p = p.parent {
+		delete(w.outputs, p)
+		delete(w.texts, p)
+	}
+}
+
+func visitLink(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnLink == nil {
+		return nil, nil
+	}
+	vr := w.visitor.OnLink(ctx, n.attrOr("href", ""), n.normalizedText(), n.attrOr("title", ""))
+	if vr != nil && vr.ResultType == VisitContinue && vr.RewriteURL != "" {
+		w.invalidate(n)
+		n.setAttr("href", vr.RewriteURL)
+	}
+	return vr, nil
+}
+
+func visitImage(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnImage == nil {
+		return nil, nil
+	}
+	vr := w.visitor.OnImage(ctx, n.attrOr("src", ""), n.attrOr("alt", ""), n.attrOr("title", ""))
+	if vr != nil && vr.ResultType == VisitContinue && vr.RewriteURL != "" {
+		w.invalidate(n)
+		n.setAttr("src", vr.RewriteURL)
+	}
+	return vr, nil
+}
+
+func visitHeading(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnHeading == nil {
+		return nil, nil
+	}
+	level, err := strconv.Atoi(strings.TrimPrefix(n.tag, "h"))
+	if err != nil {
+		return nil, nil
+	}
+	return w.visitor.OnHeading(ctx, uint32(level), n.normalizedText(), n.attrOr("id", "")), nil
+}
+
+func visitCodeBlock(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnCodeBlock == nil {
+		return nil, nil
+	}
+	return w.visitor.OnCodeBlock(ctx, codeLanguage(n, defaultCodeLanguageClasses), strings.TrimSuffix(n.text(), "\n")), nil
+}
+
+func visitCodeInline(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnCodeInline == nil || n.hasAncestor("pre") {
+		return nil, nil
+	}
+	return w.visitor.OnCodeInline(ctx, n.text()), nil
+}
+
+func visitListStart(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnListStart == nil {
+		return nil, nil
+	}
+	return w.visitor.OnListStart(ctx, n.tag == "ol"), nil
+}
+
+func visitListItem(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnListItem == nil {
+		return nil, nil
+	}
+	ordered := n.parent != nil && n.parent.tag == "ol"
+	text, err := w.markdownOf(n, true)
+	if err != nil {
+		return nil, err
+	}
+	return w.visitor.OnListItem(ctx, ordered, listMarker(n, ordered), text), nil
+}
+
+// listMarker returns the Markdown marker for a list item, numbered as the
+// ordered list's start, reversed and value attributes declare.
+func listMarker(li *htmlNode, ordered bool) string {
+	if !ordered {
+		return "-"
+	}
+	items := listItems(li.parent)
+	numbers := listNumbers(li.parent, items)
+	style := li.attrOr("type", li.parent.attrOr("type", "1"))
+	return formatListNumber(numbers[slices.Index(items, li)], style) + "."
+}
+
+func visitListEnd(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnListEnd == nil {
+		return nil, nil
+	}
+	output, err := w.markdownOf(n, false)
+	if err != nil {
+		return nil, err
+	}
+	return w.visitor.OnListEnd(ctx, n.tag == "ol", output), nil
+}
+
+func visitTableStart(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnTableStart == nil {
+		return nil, nil
+	}
+	return w.visitor.OnTableStart(ctx), nil
+}
+
+func visitTableRow(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnTableRow == nil {
+		return nil, nil
+	}
+	cells := make([]string, 0, len(n.children))
+	allHeaders := true
+	for _, c := range n.children {
+		if c.typ != htmlElementNode || (c.tag != "td" && c.tag != "th") {
+			continue
+		}
+		if c.tag != "th" {
+			allHeaders = false
+		}
+		cell, err := w.markdownOf(c, true)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+	isHeader := n.parentTag() == "thead" || (len(cells) > 0 && allHeaders)
+	return w.visitor.OnTableRow(ctx, cells, isHeader), nil
+}
+
+func visitTableCell(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnTableCell == nil {
+		return nil, nil
+	}
+	cell, ok := w.tableCell(n)
+	if !ok {
+		return nil, nil
+	}
+	content, err := w.markdownOf(n, true)
+	if err != nil {
+		return nil, err
+	}
+	return w.visitor.OnTableCell(ctx, cell.row, cell.col, cell.colspan, cell.rowspan, content, cell.isHeader), nil
+}
+
+// tableCell returns the grid placement of a cell, laying out its table on first use.
+func (w *visitorWalker) tableCell(n *htmlNode) (tableCell, bool) {
+	if cell, ok := w.cells[n]; ok {
+		return cell, true
+	}
+	table := n.parent
+	for table != nil && table.tag != "table" {
+		table = table.parent
+	}
+	if table == nil {
+		return tableCell{}, false
+	}
+	for _, row := range layoutTable(table) {
+		for _, cell := range row {
+			w.cells[cell.node] = cell
+		}
+	}
+	cell, ok := w.cells[n]
+	return cell, ok
+}
+
+func visitBlockquote(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnBlockquote == nil {
+		return nil, nil
+	}
+	content, err := w.markdownOf(n, true)
+	if err != nil {
+		return nil, err
+	}
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		if p.tag == "blockquote" {
+			depth++
+		}
+	}
+	return w.visitor.OnBlockquote(ctx, content, uint64(depth)), nil
+}
+
+func visitForm(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnForm == nil {
+		return nil, nil
+	}
+	return w.visitor.OnForm(ctx, n.attrOr("action", ""), n.attrOr("method", "")), nil
+}
+
+func visitInput(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnInput == nil {
+		return nil, nil
+	}
+	return w.visitor.OnInput(ctx, n.attrOr("type", "text"), n.attrOr("name", ""), n.attrOr("value", ""), w.fieldLabel(n)), nil
+}
+
+func visitSelect(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnSelect == nil {
+		return nil, nil
+	}
+	options, selected := selectOptions(n)
+	return w.visitor.OnSelect(ctx, n.attrOr("name", ""), options, selected), nil
+}
+
+// fieldLabel returns the label of a form field, indexing the labels of the
+// document on first use.
+func (w *visitorWalker) fieldLabel(field *htmlNode) string {
+	if w.labels == nil {
+		root := field
+		for root.parent != nil {
+			root = root.parent
+		}
+		w.labels = labelsByFor(root)
+	}
+	return fieldLabel(field, w.labels)
+}
+
+func visitDetails(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnDetails == nil {
+		return nil, nil
+	}
+	_, open := n.attr("open")
+	return w.visitor.OnDetails(ctx, open), nil
+}
+
+func visitMeta(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnMeta == nil {
+		return nil, nil
+	}
+	return w.visitor.OnMeta(ctx, n.attrOr("name", ""), n.attrOr("property", ""), n.attrOr("content", "")), nil
+}
+
+func visitScript(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnScript == nil {
+		return nil, nil
+	}
+	return w.visitor.OnScript(ctx, n.attrOr("type", ""), n.text()), nil
+}
+
+func visitStyle(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnStyle == nil {
+		return nil, nil
+	}
+	return w.visitor.OnStyle(ctx, n.text()), nil
+}
+
+func visitCustomElement(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+	if w.visitor.OnCustomElement == nil {
+		return nil, nil
+	}
+	return w.visitor.OnCustomElement(ctx, n.tag, n.render()), nil
+}
+
+// visitRegisteredElement adapts a callback registered in Visitor.ElementHandlers.
+func visitRegisteredElement(fn func(ctx *NodeContext, html string) *VisitResult) enterHandler {
+	return func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+		return fn(ctx, n.render()), nil
+	}
+}
+
+// visitTextCallback adapts callbacks receiving the element's plain text.
+func visitTextCallback(pick func(*Visitor) textCallback) enterHandler {
+	return func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+		fn := pick(w.visitor)
+		if fn == nil {
+			return nil, nil
+		}
+		return fn(ctx, n.normalizedText()), nil
+	}
+}
+
+// visitSimple adapts callbacks receiving only the node context.
+func visitSimple(pick func(*Visitor) contextCallback) enterHandler {
+	return func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+		fn := pick(w.visitor)
+		if fn == nil {
+			return nil, nil
+		}
+		return fn(ctx), nil
+	}
+}
+
+// visitMedia adapts callbacks receiving an element's source URL, taken from
+// its src attribute or its first <source> child.
+func visitMedia(pick func(*Visitor) textCallback) enterHandler {
+	return func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+		fn := pick(w.visitor)
+		if fn == nil {
+			return nil, nil
+		}
+		src := n.attrOr("src", "")
+		if src == "" {
+			if sources := n.findAll("source"); len(sources) > 0 {
+				src = sources[0].attrOr("src", "")
+			}
+		}
+		return fn(ctx, src), nil
+	}
+}
+
+// visitOutputCallback adapts end callbacks receiving the element's converted Markdown.
+func visitOutputCallback(pick func(*Visitor) textCallback) exitHandler {
+	return func(w *visitorWalker, n *htmlNode, ctx *NodeContext) (*VisitResult, error) {
+		fn := pick(w.visitor)
+		if fn == nil {
+			return nil, nil
+		}
+		output, err := w.markdownOf(n, false)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ctx, output), nil
+	}
+}